@@ -19,7 +19,10 @@ import (
 	"github.com/nyashahama/music-awards/internal/config"
 	"github.com/nyashahama/music-awards/internal/handlers"
 	"github.com/nyashahama/music-awards/internal/middleware"
+	"github.com/nyashahama/music-awards/internal/oidc"
+	"github.com/nyashahama/music-awards/internal/rbac"
 	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/nyashahama/music-awards/internal/security"
 	"github.com/nyashahama/music-awards/internal/services"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -36,6 +39,35 @@ func Run() {
 		log.Fatalf("Failed to load DB config: %v", err)
 	}
 
+	jobCfg, err := config.LoadJobConfig()
+	if err != nil {
+		log.Fatalf("Failed to load job config: %v", err)
+	}
+
+	jwtCfg, err := config.LoadJWTConfig()
+	if err != nil {
+		log.Fatalf("Failed to load JWT config: %v", err)
+	}
+	security.UseSecret(jwtCfg.Secret)
+	security.UseKeySet(jwtCfg.Keys)
+	security.AcceptSecretTokens(jwtCfg.AcceptSecret)
+
+	receiptSecret, err := config.LoadReceiptSecret()
+	if err != nil {
+		log.Fatalf("Failed to load receipt secret: %v", err)
+	}
+	security.UseReceiptSecret(receiptSecret)
+
+	adminRequireTwoFactor, err := config.AdminRequireTwoFactor()
+	if err != nil {
+		log.Fatalf("Failed to load admin config: %v", err)
+	}
+
+	oidcCfgs, err := config.LoadOIDCProviders()
+	if err != nil {
+		log.Fatalf("Failed to load OIDC config: %v", err)
+	}
+
 	// 2) Open raw *sql.DB
 	sqlDB, err := config.InitDB(dbCfg)
 	if err != nil {
@@ -71,13 +103,32 @@ func Run() {
 
 	// 5) Initialize services and handlers
 	userRepo := repositories.NewUserRepository(gormDB)
-	userSvc := services.NewUserService(userRepo)
+	editionRepo := repositories.NewEditionRepository(gormDB)
+	unitOfWork := repositories.NewUnitOfWork(gormDB)
+	accountLinks := services.AccountLinks{
+		VerifyEmail:   config.PublicAPIURL() + "/api/verify-email",
+		ResetPassword: config.FrontendURL() + "/reset-password",
+	}
+	sessionRepo := repositories.NewSessionRepository(gormDB)
+	throttleRepo := repositories.NewLoginThrottleRepository(gormDB)
+	userSvc := services.NewUserService(userRepo, editionRepo, sessionRepo, throttleRepo, unitOfWork, accountLinks)
 	userH := handlers.NewUserHandler(userSvc)
 
-	// Initialize category dependencies
+	// Initialize sign-in with OpenID Connect providers
+	identityRepo := repositories.NewIdentityRepository(gormDB)
+	var oidcProviders []services.OIDCProvider
+	for _, cfg := range oidcCfgs {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(cfg, nil))
+	}
+	oidcSvc := services.NewOIDCService(userRepo, editionRepo, identityRepo, sessionRepo, unitOfWork, oidcProviders)
+	oidcH := handlers.NewOIDCHandler(oidcSvc)
+
+	// Initialize edition and category dependencies
+	editionSvc := services.NewEditionService(editionRepo)
 	categoryRepo := repositories.NewCategoryRepository(gormDB)
-	categorySvc := services.NewCategoryService(categoryRepo)
+	categorySvc := services.NewCategoryService(categoryRepo, editionRepo)
 	categoryH := handlers.NewCategoryHandler(categorySvc)
+	editionH := handlers.NewEditionHandler(editionSvc, categorySvc)
 
 	// Initialize nominee dependencies
 	nomineeRepo := repositories.NewNomineeRepository(gormDB)
@@ -90,16 +141,41 @@ func Run() {
 	nomineeH := handlers.NewNomineeHandler(nomineeSvc)
 
 	// Initialize nominee-category dependencies
-	nomineeCategorySvc := services.NewNomineeCategoryService(nomineeCategoryRepo)
+	nomineeCategorySvc := services.NewNomineeCategoryService(nomineeCategoryRepo, categoryRepo)
 	nomineeCategoryH := handlers.NewNomineeCategoryHandler(nomineeCategorySvc)
 
 	// Initialize vote dependencies
 	voteRepo := repositories.NewVoteRepository(gormDB)
-	voteSvc := services.NewVotingMechanismService(voteRepo, userRepo)
-	voteH := handlers.NewVoteHandler(voteSvc)
+	voteSvc := services.NewVotingMechanismService(voteRepo, userRepo, categoryRepo, editionRepo, nomineeCategoryRepo, unitOfWork)
+	receiptSvc := services.NewReceiptService(voteRepo)
+	voteH := handlers.NewVoteHandler(voteSvc, receiptSvc)
+	receiptH := handlers.NewReceiptHandler(receiptSvc)
+	jwksH := handlers.NewJWKSHandler(jwtCfg.Keys)
+	ledgerRepo := repositories.NewLedgerRepository(gormDB)
+	ledgerSvc := services.NewLedgerService(ledgerRepo)
+	ledgerH := handlers.NewLedgerHandler(ledgerSvc)
+	allocationSvc := services.NewVoteAllocationService(editionRepo, userRepo)
+	allocationH := handlers.NewVoteAllocationHandler(allocationSvc)
+
+	// Initialize results dependencies
+	resultsSvc := services.NewResultsService(voteRepo, categoryRepo, editionRepo)
+	resultsH := handlers.NewResultsHandler(resultsSvc)
+
+	// Initialize fraud detection dependencies
+	fraudRepo := repositories.NewFraudRepository(gormDB)
+	fraudSvc := services.NewFraudService(fraudRepo, voteRepo, unitOfWork, services.DefaultFraudRules())
+	fraudH := handlers.NewFraudHandler(fraudSvc)
+
+	// Initialize request audit dependencies
+	auditRepo := repositories.NewAuditRepository(gormDB)
+	auditSvc := services.NewAuditService(auditRepo, jobCfg.AuditRetention)
+	auditH := handlers.NewAuditHandler(auditSvc)
 
 	// 6) Configure Gin router with production settings
 	router := gin.New()
+	if err := router.SetTrustedProxies(config.LoadTrustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Production-friendly middleware stack
 	router.Use(
@@ -112,19 +188,37 @@ func Run() {
 				"https://music-awards-web.onrender.com", // exact match
 			},
 			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-			ExposeHeaders:    []string{"Content-Length"},
+			AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.RequestIDHeader},
+			ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
 			AllowCredentials: true,
 			MaxAge:           12 * time.Hour,
 		}),
+		middleware.RequestFingerprint(),
 	)
 
+	// Public keys for verifying access tokens
+	router.GET("/.well-known/jwks.json", jwksH.GetJWKS)
+
 	// API routes
 	api := router.Group("/api")
 	{
 		// Authentication
 		api.POST("/register", userH.Register)
 		api.POST("/login", userH.Login)
+		api.GET("/verify-email", userH.VerifyEmail)
+		api.POST("/verify-email/resend", userH.ResendVerification)
+		api.POST("/forgot-password", userH.ForgotPassword)
+		api.POST("/reset-password", userH.ResetPassword)
+		api.POST("/token/refresh", userH.RefreshToken)
+		api.POST("/login/2fa", userH.CompleteTwoFactorLogin)
+		api.GET("/auth/oidc/:provider/login", oidcH.StartLogin)
+		api.GET("/auth/oidc/:provider/callback", oidcH.Callback)
+
+		// Public Edition APIs
+		api.GET("/editions", editionH.ListEditions)
+		api.GET("/editions/active", editionH.GetActiveEdition)
+		api.GET("/editions/:editionId", editionH.GetEdition)
+		api.GET("/editions/:editionId/categories", editionH.ListEditionCategories)
 
 		// Public Category APIs
 		api.GET("/categories", categoryH.ListCategories)
@@ -134,51 +228,141 @@ func Run() {
 		// Public Nominee APIs
 		api.GET("/nominees", nomineeH.GetAllNominees)
 		api.GET("/nominees/:id", nomineeH.GetNomineeDetails)
+
+		// Public Results APIs (published categories only)
+		api.GET("/results/categories/:categoryId", resultsH.GetPublishedCategoryResults)
+		api.GET("/results/editions/:year", resultsH.GetPublishedHistoricalResults)
+
+		// Public vote receipt verification
+		api.POST("/receipts/verify", receiptH.VerifyReceipt)
 	}
 
 	// Protected routes (require authentication)
-	protected := router.Group("/api", middleware.AuthMiddleware())
+	protected := router.Group("/api", middleware.AuthMiddleware(userSvc))
 	{
 		// User routes
+		protected.POST("/logout", userH.Logout)
+		protected.POST("/2fa/setup", userH.SetupTwoFactor)
+		protected.POST("/2fa/enable", userH.EnableTwoFactor)
+		protected.POST("/2fa/disable", userH.DisableTwoFactor)
+		protected.POST("/2fa/recovery-codes", userH.RegenerateRecoveryCodes)
 		protected.GET("/profile/:id", userH.GetProfile)
 		protected.GET("/profile/users", userH.ListAllUsers)
 		protected.PUT("/profile/:id", userH.UpdateProfile)
 		protected.DELETE("/profile/:id", userH.DeleteAccount)
-		protected.PUT("/profile/:id/promote", userH.PromoteUser)
 
 		// Vote routes
 		protected.POST("/votes", voteH.CastVote)
+		protected.POST("/votes/ballot", voteH.CastBallot)
 		protected.GET("/votes", voteH.GetUserVotes)
 		protected.GET("/votes/available", voteH.GetAvailableVotes)
 		protected.PUT("/votes/:id", voteH.ChangeVote)
+		protected.PUT("/votes/:id/ballot", voteH.ChangeBallot)
+		protected.GET("/votes/:id/history", voteH.GetVoteHistory)
 		protected.DELETE("/votes/:id", voteH.DeleteVote)
 
 		// Nominee-Category routes
 		protected.GET("/categories/:categoryId/nominees", nomineeCategoryH.GetNominees)
 	}
 
-	// Admin-only routes
-	admin := router.Group("/api", middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	// Staff routes, each group requiring a permission of the user's role.
+	// Every permitted request to them is recorded in the admin audit log,
+	// with snapshots of the resource it changes.
+	staff := router.Group("/api", middleware.AuthMiddleware(userSvc))
+	audit := middleware.AuditAdminActions(auditSvc, map[string]middleware.Snapshot{
+		"editions":   editionH.Snapshot,
+		"categories": categoryH.Snapshot,
+		"nominees":   nomineeH.Snapshot,
+		"votes":      voteH.Snapshot,
+		"users":      userH.Snapshot,
+		"profile":    userH.Snapshot,
+	})
+	can := func(perm rbac.Permission) gin.HandlersChain {
+		return gin.HandlersChain{
+			middleware.RequirePermission(perm, middleware.RequireTwoFactor(adminRequireTwoFactor)),
+			audit,
+		}
+	}
 	{
-		// Category Admin APIs
-		admin.POST("/categories", categoryH.CreateCategory)
-		admin.PUT("/categories/:categoryId", categoryH.UpdateCategory)
-		admin.DELETE("/categories/:categoryId", categoryH.DeleteCategory)
+		// Edition Admin APIs
+		editions := staff.Group("", can(rbac.EditionsWrite)...)
+		editions.POST("/editions", editionH.CreateEdition)
+		editions.PUT("/editions/:editionId", editionH.UpdateEdition)
+		editions.DELETE("/editions/:editionId", editionH.DeleteEdition)
+		editions.POST("/editions/:editionId/activate", editionH.ActivateEdition)
 
-		// Nominee Admin APIs
-		admin.POST("/nominees", nomineeH.CreateNominee)
-		admin.PUT("/nominees/:id", nomineeH.UpdateNominee)
-		admin.DELETE("/nominees/:id", nomineeH.DeleteNominee)
+		// Category Admin APIs
+		categories := staff.Group("", can(rbac.CategoriesWrite)...)
+		categories.POST("/categories", categoryH.CreateCategory)
+		categories.PUT("/categories/:categoryId", categoryH.UpdateCategory)
+		categories.DELETE("/categories/:categoryId", categoryH.DeleteCategory)
+		categories.POST("/categories/:categoryId/restore", categoryH.RestoreCategory)
+		categories.PUT("/categories/:categoryId/voting-window", categoryH.ScheduleVotingWindow)
+		categories.POST("/categories/:categoryId/voting-window/extend", categoryH.ExtendVotingWindow)
+		categories.POST("/categories/:categoryId/voting-window/close", categoryH.CloseVoting)
+		categories.PUT("/categories/:categoryId/voting-method", categoryH.SetVotingMethod)
+		categories.PUT("/categories/:categoryId/jury-weight", categoryH.SetJuryWeight)
+		categories.PUT("/categories/:categoryId/vote-changes", categoryH.SetVoteChangeLimits)
 
-		// Nominee-Category Admin APIs
-		admin.POST("/nominees/:id/categories", nomineeCategoryH.AddCategory)
-		admin.DELETE("/nominees/:id/categories/:categoryId", nomineeCategoryH.RemoveCategory)
-		admin.PUT("/nominees/:id/categories", nomineeCategoryH.SetCategories)
-		admin.GET("/nominees/:id/categories", nomineeCategoryH.GetCategories)
+		// Nominee and Nominee-Category Admin APIs
+		nominees := staff.Group("", can(rbac.NomineesWrite)...)
+		nominees.POST("/nominees", nomineeH.CreateNominee)
+		nominees.PUT("/nominees/:id", nomineeH.UpdateNominee)
+		nominees.DELETE("/nominees/:id", nomineeH.DeleteNominee)
+		nominees.POST("/nominees/:id/restore", nomineeH.RestoreNominee)
+		nominees.POST("/nominees/:id/categories", nomineeCategoryH.AddCategory)
+		nominees.DELETE("/nominees/:id/categories/:categoryId", nomineeCategoryH.RemoveCategory)
+		nominees.PUT("/nominees/:id/categories", nomineeCategoryH.SetCategories)
+		nominees.GET("/nominees/:id/categories", nomineeCategoryH.GetCategories)
 
 		// Vote Admin APIs
-		admin.GET("/votes/category/:category_id", voteH.GetCategoryVotes)
-		admin.GET("/votes/all", voteH.GetAllVotes)
+		voteReaders := staff.Group("", can(rbac.VotesRead)...)
+		voteReaders.GET("/votes/category/:category_id", voteH.GetCategoryVotes)
+		voteReaders.GET("/votes/all", voteH.GetAllVotes)
+		staff.Group("", can(rbac.VotesModerate)...).POST("/votes/:id/undelete", voteH.UndeleteVote)
+
+		// Vote Allocation Admin APIs
+		allocation := staff.Group("", can(rbac.VotesAllocate)...)
+		allocation.PUT("/editions/:editionId/vote-policy", allocationH.SetVotePolicy)
+		allocation.POST("/editions/:editionId/vote-budgets/reset", allocationH.ResetVoteBudgets)
+		allocation.POST("/users/:id/votes/grant", allocationH.GrantVotes)
+		allocation.POST("/users/:id/votes/revoke", allocationH.RevokeVotes)
+
+		// Fraud Review Admin APIs
+		fraud := staff.Group("", can(rbac.FraudReview)...)
+		fraud.GET("/votes/quarantine", fraudH.ListQuarantined)
+		fraud.POST("/votes/scan", fraudH.ScanVotes)
+		fraud.POST("/votes/:id/void", fraudH.VoidVote)
+		fraud.POST("/votes/:id/restore", fraudH.RestoreVote)
+
+		// Vote Ledger Admin APIs
+		staff.Group("", can(rbac.LedgerVerify)...).GET("/ledger/verify", ledgerH.VerifyLedger)
+
+		// Role Admin APIs
+		roles := staff.Group("", can(rbac.RolesAssign)...)
+		roles.GET("/roles", userH.ListRoles)
+		roles.PUT("/users/:id/role", userH.AssignRole)
+		roles.DELETE("/users/:id/role", userH.RevokeRole)
+		roles.POST("/users/:id/jury", userH.AppointJury)
+		roles.DELETE("/users/:id/jury", userH.DismissJury)
+		roles.PUT("/profile/:id/promote", userH.PromoteUser)
+
+		// User Admin APIs
+		staff.Group("", can(rbac.UsersManage)...).POST("/users/:id/restore", userH.RestoreUser)
+
+		// Results Admin APIs
+		results := staff.Group("", can(rbac.ResultsRead)...)
+		results.GET("/results/tallies", resultsH.GetRealTimeTallies)
+		results.GET("/results/export", resultsH.ExportResults)
+		results.GET("/results/categories/:categoryId/preview", resultsH.GetCategoryResults)
+		results.GET("/results/editions/:year/preview", resultsH.GetHistoricalResults)
+
+		publishing := staff.Group("", can(rbac.ResultsPublish)...)
+		publishing.PUT("/categories/:categoryId/results-state", categoryH.SetResultsState)
+		publishing.POST("/categories/:categoryId/results/publish", categoryH.PublishResults)
+
+		// Audit Log Admin APIs
+		staff.Group("", can(rbac.AuditRead)...).GET("/audit/actions", auditH.ListAdminActions)
 	}
 
 	// 7) Configure server with proper timeouts
@@ -199,6 +383,12 @@ func Run() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go fraudSvc.Run(jobsCtx, jobCfg.FraudScanInterval)
+	go auditSvc.Run(jobsCtx, jobCfg.AuditPurgeInterval)
+
 	go func() {
 		log.Printf("Starting server on %s", server.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -208,6 +398,7 @@ func Run() {
 
 	<-quit
 	log.Println("Shutting down...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
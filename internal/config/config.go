@@ -3,12 +3,18 @@ package config
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
+	"github.com/nyashahama/music-awards/internal/oidc"
+	"github.com/nyashahama/music-awards/internal/security"
 )
 
 // DBConfig holds the settings for your Postgres connection.
@@ -69,3 +75,231 @@ func (c *DBConfig) DatabaseURL() string {
 		c.Name, c.SSLMode,
 	)
 }
+
+// JobConfig holds the schedules of background jobs.
+type JobConfig struct {
+	FraudScanInterval  time.Duration
+	AuditRetention     time.Duration
+	AuditPurgeInterval time.Duration
+}
+
+// LoadJobConfig reads background job settings from the environment, falling
+// back to defaults for unset variables.
+func LoadJobConfig() (*JobConfig, error) {
+	fraudScanInterval, err := durationFromEnv("FRAUD_SCAN_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	retentionDays, err := intFromEnv("AUDIT_RETENTION_DAYS", 90)
+	if err != nil {
+		return nil, err
+	}
+	auditPurgeInterval, err := durationFromEnv("AUDIT_PURGE_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	return &JobConfig{
+		FraudScanInterval:  fraudScanInterval,
+		AuditRetention:     time.Duration(retentionDays) * 24 * time.Hour,
+		AuditPurgeInterval: auditPurgeInterval,
+	}, nil
+}
+
+// LoadTrustedProxies reads TRUSTED_PROXIES, a comma-separated list of IPs or
+// CIDR ranges whose X-Forwarded-For headers are believed. With none set, the
+// client IP is always the address of the direct peer.
+func LoadTrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// JWTConfig holds the keys access tokens are signed with.
+type JWTConfig struct {
+	// Secret signs HS256 tokens. With Keys set it verifies nothing, unless
+	// AcceptSecret keeps tokens issued before the switch valid.
+	Secret       []byte
+	Keys         *security.KeySet
+	AcceptSecret bool
+}
+
+// LoadJWTConfig reads JWT_SECRET and the asymmetric signing keys.
+//
+// JWT_KEYS is a comma-separated list of id=path pairs naming PEM files of
+// RSA or Ed25519 keys; JWT_SIGNING_KEY_ID picks the key that signs new
+// tokens and defaults to the first one. To rotate, add the new key, then
+// make it the signing key, then drop the old key once its tokens expire.
+// JWT_ACCEPT_SECRET_TOKENS keeps JWT_SECRET tokens valid alongside the keys
+// while switching to them; it should be turned off once they have expired.
+func LoadJWTConfig() (*JWTConfig, error) {
+	cfg := &JWTConfig{Secret: []byte(os.Getenv("JWT_SECRET"))}
+	if value := os.Getenv("JWT_ACCEPT_SECRET_TOKENS"); value != "" {
+		accept, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("parsing JWT_ACCEPT_SECRET_TOKENS: %w", err)
+		}
+		if accept && len(cfg.Secret) == 0 {
+			return nil, errors.New("JWT_ACCEPT_SECRET_TOKENS needs JWT_SECRET")
+		}
+		cfg.AcceptSecret = accept
+	}
+
+	var keys []security.SigningKey
+	for _, entry := range strings.Split(os.Getenv("JWT_KEYS"), ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		id, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("parsing JWT_KEYS: %q is not id=path", entry)
+		}
+		data, err := os.ReadFile(strings.TrimSpace(path))
+		if err != nil {
+			return nil, fmt.Errorf("reading JWT key %q: %w", id, err)
+		}
+		key, err := security.ParseKeyPEM(strings.TrimSpace(id), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		if len(cfg.Secret) == 0 {
+			return nil, errors.New("set JWT_SECRET or JWT_KEYS")
+		}
+		return cfg, nil
+	}
+
+	signingID := os.Getenv("JWT_SIGNING_KEY_ID")
+	if signingID == "" {
+		signingID = keys[0].ID
+	}
+	set, err := security.NewKeySet(signingID, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("loading JWT keys: %w", err)
+	}
+	cfg.Keys = set
+	return cfg, nil
+}
+
+// LoadReceiptSecret reads RECEIPT_SECRET, the secret vote receipts are
+// signed with. It is required, and changing it voids every receipt issued.
+func LoadReceiptSecret() ([]byte, error) {
+	secret := os.Getenv("RECEIPT_SECRET")
+	if secret == "" {
+		return nil, errors.New("set RECEIPT_SECRET")
+	}
+	return []byte(secret), nil
+}
+
+// LoadOIDCProviders reads the OpenID Connect providers users can sign in
+// with. OIDC_PROVIDERS is a comma-separated list of names, and each provider
+// NAME is configured with OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID,
+// OIDC_<NAME>_CLIENT_SECRET and optionally OIDC_<NAME>_SCOPES and
+// OIDC_<NAME>_REDIRECT_URL. The redirect URL defaults to this API's
+// callback route for the provider.
+func LoadOIDCProviders() ([]oidc.Config, error) {
+	var providers []oidc.Config
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		cfg := oidc.Config{
+			Name:         name,
+			IssuerURL:    os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}
+		if cfg.IssuerURL == "" || cfg.ClientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q needs %sISSUER and %sCLIENT_ID", name, prefix, prefix)
+		}
+		if cfg.RedirectURL == "" {
+			cfg.RedirectURL = PublicAPIURL() + "/api/auth/oidc/" + name + "/callback"
+		}
+		scopes := os.Getenv(prefix + "SCOPES")
+		if scopes == "" {
+			scopes = "email profile"
+		}
+		// openid is always requested
+		for _, scope := range strings.Fields(strings.ReplaceAll(scopes, ",", " ")) {
+			if scope != "openid" {
+				cfg.Scopes = append(cfg.Scopes, scope)
+			}
+		}
+		providers = append(providers, cfg)
+	}
+	return providers, nil
+}
+
+// AdminRequireTwoFactor reads ADMIN_REQUIRE_2FA, whether staff routes only
+// accept tokens from logins with a second factor. It defaults to false so
+// that staff can enrol before it is switched on.
+func AdminRequireTwoFactor() (bool, error) {
+	value := os.Getenv("ADMIN_REQUIRE_2FA")
+	if value == "" {
+		return false, nil
+	}
+	require, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("parsing ADMIN_REQUIRE_2FA: %w", err)
+	}
+	return require, nil
+}
+
+// PublicAPIURL reads PUBLIC_API_URL, the address clients reach this API at,
+// used to build links in emails. It defaults to the local development server.
+func PublicAPIURL() string {
+	url := strings.TrimRight(os.Getenv("PUBLIC_API_URL"), "/")
+	if url == "" {
+		return "http://localhost:8080"
+	}
+	return url
+}
+
+// FrontendURL reads FRONTEND_URL, the address of the web app, used for links
+// in emails that open a page there. It defaults to the local dev server.
+func FrontendURL() string {
+	url := strings.TrimRight(os.Getenv("FRONTEND_URL"), "/")
+	if url == "" {
+		return "http://localhost:4200"
+	}
+	return url
+}
+
+// durationFromEnv parses key as a time.Duration such as "90s" or "5m".
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", key, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive", key)
+	}
+	return d, nil
+}
+
+// intFromEnv parses key as a positive integer.
+func intFromEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", key, err)
+	}
+	if n <= 0 {
+		return 0, fmt.Errorf("%s must be positive", key)
+	}
+	return n, nil
+}
@@ -117,10 +117,24 @@ func MigrateModels(db *gorm.DB) error {
 
 	err := db.Set("gorm:table_options", "WITHOUT OIDS").AutoMigrate(
 		&models.User{},
+		&models.Edition{},
 		&models.Category{},
 		&models.Nominee{},
 		&models.NomineeCategory{},
 		&models.Vote{},
+		&models.BallotRanking{},
+		&models.VoteFlag{},
+		&models.VoteAudit{},
+		&models.RegistrationAudit{},
+		&models.VoteLedgerEntry{},
+		&models.VoteChange{},
+		&models.UserToken{},
+		&models.Session{},
+		&models.Identity{},
+		&models.OIDCLoginRequest{},
+		&models.RecoveryCode{},
+		&models.LoginThrottle{},
+		&models.AdminAction{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create tables: %w", err)
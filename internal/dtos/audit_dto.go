@@ -0,0 +1,65 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+)
+
+// AdminActionResponse is an entry in the admin audit log
+type AdminActionResponse struct {
+	ActionID   uuid.UUID       `json:"action_id"`
+	ActorID    uuid.UUID       `json:"actor_id"`
+	ActorRole  string          `json:"actor_role"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type,omitempty"`
+	TargetID   string          `json:"target_id,omitempty"`
+	StatusCode int             `json:"status_code"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Diff       json.RawMessage `json:"diff,omitempty"`
+	IPAddress  string          `json:"ip_address"`
+	UserAgent  string          `json:"user_agent"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AdminActionListResponse is one page of the admin audit log
+type AdminActionListResponse struct {
+	Actions  []AdminActionResponse `json:"actions"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+	Total    int64                 `json:"total"`
+}
+
+// NewAdminActionListResponse converts a page of models.AdminAction to its
+// response DTO
+func NewAdminActionListResponse(actions []models.AdminAction, page, pageSize int, total int64) AdminActionListResponse {
+	response := AdminActionListResponse{
+		Actions:  make([]AdminActionResponse, len(actions)),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+	for i, action := range actions {
+		response.Actions[i] = AdminActionResponse{
+			ActionID:   action.ActionID,
+			ActorID:    action.ActorID,
+			ActorRole:  action.ActorRole,
+			Action:     action.Action,
+			TargetType: action.TargetType,
+			TargetID:   action.TargetID,
+			StatusCode: action.StatusCode,
+			Before:     action.Before,
+			After:      action.After,
+			Diff:       action.Diff,
+			IPAddress:  action.IPAddress,
+			UserAgent:  action.UserAgent,
+			RequestID:  action.RequestID,
+			CreatedAt:  action.CreatedAt,
+		}
+	}
+	return response
+}
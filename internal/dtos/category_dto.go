@@ -9,8 +9,10 @@ import (
 )
 
 type CreateCategoryRequest struct {
-	Name        string `json:"name" binding:"required,min=3,max=50"`
-	Description string `json:"description" binding:"max=255"`
+	// EditionID defaults to the active edition when omitted
+	EditionID   *uuid.UUID `json:"edition_id"`
+	Name        string     `json:"name" binding:"required,min=3,max=50"`
+	Description string     `json:"description" binding:"max=255"`
 }
 
 type UpdateCategoryRequest struct {
@@ -18,21 +20,83 @@ type UpdateCategoryRequest struct {
 	Description *string `json:"description" binding:"omitempty,max=255"`
 }
 
+// VotingWindowRequest schedules when voting opens and closes for a category.
+// Omitted bounds leave the window open on that side.
+type VotingWindowRequest struct {
+	OpensAt  *time.Time `json:"opens_at"`
+	ClosesAt *time.Time `json:"closes_at"`
+}
+
+// ExtendVotingWindowRequest pushes a category's voting deadline later.
+type ExtendVotingWindowRequest struct {
+	ClosesAt time.Time `json:"closes_at" binding:"required"`
+}
+
+// ResultsStateRequest hides results or opens them for admin preview
+type ResultsStateRequest struct {
+	State string `json:"state" binding:"required,oneof=hidden preview"`
+}
+
+// VotingMethodRequest switches a category between single picks and ranked ballots
+type VotingMethodRequest struct {
+	Method string `json:"method" binding:"required,oneof=single ranked"`
+}
+
+// JuryWeightRequest sets the jury's share of a blended result, from 0 to 1
+type JuryWeightRequest struct {
+	JuryWeight *float64 `json:"jury_weight" binding:"required,min=0,max=1"`
+}
+
+// VoteChangeLimitsRequest caps how often votes in a category may be changed.
+// An omitted max_changes allows unlimited changes; zero forbids them.
+type VoteChangeLimitsRequest struct {
+	MaxChanges      *int `json:"max_changes" binding:"omitempty,min=0"`
+	CooldownSeconds int  `json:"cooldown_seconds" binding:"min=0"`
+}
+
+// PublishResultsRequest publishes results, optionally at a scheduled time
+type PublishResultsRequest struct {
+	PublishAt *time.Time `json:"publish_at"`
+}
+
 type CategoryResponse struct {
-	CategoryID  uuid.UUID `json:"category_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	CategoryID       uuid.UUID  `json:"category_id"`
+	EditionID        uuid.UUID  `json:"edition_id"`
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	VotingOpensAt    *time.Time `json:"voting_opens_at,omitempty"`
+	VotingClosesAt   *time.Time `json:"voting_closes_at,omitempty"`
+	VotingOpen       bool       `json:"voting_open"`
+	ResultsState     string     `json:"results_state"`
+	ResultsPublishAt *time.Time `json:"results_publish_at,omitempty"`
+	VotingMethod     string     `json:"voting_method"`
+	JuryWeight       float64    `json:"jury_weight"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	MaxVoteChanges            *int `json:"max_vote_changes"`
+	VoteChangeCooldownSeconds int  `json:"vote_change_cooldown_seconds"`
 }
 
 // NewCategoryResponse model response
 func NewCategoryResponse(category *models.Category) CategoryResponse {
+	now := time.Now()
 	return CategoryResponse{
-		CategoryID:  category.CategoryID,
-		Name:        category.Name,
-		Description: category.Description,
-		CreatedAt:   category.CreatedAt,
-		UpdatedAt:   category.UpdatedAt,
+		CategoryID:       category.CategoryID,
+		EditionID:        category.EditionID,
+		Name:             category.Name,
+		Description:      category.Description,
+		VotingOpensAt:    category.VotingOpensAt,
+		VotingClosesAt:   category.VotingClosesAt,
+		VotingOpen:       category.IsVotingOpen(now),
+		ResultsState:     category.EffectiveResultsState(now),
+		ResultsPublishAt: category.ResultsPublishAt,
+		VotingMethod:     category.VotingMethod,
+		JuryWeight:       category.JuryWeight,
+		CreatedAt:        category.CreatedAt,
+		UpdatedAt:        category.UpdatedAt,
+
+		MaxVoteChanges:            category.MaxVoteChanges,
+		VoteChangeCooldownSeconds: category.VoteChangeCooldownSeconds,
 	}
 }
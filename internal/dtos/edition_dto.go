@@ -0,0 +1,53 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+)
+
+type CreateEditionRequest struct {
+	Name string `json:"name" binding:"required,min=3,max=100"`
+	Year int    `json:"year" binding:"required,min=1900,max=9999"`
+}
+
+type UpdateEditionRequest struct {
+	Name *string `json:"name" binding:"omitempty,min=3,max=100"`
+	Year *int    `json:"year" binding:"omitempty,min=1900,max=9999"`
+}
+
+// VotePolicyRequest sets an edition's vote allocation policy. Budget is the
+// default allowance and RoleBudgets overrides it per role under per_role.
+type VotePolicyRequest struct {
+	Policy      string         `json:"policy" binding:"required,oneof=fixed per_role per_category unlimited"`
+	Budget      *int           `json:"budget" binding:"required,min=0"`
+	RoleBudgets map[string]int `json:"role_budgets"`
+}
+
+type EditionResponse struct {
+	EditionID       uuid.UUID      `json:"edition_id"`
+	Name            string         `json:"name"`
+	Year            int            `json:"year"`
+	IsActive        bool           `json:"is_active"`
+	VotePolicy      string         `json:"vote_policy"`
+	VoteBudget      int            `json:"vote_budget"`
+	RoleVoteBudgets map[string]int `json:"role_vote_budgets,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// NewEditionResponse converts a models.Edition to an EditionResponse DTO.
+func NewEditionResponse(edition *models.Edition) EditionResponse {
+	return EditionResponse{
+		EditionID:       edition.EditionID,
+		Name:            edition.Name,
+		Year:            edition.Year,
+		IsActive:        edition.IsActive,
+		VotePolicy:      edition.VotePolicy,
+		VoteBudget:      edition.VoteBudget,
+		RoleVoteBudgets: edition.RoleVoteBudgets,
+		CreatedAt:       edition.CreatedAt,
+		UpdatedAt:       edition.UpdatedAt,
+	}
+}
@@ -0,0 +1,36 @@
+package dtos
+
+import "github.com/nyashahama/music-awards/internal/models"
+
+// LedgerProblemResponse is a point where the vote ledger chain does not hold
+type LedgerProblemResponse struct {
+	Sequence int64  `json:"sequence"`
+	Kind     string `json:"kind"`
+	Detail   string `json:"detail"`
+}
+
+// LedgerReportResponse is the result of verifying the vote ledger
+type LedgerReportResponse struct {
+	Valid    bool                    `json:"valid"`
+	Entries  int64                   `json:"entries"`
+	HeadHash string                  `json:"head_hash"`
+	Problems []LedgerProblemResponse `json:"problems"`
+}
+
+// NewLedgerReportResponse converts a models.LedgerReport to its response DTO
+func NewLedgerReportResponse(report *models.LedgerReport) LedgerReportResponse {
+	problems := make([]LedgerProblemResponse, len(report.Problems))
+	for i, problem := range report.Problems {
+		problems[i] = LedgerProblemResponse{
+			Sequence: problem.Sequence,
+			Kind:     problem.Kind,
+			Detail:   problem.Detail,
+		}
+	}
+	return LedgerReportResponse{
+		Valid:    report.Valid,
+		Entries:  report.Entries,
+		HeadHash: report.HeadHash,
+		Problems: problems,
+	}
+}
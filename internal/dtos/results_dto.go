@@ -0,0 +1,112 @@
+package dtos
+
+import (
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+)
+
+// NomineeResultResponse is one nominee's standing in a category
+type NomineeResultResponse struct {
+	NomineeID  uuid.UUID `json:"nominee_id"`
+	Name       string    `json:"name"`
+	Votes      int64     `json:"votes"`
+	Percentage float64   `json:"percentage"`
+	Rank       int       `json:"rank"`
+	Winner     bool      `json:"winner"`
+
+	// Jury and Public break a blended result down by voter class
+	Jury   *VoteShareResponse `json:"jury,omitempty"`
+	Public *VoteShareResponse `json:"public,omitempty"`
+}
+
+// VoteShareResponse is a nominee's votes and share within one voter class
+type VoteShareResponse struct {
+	Votes int64   `json:"votes"`
+	Share float64 `json:"share"`
+}
+
+// RunoffTallyResponse is a nominee's count in one instant-runoff round
+type RunoffTallyResponse struct {
+	NomineeID uuid.UUID `json:"nominee_id"`
+	Name      string    `json:"name"`
+	Votes     int64     `json:"votes"`
+}
+
+// RunoffRoundResponse is one instant-runoff round and who it eliminated
+type RunoffRoundResponse struct {
+	Round      int                   `json:"round"`
+	Tallies    []RunoffTallyResponse `json:"tallies"`
+	Exhausted  int64                 `json:"exhausted"`
+	Eliminated []uuid.UUID           `json:"eliminated"`
+}
+
+// CategoryResultsResponse is the tally of a single category. Rounds is only
+// present for ranked-choice categories.
+type CategoryResultsResponse struct {
+	CategoryID   uuid.UUID               `json:"category_id"`
+	CategoryName string                  `json:"category_name"`
+	EditionID    uuid.UUID               `json:"edition_id"`
+	VotingMethod string                  `json:"voting_method"`
+	JuryWeight   float64                 `json:"jury_weight"`
+	TotalVotes   int64                   `json:"total_votes"`
+	JuryVotes    int64                   `json:"jury_votes"`
+	PublicVotes  int64                   `json:"public_votes"`
+	Tied         bool                    `json:"tied"`
+	Nominees     []NomineeResultResponse `json:"nominees"`
+	Rounds       []RunoffRoundResponse   `json:"rounds,omitempty"`
+}
+
+// NewCategoryResultsResponse converts models.CategoryResults to its response DTO
+func NewCategoryResultsResponse(results *models.CategoryResults) CategoryResultsResponse {
+	nominees := make([]NomineeResultResponse, len(results.Nominees))
+	for i, nominee := range results.Nominees {
+		nominees[i] = NomineeResultResponse{
+			NomineeID:  nominee.NomineeID,
+			Name:       nominee.NomineeName,
+			Votes:      nominee.Votes,
+			Percentage: nominee.Percentage,
+			Rank:       nominee.Rank,
+			Winner:     nominee.Winner,
+		}
+		if results.JuryWeight > 0 {
+			nominees[i].Jury = &VoteShareResponse{Votes: nominee.Jury.Votes, Share: nominee.Jury.Share}
+			nominees[i].Public = &VoteShareResponse{Votes: nominee.Public.Votes, Share: nominee.Public.Share}
+		}
+	}
+
+	var rounds []RunoffRoundResponse
+	for _, round := range results.Rounds {
+		tallies := make([]RunoffTallyResponse, len(round.Tallies))
+		for i, tally := range round.Tallies {
+			tallies[i] = RunoffTallyResponse{
+				NomineeID: tally.NomineeID,
+				Name:      tally.NomineeName,
+				Votes:     tally.Votes,
+			}
+		}
+		eliminated := round.Eliminated
+		if eliminated == nil {
+			eliminated = []uuid.UUID{}
+		}
+		rounds = append(rounds, RunoffRoundResponse{
+			Round:      round.Round,
+			Tallies:    tallies,
+			Exhausted:  round.Exhausted,
+			Eliminated: eliminated,
+		})
+	}
+
+	return CategoryResultsResponse{
+		CategoryID:   results.CategoryID,
+		CategoryName: results.CategoryName,
+		EditionID:    results.EditionID,
+		VotingMethod: results.VotingMethod,
+		JuryWeight:   results.JuryWeight,
+		TotalVotes:   results.TotalVotes,
+		JuryVotes:    results.JuryVotes,
+		PublicVotes:  results.PublicVotes,
+		Tied:         results.Tied,
+		Nominees:     nominees,
+		Rounds:       rounds,
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/rbac"
 )
 
 // RegisterRequest is the request payload for user registration.
@@ -28,6 +29,25 @@ type UpdateProfileRequest struct {
 	Password *string `json:"password"`
 }
 
+// ResendVerificationRequest is the request payload for resending the email
+// verification link.
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ForgotPasswordRequest is the request payload for requesting a password
+// reset link.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest is the request payload for choosing a new password
+// with a reset token.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
 // UserResponse is the response payload for user details.
 type UserResponse struct {
 	UserID         uuid.UUID `json:"user_id"`
@@ -37,11 +57,65 @@ type UserResponse struct {
 	AvailableVotes int       `json:"available_votes"`
 	CreatedAt      time.Time `json:"created_at"`
 	// UpdatedAt      time.Time `json:"updated_at"`
+
+	EmailVerified bool `json:"email_verified"`
 }
 
-// LoginResponse is the response payload after a successful login.
+// LoginResponse is the response payload after a successful login or token
+// refresh. Token is the access token; ExpiresIn is its lifetime in seconds.
+//
+// If the user has two-factor authentication on, login instead responds with
+// TwoFactorRequired and a TwoFactorToken to send with their code, and
+// ExpiresIn is how long that token lasts.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in"`
+
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	TwoFactorToken    string `json:"two_factor_token,omitempty"`
+}
+
+// TwoFactorLoginRequest is the request payload for finishing a login with a
+// TOTP or recovery code.
+type TwoFactorLoginRequest struct {
+	TwoFactorToken string `json:"two_factor_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// TwoFactorCodeRequest is the request payload for changing two-factor
+// settings, confirmed with a TOTP or recovery code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorSetupResponse is the secret to enter in an authenticator app,
+// also as an otpauth URI for a QR code.
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// RecoveryCodesResponse lists the user's new recovery codes. They are not
+// shown again.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// AssignRoleRequest is the request payload for changing a user's role.
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// RoleResponse is a role and the permissions it grants.
+type RoleResponse struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// RefreshTokenRequest is the request payload for exchanging a refresh token.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // NewUserResponse converts a models.User to a UserResponse DTO.
@@ -54,5 +128,15 @@ func NewUserResponse(user *models.User) UserResponse {
 		AvailableVotes: user.AvailableVotes,
 		CreatedAt:      user.CreatedAt,
 		// UpdatedAt:      user.UpdatedAt,
+		EmailVerified: user.IsEmailVerified(),
+	}
+}
+
+// NewRoleResponse converts a role and its permissions to a RoleResponse DTO.
+func NewRoleResponse(role string, permissions []rbac.Permission) RoleResponse {
+	response := RoleResponse{Role: role, Permissions: make([]string, len(permissions))}
+	for i, perm := range permissions {
+		response.Permissions[i] = string(perm)
 	}
+	return response
 }
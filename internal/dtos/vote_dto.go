@@ -13,13 +13,63 @@ type CastVoteRequest struct {
 	NomineeID  uuid.UUID `json:"nominee_id" binding:"required"`
 }
 
+// CastBallotRequest casts a ranked ballot; Rankings lists nominee IDs from
+// first to last preference
+type CastBallotRequest struct {
+	CategoryID uuid.UUID   `json:"category_id" binding:"required"`
+	Rankings   []uuid.UUID `json:"rankings" binding:"required,min=1"`
+}
+
+// ChangeBallotRequest replaces the preference order of a ranked ballot
+type ChangeBallotRequest struct {
+	Rankings []uuid.UUID `json:"rankings" binding:"required,min=1"`
+}
+
+// AdjustVotesRequest is the number of votes to grant or revoke
+type AdjustVotesRequest struct {
+	Votes int `json:"votes" binding:"required,min=1"`
+}
+
 // VoteResponse represents a vote in API responses
 type VoteResponse struct {
-	VoteID     uuid.UUID `json:"vote_id"`
-	UserID     uuid.UUID `json:"user_id"`
-	CategoryID uuid.UUID `json:"category_id"`
-	NomineeID  uuid.UUID `json:"nominee_id"`
-	CreatedAt  time.Time `json:"created_at"`
+	VoteID     uuid.UUID   `json:"vote_id"`
+	UserID     uuid.UUID   `json:"user_id"`
+	CategoryID uuid.UUID   `json:"category_id"`
+	NomineeID  uuid.UUID   `json:"nominee_id"`
+	Rankings   []uuid.UUID `json:"rankings,omitempty"`
+	VoterClass string      `json:"voter_class"`
+	Status     string      `json:"status"`
+	ReviewedAt *time.Time  `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	// Receipt is only returned to the voter when a vote is cast or changed
+	Receipt string `json:"receipt,omitempty"`
+}
+
+// VoteMetadataResponse describes a vote without the choice it records, for
+// the admin audit log
+type VoteMetadataResponse struct {
+	VoteID     uuid.UUID  `json:"vote_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	CategoryID uuid.UUID  `json:"category_id"`
+	VoterClass string     `json:"voter_class"`
+	Status     string     `json:"status"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// VoteFlagResponse is one reason the fraud analyzer flagged a vote
+type VoteFlagResponse struct {
+	Rule      string    `json:"rule"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// QuarantinedVoteResponse is a vote awaiting fraud review
+type QuarantinedVoteResponse struct {
+	VoteResponse
+	Category CategoryDetails    `json:"category"`
+	Nominee  NomineeDetails     `json:"nominee"`
+	Flags    []VoteFlagResponse `json:"flags"`
 }
 
 // UserVotesResponse represents a user's votes with category/nominee details
@@ -27,7 +77,9 @@ type UserVotesResponse struct {
 	VoteID    uuid.UUID       `json:"vote_id"`
 	Category  CategoryDetails `json:"category"`
 	Nominee   NomineeDetails  `json:"nominee"`
+	Rankings  []uuid.UUID     `json:"rankings,omitempty"`
 	CreatedAt time.Time       `json:"created_at"`
+	Receipt   string          `json:"receipt,omitempty"`
 }
 
 type CategoryDetails struct {
@@ -47,10 +99,51 @@ func NewVoteResponse(vote *models.Vote) VoteResponse {
 		UserID:     vote.UserID,
 		CategoryID: vote.CategoryID,
 		NomineeID:  vote.NomineeID,
+		Rankings:   vote.RankedNominees(),
+		VoterClass: vote.VoterClass,
+		Status:     vote.Status,
+		ReviewedAt: vote.ReviewedAt,
 		CreatedAt:  vote.CreatedAt,
 	}
 }
 
+// NewVoteMetadataResponse converts a models.Vote to VoteMetadataResponse
+func NewVoteMetadataResponse(vote *models.Vote) VoteMetadataResponse {
+	return VoteMetadataResponse{
+		VoteID:     vote.VoteID,
+		UserID:     vote.UserID,
+		CategoryID: vote.CategoryID,
+		VoterClass: vote.VoterClass,
+		Status:     vote.Status,
+		ReviewedAt: vote.ReviewedAt,
+		CreatedAt:  vote.CreatedAt,
+	}
+}
+
+// NewQuarantinedVoteResponse converts a quarantined models.Vote and its flags
+func NewQuarantinedVoteResponse(vote *models.Vote) QuarantinedVoteResponse {
+	flags := make([]VoteFlagResponse, len(vote.Flags))
+	for i, flag := range vote.Flags {
+		flags[i] = VoteFlagResponse{
+			Rule:      flag.Rule,
+			Detail:    flag.Detail,
+			CreatedAt: flag.CreatedAt,
+		}
+	}
+	return QuarantinedVoteResponse{
+		VoteResponse: NewVoteResponse(vote),
+		Category: CategoryDetails{
+			ID:   vote.Category.CategoryID,
+			Name: vote.Category.Name,
+		},
+		Nominee: NomineeDetails{
+			ID:   vote.Nominee.NomineeID,
+			Name: vote.Nominee.Name,
+		},
+		Flags: flags,
+	}
+}
+
 // NewUserVotesResponse converts a models.Vote to UserVotesResponse
 func NewUserVotesResponse(vote *models.Vote) UserVotesResponse {
 	return UserVotesResponse{
@@ -63,6 +156,59 @@ func NewUserVotesResponse(vote *models.Vote) UserVotesResponse {
 			ID:   vote.Nominee.NomineeID,
 			Name: vote.Nominee.Name,
 		},
+		Rankings:  vote.RankedNominees(),
 		CreatedAt: vote.CreatedAt,
 	}
 }
+
+// VerifyReceiptRequest is a receipt code to check
+type VerifyReceiptRequest struct {
+	Receipt string `json:"receipt" binding:"required"`
+}
+
+// ReceiptVerificationResponse reports whether a receipt's vote is counted
+type ReceiptVerificationResponse struct {
+	Verified bool   `json:"verified"`
+	Counted  bool   `json:"counted"`
+	Status   string `json:"status"`
+}
+
+// VoteChangeResponse is one change in a vote's history. Rankings are omitted
+// for single-choice votes.
+type VoteChangeResponse struct {
+	ChangedBy         *uuid.UUID  `json:"changed_by,omitempty"`
+	PreviousNomineeID uuid.UUID   `json:"previous_nominee_id"`
+	NewNomineeID      uuid.UUID   `json:"new_nominee_id"`
+	PreviousRankings  []uuid.UUID `json:"previous_rankings,omitempty"`
+	NewRankings       []uuid.UUID `json:"new_rankings,omitempty"`
+	ChangedAt         time.Time   `json:"changed_at"`
+}
+
+// VoteHistoryResponse lists the changes made to a vote, oldest first
+type VoteHistoryResponse struct {
+	VoteID        uuid.UUID            `json:"vote_id"`
+	ChangeCount   int                  `json:"change_count"`
+	LastChangedAt *time.Time           `json:"last_changed_at,omitempty"`
+	Changes       []VoteChangeResponse `json:"changes"`
+}
+
+// NewVoteHistoryResponse converts a vote and its changes to VoteHistoryResponse
+func NewVoteHistoryResponse(vote *models.Vote, changes []models.VoteChange) VoteHistoryResponse {
+	response := VoteHistoryResponse{
+		VoteID:        vote.VoteID,
+		ChangeCount:   vote.ChangeCount,
+		LastChangedAt: vote.LastChangedAt,
+		Changes:       make([]VoteChangeResponse, len(changes)),
+	}
+	for i, change := range changes {
+		response.Changes[i] = VoteChangeResponse{
+			ChangedBy:         change.ChangedBy,
+			PreviousNomineeID: change.PreviousNomineeID,
+			NewNomineeID:      change.NewNomineeID,
+			PreviousRankings:  change.PreviousRankings,
+			NewRankings:       change.NewRankings,
+			ChangedAt:         change.ChangedAt,
+		}
+	}
+	return response
+}
@@ -0,0 +1,25 @@
+// Package fingerprint carries details of the client behind a request, used
+// to audit votes and registrations.
+package fingerprint
+
+import "context"
+
+// Fingerprint identifies the client and request that triggered a change.
+type Fingerprint struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying fp.
+func NewContext(ctx context.Context, fp Fingerprint) context.Context {
+	return context.WithValue(ctx, contextKey{}, fp)
+}
+
+// FromContext returns the fingerprint stored in ctx, if any.
+func FromContext(ctx context.Context) (Fingerprint, bool) {
+	fp, ok := ctx.Value(contextKey{}).(Fingerprint)
+	return fp, ok
+}
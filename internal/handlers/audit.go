@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nyashahama/music-awards/internal/dtos"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/nyashahama/music-awards/internal/services"
+)
+
+type AuditHandler struct {
+	auditService services.AuditService
+}
+
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// ListAdminActions pages through the admin audit log, newest first. It can
+// be filtered by ?actor_id=, ?action= (such as "PUT /api/nominees/:id"),
+// ?target_type=, ?target_id= and a ?from= and ?to= time in RFC 3339, and
+// paged with ?page= and ?page_size=.
+func (h *AuditHandler) ListAdminActions(c *gin.Context) {
+	actorID, ok := parseOptionalUUID(c, "actor_id")
+	if !ok {
+		return
+	}
+	from, ok := parseOptionalTime(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseOptionalTime(c, "to")
+	if !ok {
+		return
+	}
+	page, ok := parseOptionalInt(c, "page")
+	if !ok {
+		return
+	}
+	pageSize, ok := parseOptionalInt(c, "page_size")
+	if !ok {
+		return
+	}
+
+	filter := repositories.AdminActionFilter{
+		ActorID:    actorID,
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		From:       from,
+		To:         to,
+	}
+	result, err := h.auditService.ListAdminActions(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		handleAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewAdminActionListResponse(result.Actions, result.Page, result.PageSize, result.Total))
+}
+
+// parseOptionalTime reads an RFC 3339 time query parameter, returning the
+// zero time when it is absent. It writes a 400 response and returns false
+// when it is malformed.
+func parseOptionalTime(c *gin.Context, name string) (time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// parseOptionalInt reads an integer query parameter, returning 0 when it is
+// absent. It writes a 400 response and returns false when it is malformed.
+func parseOptionalInt(c *gin.Context, name string) (int, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return n, true
+}
+
+func handleAuditError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAuditQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/dtos"
 	"github.com/nyashahama/music-awards/internal/middleware"
+	"github.com/nyashahama/music-awards/internal/rbac"
 	"github.com/nyashahama/music-awards/internal/services"
 
 	"gorm.io/gorm"
@@ -21,7 +23,7 @@ func NewCategoryHandler(categoryService services.CategoryService) *CategoryHandl
 	return &CategoryHandler{categoryService: categoryService}
 }
 
-func (h *CategoryHandler) RegisterRoutes(r *gin.Engine) {
+func (h *CategoryHandler) RegisterRoutes(r *gin.Engine, authenticate gin.HandlerFunc) {
 	// Public category endpoints
 	categories := r.Group("/categories")
 	categories.GET("", h.ListCategories)
@@ -30,10 +32,22 @@ func (h *CategoryHandler) RegisterRoutes(r *gin.Engine) {
 
 	// admin
 	adminCategories := r.Group("/categories")
-	adminCategories.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	adminCategories.Use(authenticate, middleware.RequirePermission(rbac.CategoriesWrite))
 	adminCategories.POST("", h.CreateCategory)
 	adminCategories.PUT("/:categoryId", h.UpdateCategory)
 	adminCategories.DELETE("/:categoryId", h.DeleteCategory)
+	adminCategories.POST("/:categoryId/restore", h.RestoreCategory)
+	adminCategories.PUT("/:categoryId/voting-window", h.ScheduleVotingWindow)
+	adminCategories.POST("/:categoryId/voting-window/extend", h.ExtendVotingWindow)
+	adminCategories.POST("/:categoryId/voting-window/close", h.CloseVoting)
+	adminCategories.PUT("/:categoryId/voting-method", h.SetVotingMethod)
+	adminCategories.PUT("/:categoryId/jury-weight", h.SetJuryWeight)
+	adminCategories.PUT("/:categoryId/vote-changes", h.SetVoteChangeLimits)
+
+	resultsCategories := r.Group("/categories")
+	resultsCategories.Use(authenticate, middleware.RequirePermission(rbac.ResultsPublish))
+	resultsCategories.PUT("/:categoryId/results-state", h.SetResultsState)
+	resultsCategories.POST("/:categoryId/results/publish", h.PublishResults)
 }
 
 func (h *CategoryHandler) CreateCategory(c *gin.Context) {
@@ -43,7 +57,12 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := h.categoryService.CreateCategory(c.Request.Context(), req.Name, req.Description)
+	editionID := uuid.Nil
+	if req.EditionID != nil {
+		editionID = *req.EditionID
+	}
+
+	category, err := h.categoryService.CreateCategory(c.Request.Context(), editionID, req.Name, req.Description)
 	if err != nil {
 		handleCategoryError(c, err)
 		return
@@ -91,7 +110,12 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := h.categoryService.DeleteCategory(c.Request.Context(), categoryID); err != nil {
+	force, ok := parseForce(c)
+	if !ok {
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(c.Request.Context(), categoryID, force); err != nil {
 		handleCategoryError(c, err)
 		return
 	}
@@ -99,6 +123,22 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (h *CategoryHandler) RestoreCategory(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	category, err := h.categoryService.RestoreCategory(c.Request.Context(), categoryID)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
+}
+
 func (h *CategoryHandler) GetCategory(c *gin.Context) {
 	categoryID, err := uuid.Parse(c.Param("categoryId"))
 	if err != nil {
@@ -115,8 +155,29 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
 }
 
+// Snapshot returns the category as GetCategory shows it, for the audit log.
+func (h *CategoryHandler) Snapshot(ctx context.Context, id uuid.UUID) (any, error) {
+	category, err := h.categoryService.GetCategoryDetails(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dtos.NewCategoryResponse(category), nil
+}
+
+// ListCategories lists the categories of the edition given by ?edition_id=,
+// defaulting to the active edition.
 func (h *CategoryHandler) ListCategories(c *gin.Context) {
-	categories, err := h.categoryService.ListAllCategories(c.Request.Context())
+	editionID := uuid.Nil
+	if raw := c.Query("edition_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid edition ID"})
+			return
+		}
+		editionID = id
+	}
+
+	categories, err := h.categoryService.ListEditionCategories(c.Request.Context(), editionID)
 	if err != nil {
 		handleCategoryError(c, err)
 		return
@@ -143,12 +204,193 @@ func (h *CategoryHandler) ListActiveCategories(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *CategoryHandler) ScheduleVotingWindow(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	var req dtos.VotingWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryService.ScheduleVotingWindow(c.Request.Context(), categoryID, req.OpensAt, req.ClosesAt)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
+}
+
+func (h *CategoryHandler) ExtendVotingWindow(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	var req dtos.ExtendVotingWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryService.ExtendVotingWindow(c.Request.Context(), categoryID, req.ClosesAt)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
+}
+
+func (h *CategoryHandler) CloseVoting(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	category, err := h.categoryService.CloseVoting(c.Request.Context(), categoryID)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
+}
+
+func (h *CategoryHandler) SetResultsState(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	var req dtos.ResultsStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryService.SetResultsState(c.Request.Context(), categoryID, req.State)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
+}
+
+func (h *CategoryHandler) PublishResults(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	// The body is optional; an empty one publishes immediately
+	var req dtos.PublishResultsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	category, err := h.categoryService.PublishResults(c.Request.Context(), categoryID, req.PublishAt)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
+}
+
+func (h *CategoryHandler) SetVotingMethod(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	var req dtos.VotingMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryService.SetVotingMethod(c.Request.Context(), categoryID, req.Method)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
+}
+
+func (h *CategoryHandler) SetJuryWeight(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	var req dtos.JuryWeightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryService.SetJuryWeight(c.Request.Context(), categoryID, *req.JuryWeight)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
+}
+
+func (h *CategoryHandler) SetVoteChangeLimits(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	var req dtos.VoteChangeLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category, err := h.categoryService.SetVoteChangeLimits(c.Request.Context(), categoryID, req.MaxChanges, req.CooldownSeconds)
+	if err != nil {
+		handleCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResponse(category))
+}
+
 func handleCategoryError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, services.ErrCategoryNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 	case errors.Is(err, services.ErrCategoryExists):
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCategoryHasVotes):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error() + "; pass force=true to delete it anyway"})
+	case errors.Is(err, services.ErrEditionNotFound), errors.Is(err, services.ErrNoActiveEdition):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidVotingWindow), errors.Is(err, services.ErrInvalidResultsState),
+		errors.Is(err, services.ErrInvalidVotingMethod), errors.Is(err, services.ErrInvalidJuryWeight),
+		errors.Is(err, services.ErrInvalidVoteChangeLimits):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	case errors.Is(err, gorm.ErrRecordNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
 	default:
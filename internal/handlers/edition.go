@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/dtos"
+	"github.com/nyashahama/music-awards/internal/services"
+	"gorm.io/gorm"
+)
+
+type EditionHandler struct {
+	editionService  services.EditionService
+	categoryService services.CategoryService
+}
+
+func NewEditionHandler(editionService services.EditionService, categoryService services.CategoryService) *EditionHandler {
+	return &EditionHandler{
+		editionService:  editionService,
+		categoryService: categoryService,
+	}
+}
+
+func (h *EditionHandler) CreateEdition(c *gin.Context) {
+	var req dtos.CreateEditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	edition, err := h.editionService.CreateEdition(c.Request.Context(), req.Name, req.Year)
+	if err != nil {
+		handleEditionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dtos.NewEditionResponse(edition))
+}
+
+func (h *EditionHandler) UpdateEdition(c *gin.Context) {
+	editionID, err := uuid.Parse(c.Param("editionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid edition ID"})
+		return
+	}
+
+	var req dtos.UpdateEditionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := ""
+	if req.Name != nil {
+		name = *req.Name
+	}
+
+	year := 0
+	if req.Year != nil {
+		year = *req.Year
+	}
+
+	edition, err := h.editionService.UpdateEdition(c.Request.Context(), editionID, name, year)
+	if err != nil {
+		handleEditionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewEditionResponse(edition))
+}
+
+func (h *EditionHandler) DeleteEdition(c *gin.Context) {
+	editionID, err := uuid.Parse(c.Param("editionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid edition ID"})
+		return
+	}
+
+	if err := h.editionService.DeleteEdition(c.Request.Context(), editionID); err != nil {
+		handleEditionError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *EditionHandler) ActivateEdition(c *gin.Context) {
+	editionID, err := uuid.Parse(c.Param("editionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid edition ID"})
+		return
+	}
+
+	edition, err := h.editionService.ActivateEdition(c.Request.Context(), editionID)
+	if err != nil {
+		handleEditionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewEditionResponse(edition))
+}
+
+func (h *EditionHandler) GetEdition(c *gin.Context) {
+	editionID, err := uuid.Parse(c.Param("editionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid edition ID"})
+		return
+	}
+
+	edition, err := h.editionService.GetEdition(c.Request.Context(), editionID)
+	if err != nil {
+		handleEditionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewEditionResponse(edition))
+}
+
+// Snapshot returns the edition as GetEdition shows it, for the audit log.
+func (h *EditionHandler) Snapshot(ctx context.Context, id uuid.UUID) (any, error) {
+	edition, err := h.editionService.GetEdition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dtos.NewEditionResponse(edition), nil
+}
+
+func (h *EditionHandler) GetActiveEdition(c *gin.Context) {
+	edition, err := h.editionService.GetActiveEdition(c.Request.Context())
+	if err != nil {
+		handleEditionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewEditionResponse(edition))
+}
+
+func (h *EditionHandler) ListEditions(c *gin.Context) {
+	editions, err := h.editionService.ListEditions(c.Request.Context())
+	if err != nil {
+		handleEditionError(c, err)
+		return
+	}
+
+	response := make([]dtos.EditionResponse, len(editions))
+	for i, edition := range editions {
+		response[i] = dtos.NewEditionResponse(&edition)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *EditionHandler) ListEditionCategories(c *gin.Context) {
+	editionID, err := uuid.Parse(c.Param("editionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid edition ID"})
+		return
+	}
+
+	categories, err := h.categoryService.ListEditionCategories(c.Request.Context(), editionID)
+	if err != nil {
+		handleEditionError(c, err)
+		return
+	}
+
+	response := make([]dtos.CategoryResponse, len(categories))
+	for i, cat := range categories {
+		response[i] = dtos.NewCategoryResponse(&cat)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func handleEditionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrEditionNotFound), errors.Is(err, services.ErrNoActiveEdition):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEditionExists), errors.Is(err, services.ErrEditionInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "edition not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
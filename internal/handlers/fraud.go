@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/dtos"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/services"
+	"gorm.io/gorm"
+)
+
+type FraudHandler struct {
+	fraudService services.FraudService
+}
+
+func NewFraudHandler(fraudService services.FraudService) *FraudHandler {
+	return &FraudHandler{fraudService: fraudService}
+}
+
+func (h *FraudHandler) ListQuarantined(c *gin.Context) {
+	votes, err := h.fraudService.ListQuarantined(c.Request.Context())
+	if err != nil {
+		handleFraudError(c, err)
+		return
+	}
+
+	response := make([]dtos.QuarantinedVoteResponse, len(votes))
+	for i := range votes {
+		response[i] = dtos.NewQuarantinedVoteResponse(&votes[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ScanVotes runs the fraud analyzer now rather than waiting for its next
+// scheduled scan.
+func (h *FraudHandler) ScanVotes(c *gin.Context) {
+	quarantined, err := h.fraudService.Analyze(c.Request.Context())
+	if err != nil {
+		handleFraudError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"votes_quarantined": quarantined})
+}
+
+func (h *FraudHandler) VoidVote(c *gin.Context) {
+	h.reviewVote(c, h.fraudService.VoidVote)
+}
+
+func (h *FraudHandler) RestoreVote(c *gin.Context) {
+	h.reviewVote(c, h.fraudService.RestoreVote)
+}
+
+func (h *FraudHandler) reviewVote(c *gin.Context, review func(ctx context.Context, voteID, reviewerID uuid.UUID) (*models.Vote, error)) {
+	voteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vote ID"})
+		return
+	}
+	reviewerID := c.MustGet("user_id").(uuid.UUID)
+
+	vote, err := review(c.Request.Context(), voteID, reviewerID)
+	if err != nil {
+		handleFraudError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewVoteResponse(vote))
+}
+
+func handleFraudError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidVoteReview):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "vote not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nyashahama/music-awards/internal/security"
+)
+
+// JWKSHandler publishes the public keys access tokens are signed with, so
+// other services can verify them without sharing a secret.
+type JWKSHandler struct {
+	keys *security.KeySet
+}
+
+// NewJWKSHandler serves keys, which may be nil when tokens are signed with
+// the HS256 secret.
+func NewJWKSHandler(keys *security.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
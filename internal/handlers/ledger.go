@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nyashahama/music-awards/internal/dtos"
+	"github.com/nyashahama/music-awards/internal/services"
+)
+
+type LedgerHandler struct {
+	ledgerService services.LedgerService
+}
+
+func NewLedgerHandler(ledgerService services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{ledgerService: ledgerService}
+}
+
+// VerifyLedger walks the whole vote ledger and reports any gap or tampering.
+func (h *LedgerHandler) VerifyLedger(c *gin.Context) {
+	report, err := h.ledgerService.Verify(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewLedgerReportResponse(report))
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/dtos"
 	"github.com/nyashahama/music-awards/internal/middleware"
+	"github.com/nyashahama/music-awards/internal/rbac"
 	"github.com/nyashahama/music-awards/internal/services"
 	"gorm.io/gorm"
 )
@@ -20,7 +22,7 @@ func NewNomineeHandler(nomineeService services.NomineeService) *NomineeHandler {
 	return &NomineeHandler{nomineeService: nomineeService}
 }
 
-func (h *NomineeHandler) RegisterRoutes(r *gin.Engine) {
+func (h *NomineeHandler) RegisterRoutes(r *gin.Engine, authenticate gin.HandlerFunc) {
 	public := r.Group("/nominees")
 	{
 		public.GET("", h.GetAllNominees)
@@ -28,11 +30,12 @@ func (h *NomineeHandler) RegisterRoutes(r *gin.Engine) {
 	}
 
 	admin := r.Group("/nominees")
-	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	admin.Use(authenticate, middleware.RequirePermission(rbac.NomineesWrite))
 	{
 		admin.POST("", h.CreateNominee)
 		admin.PUT("/:id", h.UpdateNominee)
 		admin.DELETE("/:id", h.DeleteNominee)
+		admin.POST("/:id/restore", h.RestoreNominee)
 	}
 }
 
@@ -81,7 +84,12 @@ func (h *NomineeHandler) DeleteNominee(c *gin.Context) {
 		return
 	}
 
-	if err := h.nomineeService.DeleteNominee(c.Request.Context(), id); err != nil {
+	force, ok := parseForce(c)
+	if !ok {
+		return
+	}
+
+	if err := h.nomineeService.DeleteNominee(c.Request.Context(), id, force); err != nil {
 		handleNomineeError(c, err)
 		return
 	}
@@ -89,6 +97,22 @@ func (h *NomineeHandler) DeleteNominee(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (h *NomineeHandler) RestoreNominee(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	nominee, err := h.nomineeService.RestoreNominee(c.Request.Context(), id)
+	if err != nil {
+		handleNomineeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewNomineeResponse(nominee))
+}
+
 func (h *NomineeHandler) GetNomineeDetails(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -105,6 +129,16 @@ func (h *NomineeHandler) GetNomineeDetails(c *gin.Context) {
 	c.JSON(http.StatusOK, dtos.NewNomineeResponse(nominee))
 }
 
+// Snapshot returns the nominee as GetNomineeDetails shows it, for the audit
+// log.
+func (h *NomineeHandler) Snapshot(ctx context.Context, id uuid.UUID) (any, error) {
+	nominee, err := h.nomineeService.GetNomineeDetails(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dtos.NewNomineeResponse(nominee), nil
+}
+
 func (h *NomineeHandler) GetAllNominees(c *gin.Context) {
 	nominees, err := h.nomineeService.GetAllNominees(c.Request.Context())
 	if err != nil {
@@ -123,6 +157,8 @@ func handleNomineeError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, services.ErrNomineeNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNomineeHasVotes):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error() + "; pass force=true to delete it anyway"})
 	case errors.Is(err, services.ErrInvalidJSON):
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON format"})
 	case errors.Is(err, services.ErrCategoryNotFound):
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/dtos"
 	"github.com/nyashahama/music-awards/internal/middleware"
+	"github.com/nyashahama/music-awards/internal/rbac"
 	"github.com/nyashahama/music-awards/internal/services"
 	"gorm.io/gorm"
 )
@@ -20,9 +21,9 @@ func NewNomineeCategoryHandler(service services.NomineeCategoryService) *Nominee
 	return &NomineeCategoryHandler{service: service}
 }
 
-func (h *NomineeCategoryHandler) RegisterRoutes(r *gin.Engine) {
+func (h *NomineeCategoryHandler) RegisterRoutes(r *gin.Engine, authenticate gin.HandlerFunc) {
 	nomineeCategoryGroup := r.Group("/nominees/:id/categories")
-	nomineeCategoryGroup.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	nomineeCategoryGroup.Use(authenticate, middleware.RequirePermission(rbac.NomineesWrite))
 	{
 		nomineeCategoryGroup.POST("", h.AddCategory)
 		nomineeCategoryGroup.DELETE("/:categoryId", h.RemoveCategory)
@@ -31,7 +32,7 @@ func (h *NomineeCategoryHandler) RegisterRoutes(r *gin.Engine) {
 	}
 
 	categoryGroup := r.Group("/categories/:categoryId/nominees")
-	categoryGroup.Use(middleware.AuthMiddleware())
+	categoryGroup.Use(authenticate)
 	{
 		categoryGroup.GET("", h.GetNominees)
 	}
@@ -159,6 +160,10 @@ func handleNomineeCategoryError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, services.ErrInvalidID):
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCategoryLinkHasVotes):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 	}
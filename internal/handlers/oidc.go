@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nyashahama/music-awards/internal/oidc"
+	"github.com/nyashahama/music-awards/internal/services"
+)
+
+// OIDCHandler signs users in through OpenID Connect providers.
+type OIDCHandler struct {
+	oidcService services.OIDCService
+}
+
+func NewOIDCHandler(oidcService services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// StartLogin redirects the user to the provider to sign in.
+func (h *OIDCHandler) StartLogin(c *gin.Context) {
+	authURL, err := h.oidcService.StartLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		handleOIDCError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback is where the provider sends the user back to. It responds like
+// login, with an access token and a refresh token.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if c.Query("error") != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": oidc.ErrLoginFailed.Error()})
+		return
+	}
+
+	tokens, err := h.oidcService.CompleteLogin(c.Request.Context(), c.Param("provider"), c.Query("state"), c.Query("code"))
+	if err != nil {
+		handleOIDCError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
+}
+
+func handleOIDCError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownProvider):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidLoginState):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, oidc.ErrLoginFailed):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": oidc.ErrLoginFailed.Error()})
+	case errors.Is(err, services.ErrEmailExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "an account with this email already exists; sign in with your password to use it"})
+	default:
+		handleServiceError(c, err)
+	}
+}
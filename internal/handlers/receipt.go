@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nyashahama/music-awards/internal/dtos"
+	"github.com/nyashahama/music-awards/internal/services"
+	"gorm.io/gorm"
+)
+
+type ReceiptHandler struct {
+	receiptService services.ReceiptService
+}
+
+func NewReceiptHandler(receiptService services.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{receiptService: receiptService}
+}
+
+// VerifyReceipt is public: anyone holding a receipt can check that its vote
+// is counted, but the response never reveals who was voted for.
+func (h *ReceiptHandler) VerifyReceipt(c *gin.Context) {
+	var req dtos.VerifyReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	verification, err := h.receiptService.Verify(c.Request.Context(), req.Receipt)
+	if err != nil {
+		handleReceiptError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.ReceiptVerificationResponse{
+		Verified: verification.Verified,
+		Counted:  verification.Counted,
+		Status:   verification.Status,
+	})
+}
+
+func handleReceiptError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidReceipt):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid receipt"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "no vote matches this receipt"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -1,2 +1,172 @@
 package handlers
 
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/dtos"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/services"
+)
+
+type ResultsHandler struct {
+	resultsService services.ResultsService
+}
+
+func NewResultsHandler(resultsService services.ResultsService) *ResultsHandler {
+	return &ResultsHandler{resultsService: resultsService}
+}
+
+func (h *ResultsHandler) GetPublishedCategoryResults(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	results, err := h.resultsService.GetPublishedCategoryResults(c.Request.Context(), categoryID)
+	if err != nil {
+		handleResultsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResultsResponse(results))
+}
+
+func (h *ResultsHandler) GetPublishedHistoricalResults(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+		return
+	}
+
+	results, err := h.resultsService.GetPublishedHistoricalResults(c.Request.Context(), year)
+	if err != nil {
+		handleResultsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newCategoryResultsList(results))
+}
+
+func (h *ResultsHandler) GetCategoryResults(c *gin.Context) {
+	categoryID, err := uuid.Parse(c.Param("categoryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category ID"})
+		return
+	}
+
+	results, err := h.resultsService.GetCategoryResults(c.Request.Context(), categoryID)
+	if err != nil {
+		handleResultsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewCategoryResultsResponse(results))
+}
+
+func (h *ResultsHandler) GetRealTimeTallies(c *gin.Context) {
+	results, err := h.resultsService.GetRealTimeTallies(c.Request.Context())
+	if err != nil {
+		handleResultsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newCategoryResultsList(results))
+}
+
+func (h *ResultsHandler) GetHistoricalResults(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+		return
+	}
+
+	results, err := h.resultsService.GetHistoricalResults(c.Request.Context(), year)
+	if err != nil {
+		handleResultsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newCategoryResultsList(results))
+}
+
+// ExportResults downloads standings as ?format=csv|tsv|json|ods for a single
+// ?category_id= or a whole ?edition_id= (default: the active edition).
+func (h *ResultsHandler) ExportResults(c *gin.Context) {
+	categoryID, ok := parseOptionalUUID(c, "category_id")
+	if !ok {
+		return
+	}
+	editionID, ok := parseOptionalUUID(c, "edition_id")
+	if !ok {
+		return
+	}
+
+	export, err := h.resultsService.ExportResults(c.Request.Context(), c.DefaultQuery("format", "csv"), categoryID, editionID)
+	if err != nil {
+		handleResultsError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.Filename))
+	c.Data(http.StatusOK, export.ContentType, export.Data)
+}
+
+// parseOptionalUUID reads a UUID query parameter, returning uuid.Nil when it
+// is absent. It writes a 400 response and returns false when it is malformed.
+func parseOptionalUUID(c *gin.Context, name string) (uuid.UUID, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return uuid.Nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// parseForce reads the optional force query flag used to override delete
+// guards. It writes a 400 response and returns false when it is malformed.
+func parseForce(c *gin.Context) (bool, bool) {
+	raw := c.Query("force")
+	if raw == "" {
+		return false, true
+	}
+	force, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid force"})
+		return false, false
+	}
+	return force, true
+}
+
+func newCategoryResultsList(results []models.CategoryResults) []dtos.CategoryResultsResponse {
+	response := make([]dtos.CategoryResultsResponse, len(results))
+	for i := range results {
+		response[i] = dtos.NewCategoryResultsResponse(&results[i])
+	}
+	return response
+}
+
+func handleResultsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound),
+		errors.Is(err, services.ErrEditionNotFound),
+		errors.Is(err, services.ErrNoActiveEdition),
+		errors.Is(err, services.ErrResultsHidden):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrResultsEmbargoed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnsupportedExportFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
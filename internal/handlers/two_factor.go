@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/dtos"
+)
+
+// CompleteTwoFactorLogin finishes a login that asked for a second factor.
+func (h *UserHandler) CompleteTwoFactorLogin(c *gin.Context) {
+	var req dtos.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.userService.CompleteTwoFactorLogin(c.Request.Context(), req.TwoFactorToken, req.Code)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
+}
+
+// SetupTwoFactor starts enrolling the current user in two-factor
+// authentication.
+func (h *UserHandler) SetupTwoFactor(c *gin.Context) {
+	setup, err := h.userService.SetupTwoFactor(c.Request.Context(), c.MustGet("user_id").(uuid.UUID))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.TwoFactorSetupResponse{Secret: setup.Secret, OTPAuthURI: setup.URI})
+}
+
+// EnableTwoFactor turns on two-factor authentication for the current user
+// and responds with their recovery codes.
+func (h *UserHandler) EnableTwoFactor(c *gin.Context) {
+	var req dtos.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.userService.EnableTwoFactor(c.Request.Context(), c.MustGet("user_id").(uuid.UUID), req.Code)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor turns off two-factor authentication for the current user.
+func (h *UserHandler) DisableTwoFactor(c *gin.Context) {
+	var req dtos.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.DisableTwoFactor(c.Request.Context(), c.MustGet("user_id").(uuid.UUID), req.Code); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes.
+func (h *UserHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dtos.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.userService.RegenerateRecoveryCodes(c.Request.Context(), c.MustGet("user_id").(uuid.UUID), req.Code)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.RecoveryCodesResponse{RecoveryCodes: codes})
+}
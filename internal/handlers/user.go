@@ -2,13 +2,17 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/dtos"
 	"github.com/nyashahama/music-awards/internal/middleware"
+	"github.com/nyashahama/music-awards/internal/rbac"
 	"github.com/nyashahama/music-awards/internal/services"
 	"gorm.io/gorm"
 )
@@ -21,15 +25,31 @@ func NewUserHandler(userService services.UserService) *UserHandler {
 	return &UserHandler{userService: userService}
 }
 
-func (h *UserHandler) RegisterRoutes(r *gin.Engine) {
+func (h *UserHandler) RegisterRoutes(r *gin.Engine, authenticate gin.HandlerFunc) {
 	auth := r.Group("/auth")
 	{
 		auth.POST("/register", h.Register)
 		auth.POST("/login", h.Login)
+		auth.GET("/verify-email", h.VerifyEmail)
+		auth.POST("/verify-email/resend", h.ResendVerification)
+		auth.POST("/forgot-password", h.ForgotPassword)
+		auth.POST("/reset-password", h.ResetPassword)
+		auth.POST("/token/refresh", h.RefreshToken)
+		auth.POST("/logout", authenticate, h.Logout)
+		auth.POST("/login/2fa", h.CompleteTwoFactorLogin)
+	}
+
+	twoFactor := r.Group("/auth/2fa")
+	twoFactor.Use(authenticate)
+	{
+		twoFactor.POST("/setup", h.SetupTwoFactor)
+		twoFactor.POST("/enable", h.EnableTwoFactor)
+		twoFactor.POST("/disable", h.DisableTwoFactor)
+		twoFactor.POST("/recovery-codes", h.RegenerateRecoveryCodes)
 	}
 
 	users := r.Group("/users")
-	users.Use(middleware.AuthMiddleware())
+	users.Use(authenticate)
 	{
 		users.GET("", h.ListAllUsers)
 		users.GET("/:id", h.GetProfile)
@@ -37,6 +57,18 @@ func (h *UserHandler) RegisterRoutes(r *gin.Engine) {
 		users.DELETE("/:id", h.DeleteAccount)
 		users.POST("/:id/promote", h.PromoteUser)
 	}
+
+	roles := users.Group("")
+	roles.Use(middleware.RequirePermission(rbac.RolesAssign))
+	{
+		roles.PUT("/:id/role", h.AssignRole)
+		roles.DELETE("/:id/role", h.RevokeRole)
+		roles.POST("/:id/jury", h.AppointJury)
+		roles.DELETE("/:id/jury", h.DismissJury)
+	}
+	users.POST("/:id/restore", middleware.RequirePermission(rbac.UsersManage), h.RestoreUser)
+
+	r.GET("/roles", authenticate, middleware.RequirePermission(rbac.RolesAssign), h.ListRoles)
 }
 
 func (h *UserHandler) Register(c *gin.Context) {
@@ -62,19 +94,129 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.userService.Login(c.Request.Context(), req.Email, req.Password)
+	tokens, err := h.userService.Login(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dtos.LoginResponse{Token: token})
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
+}
+
+// RefreshToken exchanges a refresh token for a new access token and refresh
+// token. The old refresh token stops working.
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req dtos.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.userService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(tokens))
+}
+
+// Logout ends the session of the access token used to call it.
+func (h *UserHandler) Logout(c *gin.Context) {
+	sessionID, ok := c.Get("session_id")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "token has no session"})
+		return
+	}
+
+	if err := h.userService.Logout(c.Request.Context(), sessionID.(uuid.UUID)); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func newLoginResponse(tokens *services.AuthTokens) dtos.LoginResponse {
+	if tokens.TwoFactorToken != "" {
+		return dtos.LoginResponse{
+			TwoFactorRequired: true,
+			TwoFactorToken:    tokens.TwoFactorToken,
+			ExpiresIn:         int(tokens.ExpiresIn.Seconds()),
+		}
+	}
+	return dtos.LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int(tokens.ExpiresIn.Seconds()),
+	}
+}
+
+// VerifyEmail confirms the address of the account a verification link was
+// sent to.
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	user, err := h.userService.VerifyEmail(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewUserResponse(user))
+}
+
+// ResendVerification emails a new verification link. It answers the same
+// whether or not the address belongs to an unverified account.
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req dtos.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the account exists and is unverified, a verification email has been sent"})
+}
+
+// ForgotPassword emails a password reset link. It answers the same whether
+// or not the address belongs to an account.
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req dtos.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the account exists, a password reset email has been sent"})
+}
+
+// ResetPassword sets a new password using the token from a reset link. All
+// of the user's existing sessions are ended.
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req dtos.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password has been reset"})
 }
 
 func (h *UserHandler) ListAllUsers(c *gin.Context) {
-	currentUserRole := c.MustGet("user_role").(string)
-	if currentUserRole != "admin" {
+	if !middleware.HasPermission(c, rbac.UsersRead) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		return
 	}
@@ -100,10 +242,9 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	}
 
 	currentUserID := c.MustGet("user_id").(uuid.UUID)
-	currentUserRole := c.MustGet("user_role").(string)
 
 	// Add authorization check
-	if currentUserRole != "admin" && currentUserID != userID {
+	if currentUserID != userID && !middleware.HasPermission(c, rbac.UsersRead) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		return
 	}
@@ -117,6 +258,15 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, dtos.NewUserResponse(user))
 }
 
+// Snapshot returns the user as GetProfile shows it, for the audit log.
+func (h *UserHandler) Snapshot(ctx context.Context, id uuid.UUID) (any, error) {
+	user, err := h.userService.GetUserProfile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dtos.NewUserResponse(user), nil
+}
+
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -125,10 +275,9 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	currentUserID := c.MustGet("user_id").(uuid.UUID)
-	currentUserRole := c.MustGet("user_role").(string)
 
 	//  Authorization check before calling service
-	if currentUserRole != "admin" && currentUserID != userID {
+	if currentUserID != userID && !middleware.HasPermission(c, rbac.UsersManage) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		return
 	}
@@ -167,9 +316,8 @@ func (h *UserHandler) DeleteAccount(c *gin.Context) {
 	}
 
 	currentUserID := c.MustGet("user_id").(uuid.UUID)
-	currentUserRole := c.MustGet("user_role").(string)
 
-	if currentUserRole != "admin" && currentUserID != userID {
+	if currentUserID != userID && !middleware.HasPermission(c, rbac.UsersManage) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		return
 	}
@@ -182,6 +330,23 @@ func (h *UserHandler) DeleteAccount(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// RestoreUser brings back a deleted account. Admin only.
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	user, err := h.userService.RestoreUser(c.Request.Context(), userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewUserResponse(user))
+}
+
 func (h *UserHandler) PromoteUser(c *gin.Context) {
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -189,8 +354,7 @@ func (h *UserHandler) PromoteUser(c *gin.Context) {
 		return
 	}
 
-	currentUserRole := c.MustGet("user_role").(string)
-	if currentUserRole != "admin" {
+	if !middleware.HasPermission(c, rbac.RolesAssign) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		return
 	}
@@ -203,16 +367,117 @@ func (h *UserHandler) PromoteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "user promoted to admin"})
 }
 
+func (h *UserHandler) AppointJury(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	user, err := h.userService.AppointJury(c.Request.Context(), userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewUserResponse(user))
+}
+
+func (h *UserHandler) DismissJury(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	user, err := h.userService.DismissJury(c.Request.Context(), userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewUserResponse(user))
+}
+
+// ListRoles lists the roles users can be given and what each allows.
+func (h *UserHandler) ListRoles(c *gin.Context) {
+	response := make([]dtos.RoleResponse, len(rbac.Roles))
+	for i, role := range rbac.Roles {
+		response[i] = dtos.NewRoleResponse(role, rbac.Permissions(role))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AssignRole gives a user a new role, which may be a demotion.
+func (h *UserHandler) AssignRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req dtos.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currentUserID := c.MustGet("user_id").(uuid.UUID)
+	user, err := h.userService.AssignRole(c.Request.Context(), currentUserID, userID, req.Role)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewUserResponse(user))
+}
+
+// RevokeRole demotes a user to a regular user.
+func (h *UserHandler) RevokeRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	currentUserID := c.MustGet("user_id").(uuid.UUID)
+	user, err := h.userService.RevokeRole(c.Request.Context(), currentUserID, userID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewUserResponse(user))
+}
+
 func handleServiceError(c *gin.Context, err error) {
 	switch {
-	case errors.Is(err, gorm.ErrRecordNotFound):
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, services.ErrInvalidID):
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-	case errors.Is(err, services.ErrEmailExists):
+	case errors.Is(err, services.ErrInvalidRoleChange):
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-	case errors.Is(err, services.ErrInvalidCredentials):
+	case errors.Is(err, services.ErrUnknownRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEmailExists), errors.Is(err, services.ErrUsernameExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCredentials), errors.Is(err, services.ErrInvalidRefreshToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTooManyLoginAttempts):
+		var throttled *services.LoginThrottledError
+		if errors.As(err, &throttled) {
+			seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many failed login attempts"})
+	case errors.Is(err, services.ErrInvalidTwoFactorCode), errors.Is(err, services.ErrInvalidTwoFactorToken):
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTwoFactorEnabled), errors.Is(err, services.ErrTwoFactorNotEnabled),
+		errors.Is(err, services.ErrTwoFactorNotSetUp):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 	case errors.Is(err, services.ErrPasswordValidation):
 		c.JSON(http.StatusBadRequest, gin.H{"error": "password validation failed"})
+	case errors.Is(err, services.ErrInvalidVerificationToken), errors.Is(err, services.ErrInvalidResetToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 	}
@@ -8,11 +8,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/dtos"
 	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/security"
 	"github.com/nyashahama/music-awards/internal/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -31,9 +33,25 @@ func (m *MockUserService) Register(ctx context.Context, username, email, passwor
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserService) Login(ctx context.Context, email, password string) (string, error) {
+func (m *MockUserService) Login(ctx context.Context, email, password string) (*services.AuthTokens, error) {
 	args := m.Called(ctx, email, password)
-	return args.String(0), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.AuthTokens), args.Error(1)
+}
+
+func (m *MockUserService) Refresh(ctx context.Context, refreshToken string) (*services.AuthTokens, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.AuthTokens), args.Error(1)
+}
+
+func (m *MockUserService) Logout(ctx context.Context, sessionID uuid.UUID) error {
+	args := m.Called(ctx, sessionID)
+	return args.Error(0)
 }
 
 func (m *MockUserService) GetUserProfile(ctx context.Context, userID uuid.UUID) (*models.User, error) {
@@ -57,16 +75,121 @@ func (m *MockUserService) DeleteUser(ctx context.Context, userID uuid.UUID) erro
 	return args.Error(0)
 }
 
+func (m *MockUserService) RestoreUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserService) PromoteToAdmin(ctx context.Context, userID uuid.UUID) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
 
+func (m *MockUserService) AppointJury(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) DismissJury(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) AssignRole(ctx context.Context, actorID, userID uuid.UUID, role string) (*models.User, error) {
+	args := m.Called(ctx, actorID, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) RevokeRole(ctx context.Context, actorID, userID uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, actorID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserService) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]models.User), args.Error(1)
 }
 
+func (m *MockUserService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) ResendVerification(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ForgotPassword(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ResetPassword(ctx context.Context, token, password string) error {
+	args := m.Called(ctx, token, password)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ValidateSession(ctx context.Context, claims *security.JWTClaims) error {
+	args := m.Called(ctx, claims)
+	return args.Error(0)
+}
+
+func (m *MockUserService) SetupTwoFactor(ctx context.Context, userID uuid.UUID) (*services.TwoFactorSetup, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TwoFactorSetup), args.Error(1)
+}
+
+func (m *MockUserService) EnableTwoFactor(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserService) DisableTwoFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	args := m.Called(ctx, userID, code)
+	return args.Error(0)
+}
+
+func (m *MockUserService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserService) CompleteTwoFactorLogin(ctx context.Context, token, code string) (*services.AuthTokens, error) {
+	args := m.Called(ctx, token, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.AuthTokens), args.Error(1)
+}
+
 // Test helper functions
 func setupHandlerTest() (*MockUserService, *UserHandler, *gin.Engine) {
 	gin.SetMode(gin.TestMode)
@@ -151,6 +274,129 @@ func TestUserHandler_Register(t *testing.T) {
 	}
 }
 
+func TestUserHandler_RefreshToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		payload        any
+		mockSetup      func(*MockUserService)
+		expectedStatus int
+	}{
+		{
+			name:    "successful refresh",
+			payload: dtos.RefreshTokenRequest{RefreshToken: "old"},
+			mockSetup: func(m *MockUserService) {
+				m.On("Refresh", mock.Anything, "old").Return(&services.AuthTokens{AccessToken: "access", RefreshToken: "new"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:    "revoked refresh token",
+			payload: dtos.RefreshTokenRequest{RefreshToken: "old"},
+			mockSetup: func(m *MockUserService) {
+				m.On("Refresh", mock.Anything, "old").Return(nil, services.ErrInvalidRefreshToken)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing refresh token",
+			payload:        map[string]any{},
+			mockSetup:      func(m *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService, handler, router := setupHandlerTest()
+			router.POST("/auth/token/refresh", handler.RefreshToken)
+			tt.mockSetup(mockService)
+
+			body, _ := json.Marshal(tt.payload)
+			req, _ := http.NewRequest(http.MethodPost, "/auth/token/refresh", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserHandler_Logout(t *testing.T) {
+	mockService, handler, router := setupHandlerTest()
+	sessionID := uuid.New()
+	router.Use(func(c *gin.Context) { c.Set("session_id", sessionID) })
+	router.POST("/auth/logout", handler.Logout)
+	mockService.On("Logout", mock.Anything, sessionID).Return(nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/auth/logout", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_ResetPassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		payload        any
+		mockSetup      func(*MockUserService)
+		expectedStatus int
+	}{
+		{
+			name:    "successful reset",
+			payload: dtos.ResetPasswordRequest{Token: "abc", Password: "NewPass123!"},
+			mockSetup: func(m *MockUserService) {
+				m.On("ResetPassword", mock.Anything, "abc", "NewPass123!").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing token",
+			payload:        map[string]any{"password": "NewPass123!"},
+			mockSetup:      func(m *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "expired token",
+			payload: dtos.ResetPasswordRequest{Token: "abc", Password: "NewPass123!"},
+			mockSetup: func(m *MockUserService) {
+				m.On("ResetPassword", mock.Anything, "abc", "NewPass123!").Return(services.ErrInvalidResetToken)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "weak password",
+			payload: dtos.ResetPasswordRequest{Token: "abc", Password: "weak"},
+			mockSetup: func(m *MockUserService) {
+				m.On("ResetPassword", mock.Anything, "abc", "weak").Return(services.ErrPasswordValidation)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService, handler, router := setupHandlerTest()
+			router.POST("/auth/reset-password", handler.ResetPassword)
+			tt.mockSetup(mockService)
+
+			body, _ := json.Marshal(tt.payload)
+			req, _ := http.NewRequest(http.MethodPost, "/auth/reset-password", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestUserHandler_Login(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -165,7 +411,7 @@ func TestUserHandler_Login(t *testing.T) {
 				Password: "password",
 			},
 			mockSetup: func(m *MockUserService) {
-				m.On("Login", mock.Anything, "test@example.com", "password").Return("valid_token", nil)
+				m.On("Login", mock.Anything, "test@example.com", "password").Return(&services.AuthTokens{AccessToken: "valid_token", RefreshToken: "refresh"}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -176,7 +422,7 @@ func TestUserHandler_Login(t *testing.T) {
 				Password: "wrongpassword",
 			},
 			mockSetup: func(m *MockUserService) {
-				m.On("Login", mock.Anything, "test@example.com", "wrongpassword").Return("", services.ErrInvalidCredentials)
+				m.On("Login", mock.Anything, "test@example.com", "wrongpassword").Return(nil, services.ErrInvalidCredentials)
 			},
 			expectedStatus: http.StatusUnauthorized,
 		},
@@ -210,6 +456,58 @@ func TestUserHandler_Login(t *testing.T) {
 	}
 }
 
+func TestUserHandler_LoginThrottled(t *testing.T) {
+	mockService, handler, router := setupHandlerTest()
+	router.POST("/auth/login", handler.Login)
+	mockService.On("Login", mock.Anything, "test@example.com", "password").
+		Return(nil, &services.LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+
+	body, _ := json.Marshal(dtos.LoginRequest{Email: "test@example.com", Password: "password"})
+	req, _ := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "2", resp.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"too many failed login attempts"}`, resp.Body.String())
+}
+
+func TestUserHandler_TwoFactorLogin(t *testing.T) {
+	mockService, handler, router := setupHandlerTest()
+	router.POST("/auth/login", handler.Login)
+	router.POST("/auth/login/2fa", handler.CompleteTwoFactorLogin)
+	mockService.On("Login", mock.Anything, "admin@example.com", "password").
+		Return(&services.AuthTokens{TwoFactorToken: "challenge", ExpiresIn: 5 * time.Minute}, nil)
+	mockService.On("CompleteTwoFactorLogin", mock.Anything, "challenge", "123456").
+		Return(&services.AuthTokens{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 15 * time.Minute}, nil)
+	mockService.On("CompleteTwoFactorLogin", mock.Anything, "challenge", "000000").
+		Return(nil, services.ErrInvalidTwoFactorCode)
+
+	post := func(path string, payload any) (*httptest.ResponseRecorder, map[string]any) {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		var got map[string]any
+		_ = json.Unmarshal(resp.Body.Bytes(), &got)
+		return resp, got
+	}
+
+	resp, got := post("/auth/login", dtos.LoginRequest{Email: "admin@example.com", Password: "password"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, map[string]any{"two_factor_required": true, "two_factor_token": "challenge", "expires_in": float64(300)}, got)
+
+	resp, _ = post("/auth/login/2fa", dtos.TwoFactorLoginRequest{TwoFactorToken: "challenge", Code: "000000"})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+	resp, got = post("/auth/login/2fa", dtos.TwoFactorLoginRequest{TwoFactorToken: "challenge", Code: "123456"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, map[string]any{"token": "access", "refresh_token": "refresh", "expires_in": float64(900)}, got)
+	mockService.AssertExpectations(t)
+}
+
 func TestUserHandler_ListAllUsers(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -226,6 +524,14 @@ func TestUserHandler_ListAllUsers(t *testing.T) {
 			},
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:         "moderator can list all users",
+			authUserRole: "moderator",
+			mockSetup: func(m *MockUserService) {
+				m.On("GetAllUsers", mock.Anything).Return([]models.User{*createTestUser()}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
 		{
 			name:           "non-admin cannot list all users",
 			authUserRole:   "user",
@@ -588,6 +894,46 @@ func TestUserHandler_PromoteUser(t *testing.T) {
 	}
 }
 
+func TestUserHandler_AssignRole(t *testing.T) {
+	adminID, userID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name           string
+		pathUserID     uuid.UUID
+		role           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"assigns role", userID, "moderator", nil, http.StatusOK},
+		{"unknown role", userID, "superuser", services.ErrUnknownRole, http.StatusBadRequest},
+		{"own role", adminID, "user", services.ErrInvalidRoleChange, http.StatusConflict},
+		{"user not found", userID, "jury", services.ErrInvalidID, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService, handler, router := setupHandlerTest()
+			setupAuthContext(router, adminID, "admin")
+			router.PUT("/users/:id/role", handler.AssignRole)
+			user := createTestUser()
+			user.Role = tt.role
+			if tt.serviceErr != nil {
+				user = nil
+			}
+			mockService.On("AssignRole", mock.Anything, adminID, tt.pathUserID, tt.role).Return(user, tt.serviceErr)
+
+			body, _ := json.Marshal(dtos.AssignRoleRequest{Role: tt.role})
+			req, _ := http.NewRequest(http.MethodPut, "/users/"+tt.pathUserID.String()+"/role", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 // Helper function for pointers
 func ptr(s string) *string {
 	return &s
@@ -1,42 +1,56 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/dtos"
 	"github.com/nyashahama/music-awards/internal/middleware"
+	"github.com/nyashahama/music-awards/internal/rbac"
 	"github.com/nyashahama/music-awards/internal/services"
 	"gorm.io/gorm"
 )
 
 type VoteHandler struct {
-	voteService services.VotingMechanismService
+	voteService    services.VotingMechanismService
+	receiptService services.ReceiptService
 }
 
-func NewVoteHandler(voteService services.VotingMechanismService) *VoteHandler {
-	return &VoteHandler{voteService: voteService}
+func NewVoteHandler(voteService services.VotingMechanismService, receiptService services.ReceiptService) *VoteHandler {
+	return &VoteHandler{voteService: voteService, receiptService: receiptService}
 }
 
-func (h *VoteHandler) RegisterRoutes(r *gin.Engine) {
+func (h *VoteHandler) RegisterRoutes(r *gin.Engine, authenticate gin.HandlerFunc) {
 	votes := r.Group("/votes")
-	votes.Use(middleware.AuthMiddleware())
+	votes.Use(authenticate)
 	{
 		votes.POST("", h.CastVote)
+		votes.POST("/ballot", h.CastBallot)
 		votes.GET("", h.GetUserVotes)
 		votes.GET("/available", h.GetAvailableVotes)
 		votes.PUT("/:id", h.ChangeVote)
+		votes.PUT("/:id/ballot", h.ChangeBallot)
+		votes.GET("/:id/history", h.GetVoteHistory)
 		votes.DELETE("/:id", h.DeleteVote)
 	}
 
-	admin := votes.Group("")
-	admin.Use(middleware.AdminMiddleware())
+	readers := votes.Group("")
+	readers.Use(middleware.RequirePermission(rbac.VotesRead))
 	{
-		admin.GET("/category/:category_id", h.GetCategoryVotes)
-		admin.GET("/all", h.GetAllVotes)
+		readers.GET("/category/:category_id", h.GetCategoryVotes)
+		readers.GET("/all", h.GetAllVotes)
+	}
+
+	moderators := votes.Group("")
+	moderators.Use(middleware.RequirePermission(rbac.VotesModerate))
+	{
+		moderators.POST("/:id/undelete", h.UndeleteVote)
 	}
 }
 
@@ -60,7 +74,29 @@ func (h *VoteHandler) CastVote(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, dtos.NewUserVotesResponse(vote))
+	response := dtos.NewUserVotesResponse(vote)
+	response.Receipt = h.receiptService.Issue(vote)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *VoteHandler) CastBallot(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var req dtos.CastBallotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	vote, err := h.voteService.CastBallot(c.Request.Context(), userID, req.CategoryID, req.Rankings)
+	if err != nil {
+		handleVoteServiceError(c, err)
+		return
+	}
+
+	response := dtos.NewVoteResponse(vote)
+	response.Receipt = h.receiptService.Issue(vote)
+	c.JSON(http.StatusCreated, response)
 }
 
 func (h *VoteHandler) GetUserVotes(c *gin.Context) {
@@ -99,7 +135,6 @@ func (h *VoteHandler) ChangeVote(c *gin.Context) {
 	}
 
 	userID := c.MustGet("user_id").(uuid.UUID)
-	currentUserRole := c.MustGet("user_role").(string)
 
 	// Get vote to check ownership
 	vote, err := h.voteService.GetVote(c.Request.Context(), voteID)
@@ -108,7 +143,7 @@ func (h *VoteHandler) ChangeVote(c *gin.Context) {
 		return
 	}
 
-	if currentUserRole != "admin" && vote.UserID != userID {
+	if vote.UserID != userID && !middleware.HasPermission(c, rbac.VotesModerate) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		return
 	}
@@ -121,13 +156,94 @@ func (h *VoteHandler) ChangeVote(c *gin.Context) {
 		return
 	}
 
-	updatedVote, err := h.voteService.ChangeVote(c.Request.Context(), voteID, req.NomineeID)
+	updatedVote, err := h.voteService.ChangeVote(c.Request.Context(), voteID, userID, req.NomineeID)
+	if err != nil {
+		handleVoteServiceError(c, err)
+		return
+	}
+
+	response := dtos.NewVoteResponse(updatedVote)
+	response.Receipt = h.receiptService.Issue(updatedVote)
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *VoteHandler) ChangeBallot(c *gin.Context) {
+	voteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vote ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	// Get vote to check ownership
+	vote, err := h.voteService.GetVote(c.Request.Context(), voteID)
+	if err != nil {
+		handleVoteServiceError(c, err)
+		return
+	}
+
+	if vote.UserID != userID && !middleware.HasPermission(c, rbac.VotesModerate) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	var req dtos.ChangeBallotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedVote, err := h.voteService.ChangeBallot(c.Request.Context(), voteID, userID, req.Rankings)
+	if err != nil {
+		handleVoteServiceError(c, err)
+		return
+	}
+
+	response := dtos.NewVoteResponse(updatedVote)
+	response.Receipt = h.receiptService.Issue(updatedVote)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetVoteHistory lists the changes made to a vote. Only the vote owner or
+// staff who can read votes may see it.
+func (h *VoteHandler) GetVoteHistory(c *gin.Context) {
+	voteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vote ID"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	vote, err := h.voteService.GetVote(c.Request.Context(), voteID)
+	if err != nil {
+		handleVoteServiceError(c, err)
+		return
+	}
+
+	if vote.UserID != userID && !middleware.HasPermission(c, rbac.VotesRead) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	changes, err := h.voteService.GetVoteHistory(c.Request.Context(), voteID)
 	if err != nil {
 		handleVoteServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dtos.NewVoteResponse(updatedVote))
+	c.JSON(http.StatusOK, dtos.NewVoteHistoryResponse(vote, changes))
+}
+
+// Snapshot returns the vote's metadata for the audit log, leaving out the
+// nominees it was cast for.
+func (h *VoteHandler) Snapshot(ctx context.Context, id uuid.UUID) (any, error) {
+	vote, err := h.voteService.GetVote(ctx, id)
+	if err != nil || vote == nil {
+		return nil, err
+	}
+	return dtos.NewVoteMetadataResponse(vote), nil
 }
 
 func (h *VoteHandler) DeleteVote(c *gin.Context) {
@@ -138,7 +254,6 @@ func (h *VoteHandler) DeleteVote(c *gin.Context) {
 	}
 
 	userID := c.MustGet("user_id").(uuid.UUID)
-	currentUserRole := c.MustGet("user_role").(string)
 
 	// Get vote to check ownership
 	vote, err := h.voteService.GetVote(c.Request.Context(), voteID)
@@ -147,8 +262,8 @@ func (h *VoteHandler) DeleteVote(c *gin.Context) {
 		return
 	}
 
-	// Authorization: Only vote owner or admin can delete
-	if currentUserRole != "admin" && vote.UserID != userID {
+	// Authorization: Only vote owner or a moderator can delete
+	if vote.UserID != userID && !middleware.HasPermission(c, rbac.VotesModerate) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		return
 	}
@@ -161,6 +276,23 @@ func (h *VoteHandler) DeleteVote(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// UndeleteVote restores a vote its voter deleted. Moderators only.
+func (h *VoteHandler) UndeleteVote(c *gin.Context) {
+	voteID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vote ID"})
+		return
+	}
+
+	vote, err := h.voteService.UndeleteVote(c.Request.Context(), voteID)
+	if err != nil {
+		handleVoteServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewVoteResponse(vote))
+}
+
 func (h *VoteHandler) GetCategoryVotes(c *gin.Context) {
 	categoryID, err := uuid.Parse(c.Param("category_id"))
 	if err != nil {
@@ -199,10 +331,34 @@ func handleVoteServiceError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, services.ErrNoVotesAvailable):
 		c.JSON(http.StatusBadRequest, gin.H{"error": "no votes available"})
+	case errors.Is(err, services.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 	case errors.Is(err, services.ErrAlreadyVotedInCategory):
 		c.JSON(http.StatusConflict, gin.H{"error": "already voted in this category"})
+	case errors.Is(err, services.ErrRankedBallotRequired), errors.Is(err, services.ErrNotRankedChoice),
+		errors.Is(err, services.ErrInvalidBallot):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNomineeNotInCategory):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "nominee is not in this category"})
+	case errors.Is(err, services.ErrVoteLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": "vote is under fraud review"})
+	case errors.Is(err, services.ErrVoteChangeLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": "vote change limit reached"})
+	case errors.Is(err, services.ErrVoteChangeCooldown):
+		var cooldown *services.VoteChangeCooldownError
+		if errors.As(err, &cooldown) {
+			seconds := int(math.Ceil(cooldown.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "vote was changed too recently"})
 	case errors.Is(err, services.ErrVotingPeriodClosed):
 		c.JSON(http.StatusForbidden, gin.H{"error": "voting period is closed"})
+	case errors.Is(err, services.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+	case errors.Is(err, services.ErrResultsHidden):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 	case errors.Is(err, gorm.ErrRecordNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": "vote not found"})
 	default:
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/dtos"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/services"
+)
+
+type VoteAllocationHandler struct {
+	allocationService services.VoteAllocationService
+}
+
+func NewVoteAllocationHandler(allocationService services.VoteAllocationService) *VoteAllocationHandler {
+	return &VoteAllocationHandler{allocationService: allocationService}
+}
+
+func (h *VoteAllocationHandler) SetVotePolicy(c *gin.Context) {
+	editionID, err := uuid.Parse(c.Param("editionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid edition ID"})
+		return
+	}
+
+	var req dtos.VotePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	edition, err := h.allocationService.SetPolicy(c.Request.Context(), editionID, req.Policy, *req.Budget, req.RoleBudgets)
+	if err != nil {
+		handleVoteAllocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewEditionResponse(edition))
+}
+
+func (h *VoteAllocationHandler) ResetVoteBudgets(c *gin.Context) {
+	editionID, err := uuid.Parse(c.Param("editionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid edition ID"})
+		return
+	}
+
+	updated, err := h.allocationService.ResetBudgets(c.Request.Context(), editionID)
+	if err != nil {
+		handleVoteAllocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users_updated": updated})
+}
+
+func (h *VoteAllocationHandler) GrantVotes(c *gin.Context) {
+	h.adjustVotes(c, h.allocationService.GrantVotes)
+}
+
+func (h *VoteAllocationHandler) RevokeVotes(c *gin.Context) {
+	h.adjustVotes(c, h.allocationService.RevokeVotes)
+}
+
+func (h *VoteAllocationHandler) adjustVotes(c *gin.Context, adjust func(ctx context.Context, userID uuid.UUID, count int) (*models.User, error)) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req dtos.AdjustVotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := adjust(c.Request.Context(), userID, req.Votes)
+	if err != nil {
+		handleVoteAllocationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.NewUserResponse(user))
+}
+
+func handleVoteAllocationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidVotePolicy), errors.Is(err, services.ErrInvalidVoteCount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPolicyNotBudgeted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEditionNotFound), errors.Is(err, services.ErrNoActiveEdition),
+		errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/fingerprint"
+	"github.com/nyashahama/music-awards/internal/models"
+)
+
+// maxAuditedResponse caps how much of a response is kept to audit a create
+const maxAuditedResponse = 64 << 10
+
+// AdminActionRecorder stores the audit log of staff actions.
+// services.AuditService implements it.
+type AdminActionRecorder interface {
+	RecordAdminAction(ctx context.Context, action *models.AdminAction) error
+}
+
+// Snapshot returns the resource with id as the API shows it. An error is
+// taken to mean there is no such resource.
+type Snapshot func(ctx context.Context, id uuid.UUID) (any, error)
+
+// AuditAdminActions records every request to the routes it guards with the
+// acting user and the client's fingerprint. It must run after
+// AuthMiddleware, RequestFingerprint and RequirePermission, so that requests
+// the user is not allowed to make neither read the target nor add to the log.
+//
+// The target of a route is the first resource followed by an ID parameter,
+// such as the category in /api/categories/:categoryId/restore. When a change
+// succeeds, the target is snapshotted before and after with the Snapshot for
+// its resource in snapshots; a POST to the resource itself, which creates
+// one, is recorded from the response instead.
+func AuditAdminActions(recorder AdminActionRecorder, snapshots map[string]Snapshot) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		targetType, idParam, create := auditTarget(route, c.Request.Method)
+		targetID := c.Param(idParam)
+		changes := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+
+		snapshot := snapshots[targetType]
+		var before json.RawMessage
+		if changes && snapshot != nil {
+			before = takeSnapshot(c.Request.Context(), snapshot, targetID)
+		}
+
+		var response *responseRecorder
+		if create {
+			response = &responseRecorder{ResponseWriter: c.Writer}
+			c.Writer = response
+		}
+
+		c.Next()
+
+		action := &models.AdminAction{
+			ActorRole:  c.GetString("user_role"),
+			Action:     c.Request.Method + " " + route,
+			TargetType: targetType,
+			TargetID:   targetID,
+			StatusCode: c.Writer.Status(),
+		}
+		if actorID, ok := c.Get("user_id"); ok {
+			action.ActorID, _ = actorID.(uuid.UUID)
+		}
+		if fp, ok := fingerprint.FromContext(c.Request.Context()); ok {
+			action.IPAddress, action.UserAgent, action.RequestID = fp.IP, fp.UserAgent, fp.RequestID
+		}
+
+		if changes && action.StatusCode < http.StatusBadRequest {
+			var after json.RawMessage
+			switch {
+			case create:
+				after = response.object()
+				action.TargetID = createdID(after, targetType)
+			case snapshot != nil:
+				after = takeSnapshot(c.Request.Context(), snapshot, targetID)
+			}
+			action.Before, action.After = before, after
+			action.Diff = diffSnapshots(before, after)
+		}
+
+		if err := recorder.RecordAdminAction(c.Request.Context(), action); err != nil {
+			log.Printf("audit of %s failed: %v", action.Action, err)
+		}
+	}
+}
+
+// auditTarget returns the resource a route acts on and the parameter naming
+// it, if any. create is whether the request is a POST to the resource itself.
+func auditTarget(route, method string) (resource, idParam string, create bool) {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	// Staff routes are grouped under /api, which names no resource
+	if len(segments) > 1 && segments[0] == "api" {
+		segments = segments[1:]
+	}
+	for i := 0; i+1 < len(segments); i++ {
+		if !strings.HasPrefix(segments[i], ":") && strings.HasPrefix(segments[i+1], ":") {
+			return segments[i], segments[i+1][1:], false
+		}
+	}
+	return segments[0], "", method == http.MethodPost && len(segments) == 1
+}
+
+func takeSnapshot(ctx context.Context, snapshot Snapshot, rawID string) json.RawMessage {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil
+	}
+	resource, err := snapshot(ctx, id)
+	if err != nil || resource == nil {
+		return nil
+	}
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// createdID returns the ID field of a created resource, such as category_id
+// for a category.
+func createdID(resource json.RawMessage, resourceType string) string {
+	var fields map[string]any
+	if json.Unmarshal(resource, &fields) != nil {
+		return ""
+	}
+	name := strings.TrimSuffix(resourceType, "s")
+	if strings.HasSuffix(resourceType, "ies") {
+		name = strings.TrimSuffix(resourceType, "ies") + "y"
+	}
+	id, _ := fields[name+"_id"].(string)
+	return id
+}
+
+// diffSnapshots returns the top-level fields that differ between before and
+// after, each as {"before": ..., "after": ...}. A missing snapshot counts as
+// having no fields.
+func diffSnapshots(before, after json.RawMessage) json.RawMessage {
+	var old, updated map[string]any
+	if len(before) > 0 && json.Unmarshal(before, &old) != nil {
+		return nil
+	}
+	if len(after) > 0 && json.Unmarshal(after, &updated) != nil {
+		return nil
+	}
+
+	type change struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	}
+	diff := make(map[string]change)
+	for field, value := range old {
+		if !reflect.DeepEqual(value, updated[field]) {
+			diff[field] = change{Before: value, After: updated[field]}
+		}
+	}
+	for field, value := range updated {
+		if _, ok := old[field]; !ok {
+			diff[field] = change{After: value}
+		}
+	}
+	if len(diff) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// responseRecorder keeps a copy of the start of the response body.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseRecorder) keep(data []byte) {
+	if room := maxAuditedResponse - w.body.Len(); room > 0 {
+		w.body.Write(data[:min(len(data), room)])
+	}
+}
+
+// object returns the recorded body if it is a whole JSON object.
+func (w *responseRecorder) object() json.RawMessage {
+	body := bytes.TrimSpace(w.body.Bytes())
+	if !json.Valid(body) || !bytes.HasPrefix(body, []byte("{")) {
+		return nil
+	}
+	return json.RawMessage(body)
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type adminActionLog []*models.AdminAction
+
+func (l *adminActionLog) RecordAdminAction(ctx context.Context, action *models.AdminAction) error {
+	*l = append(*l, action)
+	return nil
+}
+
+type auditedCategory struct {
+	CategoryID string `json:"category_id"`
+	Name       string `json:"name"`
+	Active     bool   `json:"is_active"`
+}
+
+func setupAdminAuditTest(role string) (*gin.Engine, *adminActionLog, map[uuid.UUID]*auditedCategory, *int) {
+	actions := new(adminActionLog)
+	categories := make(map[uuid.UUID]*auditedCategory)
+	snapshotsTaken := new(int)
+	snapshots := map[string]Snapshot{
+		"categories": func(ctx context.Context, id uuid.UUID) (any, error) {
+			*snapshotsTaken++
+			category, ok := categories[id]
+			if !ok {
+				return nil, errors.New("category not found")
+			}
+			return *category, nil
+		},
+	}
+
+	router := gin.New()
+	router.Use(RequestFingerprint(), func(c *gin.Context) {
+		c.Set("user_id", uuid.MustParse("00000000-0000-0000-0000-00000000000a"))
+		c.Set("user_role", role)
+	})
+	audit := AuditAdminActions(actions, snapshots)
+	editors := router.Group("/api", RequirePermission("categories:write"), audit)
+	editors.POST("/categories", func(c *gin.Context) {
+		id := uuid.New()
+		categories[id] = &auditedCategory{CategoryID: id.String(), Name: "Best Album"}
+		c.JSON(http.StatusCreated, categories[id])
+	})
+	editors.PUT("/categories/:categoryId", func(c *gin.Context) {
+		category := categories[uuid.MustParse(c.Param("categoryId"))]
+		category.Name, category.Active = "Album of the Year", true
+		c.JSON(http.StatusOK, category)
+	})
+	editors.DELETE("/categories/:categoryId", func(c *gin.Context) {
+		delete(categories, uuid.MustParse(c.Param("categoryId")))
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/api/results/tallies", audit, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	return router, actions, categories, snapshotsTaken
+}
+
+func serveAudited(router *gin.Engine, method, path string) {
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = "203.0.113.7:1234"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestAuditAdminActions_RecordsChanges(t *testing.T) {
+	router, actions, categories, _ := setupAdminAuditTest("admin")
+
+	serveAudited(router, http.MethodPost, "/api/categories")
+	require.Len(t, *actions, 1)
+	created := (*actions)[0]
+	assert.Equal(t, "POST /api/categories", created.Action)
+	assert.Equal(t, "categories", created.TargetType)
+	id, err := uuid.Parse(created.TargetID)
+	require.NoError(t, err)
+	require.Contains(t, categories, id)
+	assert.Equal(t, http.StatusCreated, created.StatusCode)
+	assert.Equal(t, "00000000-0000-0000-0000-00000000000a", created.ActorID.String())
+	assert.Equal(t, "admin", created.ActorRole)
+	assert.Equal(t, "203.0.113.7", created.IPAddress)
+	assert.NotEmpty(t, created.RequestID)
+	assert.Nil(t, created.Before)
+	assert.JSONEq(t, `{"category_id":"`+id.String()+`","name":"Best Album","is_active":false}`, string(created.After))
+
+	serveAudited(router, http.MethodPut, "/api/categories/"+id.String())
+	updated := (*actions)[1]
+	assert.Equal(t, "PUT /api/categories/:categoryId", updated.Action)
+	assert.Equal(t, id.String(), updated.TargetID)
+	assert.JSONEq(t, `{"category_id":"`+id.String()+`","name":"Best Album","is_active":false}`, string(updated.Before))
+	assert.JSONEq(t, `{
+		"name": {"before": "Best Album", "after": "Album of the Year"},
+		"is_active": {"before": false, "after": true}
+	}`, string(updated.Diff))
+
+	serveAudited(router, http.MethodDelete, "/api/categories/"+id.String())
+	deleted := (*actions)[2]
+	assert.Nil(t, deleted.After)
+	var diff map[string]map[string]any
+	require.NoError(t, json.Unmarshal(deleted.Diff, &diff))
+	assert.Equal(t, map[string]any{"before": "Album of the Year", "after": nil}, diff["name"])
+}
+
+func TestAuditAdminActions_SkipsDeniedRecordsReads(t *testing.T) {
+	router, actions, categories, snapshotsTaken := setupAdminAuditTest("moderator")
+	id := uuid.New()
+	categories[id] = &auditedCategory{CategoryID: id.String(), Name: "Best Album"}
+
+	serveAudited(router, http.MethodPut, "/api/categories/"+id.String())
+	serveAudited(router, http.MethodGet, "/api/results/tallies")
+
+	assert.Zero(t, *snapshotsTaken, "a denied request must not read the target")
+	assert.Equal(t, "Best Album", categories[id].Name)
+
+	require.Len(t, *actions, 1)
+	read := (*actions)[0]
+	assert.Equal(t, "GET /api/results/tallies", read.Action)
+	assert.Equal(t, "results", read.TargetType)
+	assert.Empty(t, read.TargetID)
+}
+
+func TestAuditTarget(t *testing.T) {
+	tests := []struct {
+		route, method   string
+		resource, param string
+		create          bool
+	}{
+		{"/api/categories", http.MethodPost, "categories", "", true},
+		{"/api/categories/:categoryId/restore", http.MethodPost, "categories", "categoryId", false},
+		{"/api/nominees/:id/categories/:categoryId", http.MethodDelete, "nominees", "id", false},
+		{"/api/votes/scan", http.MethodPost, "votes", "", false},
+		{"/api/results/categories/:categoryId/preview", http.MethodGet, "categories", "categoryId", false},
+	}
+
+	for _, tt := range tests {
+		resource, param, create := auditTarget(tt.route, tt.method)
+		assert.Equal(t, tt.resource, resource, tt.route)
+		assert.Equal(t, tt.param, param, tt.route)
+		assert.Equal(t, tt.create, create, tt.route)
+	}
+}
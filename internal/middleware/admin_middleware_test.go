@@ -1,43 +0,0 @@
-package middleware
-
-import (
-	"net/http"
-	"net/http/httptest"
-	"testing"
-
-	"github.com/gin-gonic/gin"
-	"github.com/stretchr/testify/assert"
-)
-
-func TestAdminMiddleware(t *testing.T) {
-	tests := []struct {
-		name     string
-		role     string
-		wantCode int
-	}{
-		{"Admin access", "admin", http.StatusOK},
-		{"User access", "user", http.StatusForbidden},
-		{"No role", "", http.StatusForbidden},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			router := gin.New()
-			router.Use(func(c *gin.Context) {
-				if tt.role != "" {
-					c.Set("user_role", tt.role)
-				}
-			})
-			router.Use(AdminMiddleware())
-			router.GET("/test", func(c *gin.Context) {
-				c.Status(http.StatusOK)
-			})
-
-			w := httptest.NewRecorder()
-			req, _ := http.NewRequest("GET", "/test", nil)
-			router.ServeHTTP(w, req)
-
-			assert.Equal(t, tt.wantCode, w.Code)
-		})
-	}
-}
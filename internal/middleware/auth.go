@@ -2,6 +2,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -10,7 +12,20 @@ import (
 	"github.com/nyashahama/music-awards/internal/security"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// SessionValidator is called with the claims of every valid token and
+// rejects tokens whose session has been revoked with
+// security.ErrSessionRevoked. services.UserService implements it.
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, claims *security.JWTClaims) error
+}
+
+// AuthMiddleware authenticates requests by their bearer token, checking its
+// session with sessions. It panics if sessions is nil, since the role claim
+// of a revoked token must never be trusted.
+func AuthMiddleware(sessions SessionValidator) gin.HandlerFunc {
+	if sessions == nil {
+		panic("middleware: AuthMiddleware needs a SessionValidator")
+	}
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -36,10 +51,22 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if err := sessions.ValidateSession(c.Request.Context(), claims); errors.Is(err, security.ErrSessionRevoked) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		} else if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
 		c.Set("user_id", userID)
 		c.Set("username", claims.Username)
 		c.Set("user_role", claims.Role)
 		c.Set("email", claims.Email)
+		c.Set("two_factor", claims.HasTwoFactor())
+		if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+			c.Set("session_id", sessionID)
+		}
 		c.Next()
 	}
 }
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,15 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// sessionCheck is a SessionValidator backed by a function
+type sessionCheck func(ctx context.Context, claims *security.JWTClaims) error
+
+func (f sessionCheck) ValidateSession(ctx context.Context, claims *security.JWTClaims) error {
+	return f(ctx, claims)
+}
+
+var currentSession = sessionCheck(func(ctx context.Context, claims *security.JWTClaims) error { return nil })
+
 func TestAuthMiddleware(t *testing.T) {
 	// Save original function and restore after test
 	originalValidate := security.ValidateJWT
@@ -43,7 +53,7 @@ func TestAuthMiddleware(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			router := gin.New()
-			router.Use(AuthMiddleware())
+			router.Use(AuthMiddleware(currentSession))
 			router.GET("/test", func(c *gin.Context) {
 				c.Status(http.StatusOK)
 			})
@@ -59,3 +69,47 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddleware_ValidateSession(t *testing.T) {
+	originalValidate := security.ValidateJWT
+	defer func() { security.ValidateJWT = originalValidate }()
+
+	security.ValidateJWT = func(token string) (*security.JWTClaims, error) {
+		return &security.JWTClaims{UserID: "123e4567-e89b-12d3-a456-426614174000", TokenVersion: 1}, nil
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{"Current session", nil, http.StatusOK},
+		{"Revoked session", security.ErrSessionRevoked, http.StatusUnauthorized},
+		{"Lookup failure", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := sessionCheck(func(ctx context.Context, claims *security.JWTClaims) error {
+				assert.Equal(t, 1, claims.TokenVersion)
+				return tt.err
+			})
+			router := gin.New()
+			router.Use(AuthMiddleware(sessions))
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test", nil)
+			req.Header.Set("Authorization", "Bearer valid")
+
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestAuthMiddleware_RequiresSessionValidator(t *testing.T) {
+	assert.Panics(t, func() { AuthMiddleware(nil) })
+}
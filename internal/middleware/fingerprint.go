@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/fingerprint"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+const maxUserAgentLength = 512
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestFingerprint attaches the client's IP, user agent and a request ID to
+// the request context. The IP comes from gin's ClientIP, which only honours
+// forwarding headers set by the router's trusted proxies. A well-formed
+// incoming X-Request-ID is kept so requests can be traced across services;
+// otherwise a new one is generated.
+func RequestFingerprint() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Set("request_id", requestID)
+
+		userAgent := c.Request.UserAgent()
+		if len(userAgent) > maxUserAgentLength {
+			userAgent = userAgent[:maxUserAgentLength]
+		}
+
+		ctx := fingerprint.NewContext(c.Request.Context(), fingerprint.Fingerprint{
+			IP:        c.ClientIP(),
+			UserAgent: userAgent,
+			RequestID: requestID,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nyashahama/music-awards/internal/fingerprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		requestID      string
+		userAgent      string
+		wantIP         string
+		wantRequestID  string
+	}{
+		{
+			name:          "untrusted proxy headers are ignored",
+			requestID:     "trace-123",
+			userAgent:     "Mozilla/5.0",
+			wantIP:        "10.0.0.1",
+			wantRequestID: "trace-123",
+		},
+		{
+			name:           "trusted proxy forwards the client IP",
+			trustedProxies: []string{"10.0.0.0/8"},
+			userAgent:      "Mozilla/5.0",
+			wantIP:         "203.0.113.7",
+		},
+		{
+			name:      "malformed request ID is replaced",
+			requestID: "bad id\nwith newline",
+			userAgent: strings.Repeat("a", 600),
+			wantIP:    "10.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			require.NoError(t, router.SetTrustedProxies(tt.trustedProxies))
+			router.Use(RequestFingerprint())
+
+			var got fingerprint.Fingerprint
+			router.GET("/test", func(c *gin.Context) {
+				got, _ = fingerprint.FromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test", nil)
+			req.RemoteAddr = "10.0.0.1:4321"
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			req.Header.Set("User-Agent", tt.userAgent)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantIP, got.IP)
+			assert.LessOrEqual(t, len(got.UserAgent), maxUserAgentLength)
+			assert.Equal(t, got.RequestID, w.Header().Get(RequestIDHeader))
+			if tt.wantRequestID != "" {
+				assert.Equal(t, tt.wantRequestID, got.RequestID)
+			} else {
+				assert.NotEqual(t, tt.requestID, got.RequestID)
+				assert.NotEmpty(t, got.RequestID)
+			}
+		})
+	}
+}
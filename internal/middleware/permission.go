@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nyashahama/music-awards/internal/rbac"
+)
+
+// PermissionOption configures RequirePermission.
+type PermissionOption func(*permissionOptions)
+
+type permissionOptions struct {
+	requireTwoFactor bool
+}
+
+// RequireTwoFactor rejects users whose token was issued without a second
+// factor, when require is true.
+func RequireTwoFactor(require bool) PermissionOption {
+	return func(o *permissionOptions) {
+		o.requireTwoFactor = require
+	}
+}
+
+// RequirePermission only lets through users whose role grants perm. It must
+// run after AuthMiddleware.
+func RequirePermission(perm rbac.Permission, opts ...PermissionOption) gin.HandlerFunc {
+	var options permissionOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(c *gin.Context) {
+		if !HasPermission(c, perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		if options.requireTwoFactor && !c.GetBool("two_factor") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "two-factor authentication required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasPermission reports whether the authenticated user's role grants perm,
+// for handlers that also let users act on their own resources.
+func HasPermission(c *gin.Context, perm rbac.Permission) bool {
+	return rbac.Can(c.GetString("user_role"), perm)
+}
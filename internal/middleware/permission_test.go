@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		wantCode int
+	}{
+		{"Admin access", "admin", http.StatusOK},
+		{"Role with permission", "content_editor", http.StatusOK},
+		{"Role without permission", "moderator", http.StatusForbidden},
+		{"User access", "user", http.StatusForbidden},
+		{"No role", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.role != "" {
+					c.Set("user_role", tt.role)
+				}
+			})
+			router.Use(RequirePermission("nominees:write"))
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestRequirePermission_RequireTwoFactor(t *testing.T) {
+	tests := []struct {
+		name      string
+		require   bool
+		twoFactor bool
+		wantCode  int
+	}{
+		{"Second factor not required", false, false, http.StatusOK},
+		{"Second factor given", true, true, http.StatusOK},
+		{"Second factor missing", true, false, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_role", "admin")
+				c.Set("two_factor", tt.twoFactor)
+			})
+			router.Use(RequirePermission("nominees:write", RequireTwoFactor(tt.require)))
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VoteAudit records the client that cast a vote
+type VoteAudit struct {
+	VoteID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	IPAddress string    `gorm:"not null"`
+	UserAgent string    `gorm:"not null"`
+	RequestID string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// RegistrationAudit records the client that registered an account
+type RegistrationAudit struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	IPAddress string    `gorm:"not null"`
+	UserAgent string    `gorm:"not null"`
+	RequestID string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// AdminAction records a request to a staff route. Before and After are the
+// target as its API shows it around a successful change, and Diff holds the
+// fields that changed, each as {"before": ..., "after": ...}.
+type AdminAction struct {
+	ActionID   uuid.UUID       `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ActorID    uuid.UUID       `gorm:"type:uuid;not null;index"`
+	ActorRole  string          `gorm:"not null"`
+	Action     string          `gorm:"not null"`
+	TargetType string          `gorm:"not null;default:''"`
+	TargetID   string          `gorm:"not null;default:''"`
+	StatusCode int             `gorm:"not null"`
+	Before     json.RawMessage `gorm:"type:jsonb"`
+	After      json.RawMessage `gorm:"type:jsonb"`
+	Diff       json.RawMessage `gorm:"type:jsonb"`
+	IPAddress  string          `gorm:"not null"`
+	UserAgent  string          `gorm:"not null"`
+	RequestID  string          `gorm:"not null"`
+	CreatedAt  time.Time       `gorm:"autoCreateTime;index"`
+}
@@ -4,15 +4,88 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Results publication states. Hidden results are visible to nobody, preview
+// results to admins only, and published results to everyone. Categories
+// start in preview.
+const (
+	ResultsHidden    = "hidden"
+	ResultsPreview   = "preview"
+	ResultsPublished = "published"
+)
+
+// Voting methods. Single categories are won by the most first choices; ranked
+// categories are decided by an instant runoff over preference ballots.
+const (
+	VotingMethodSingle = "single"
+	VotingMethodRanked = "ranked"
 )
 
 type Category struct {
-	CategoryID  uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Name        string    `gorm:"unique;not null"`
-	Description string
-	CreatedAt   time.Time `gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
-	Votes       []Vote    `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE;"`
+	CategoryID       uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	EditionID        uuid.UUID `gorm:"type:uuid;not null"`
+	Name             string    `gorm:"not null"`
+	Description      string
+	VotingOpensAt    *time.Time
+	VotingClosesAt   *time.Time
+	ResultsState     string `gorm:"not null;default:preview"`
+	ResultsPublishAt *time.Time
+	VotingMethod     string    `gorm:"not null;default:single"`
+	JuryWeight       float64   `gorm:"not null;default:0"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+	Votes            []Vote    `gorm:"foreignKey:CategoryID;constraint:OnDelete:RESTRICT;"`
+
+	// MaxVoteChanges caps how often a vote may be changed, nil meaning no
+	// limit. VoteChangeCooldownSeconds is the minimum wait between changes.
+	MaxVoteChanges            *int
+	VoteChangeCooldownSeconds int `gorm:"not null;default:0"`
+
+	// DeletedAt is set when the category is deleted. Its votes are kept so
+	// that restoring it brings them back.
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 
 	Nominees []Nominee `gorm:"many2many:nominee_categories;joinForeignKey:CategoryID;joinReferences:NomineeID;"`
 }
+
+// IsVotingOpen reports whether votes may be cast, changed or withdrawn at now.
+// A missing bound leaves the window open on that side.
+func (c *Category) IsVotingOpen(now time.Time) bool {
+	if c.VotingOpensAt != nil && now.Before(*c.VotingOpensAt) {
+		return false
+	}
+	if c.VotingClosesAt != nil && !now.Before(*c.VotingClosesAt) {
+		return false
+	}
+	return true
+}
+
+// IsRankedChoice reports whether the category takes ranked ballots.
+func (c *Category) IsRankedChoice() bool {
+	return c.VotingMethod == VotingMethodRanked
+}
+
+// IsBlended reports whether the result mixes jury and public shares. Ranked
+// categories are never blended; every ballot counts equally in the runoff.
+func (c *Category) IsBlended() bool {
+	return c.JuryWeight > 0 && !c.IsRankedChoice()
+}
+
+// VoteChangeCooldown is the minimum time between two changes of a vote.
+func (c *Category) VoteChangeCooldown() time.Duration {
+	return time.Duration(c.VoteChangeCooldownSeconds) * time.Second
+}
+
+// EffectiveResultsState returns ResultsState, promoted to ResultsPublished once
+// a scheduled publication time has passed.
+func (c *Category) EffectiveResultsState(now time.Time) string {
+	if c.ResultsState != ResultsPublished && c.ResultsPublishAt != nil && !now.Before(*c.ResultsPublishAt) {
+		return ResultsPublished
+	}
+	if c.ResultsState == "" {
+		return ResultsHidden
+	}
+	return c.ResultsState
+}
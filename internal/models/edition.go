@@ -0,0 +1,93 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Vote allocation policies. Fixed and per-role policies spend a budget held
+// in User.AvailableVotes; the others leave it untouched.
+const (
+	VotePolicyFixed       = "fixed"        // every user gets VoteBudget votes
+	VotePolicyPerRole     = "per_role"     // budget looked up by role, falling back to VoteBudget
+	VotePolicyPerCategory = "per_category" // one vote in every category, no budget
+	VotePolicyUnlimited   = "unlimited"    // no budget and no per-category limit
+
+	DefaultVoteBudget = 5
+)
+
+// Edition is one season of the awards. Categories, nominee-category links and
+// votes all belong to exactly one edition; at most one edition is active.
+type Edition struct {
+	EditionID       uuid.UUID   `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name            string      `gorm:"not null"`
+	Year            int         `gorm:"unique;not null"`
+	IsActive        bool        `gorm:"not null;default:false"`
+	VotePolicy      string      `gorm:"not null;default:fixed"`
+	VoteBudget      int         `gorm:"not null;default:5"`
+	RoleVoteBudgets RoleBudgets `gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt       time.Time   `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time   `gorm:"autoUpdateTime"`
+	Categories      []Category  `gorm:"foreignKey:EditionID"`
+}
+
+// LimitsVotes reports whether casting a vote spends the user's budget.
+func (e *Edition) LimitsVotes() bool {
+	switch e.VotePolicy {
+	case VotePolicyPerCategory, VotePolicyUnlimited:
+		return false
+	default:
+		return true
+	}
+}
+
+// OneVotePerCategory reports whether a user may hold at most one vote in
+// each category.
+func (e *Edition) OneVotePerCategory() bool {
+	return e.VotePolicy != VotePolicyUnlimited
+}
+
+// BudgetFor returns the number of votes a user with role starts the edition
+// with.
+func (e *Edition) BudgetFor(role string) int {
+	if e.VotePolicy == VotePolicyPerRole {
+		if budget, ok := e.RoleVoteBudgets[role]; ok {
+			return budget
+		}
+	}
+	return e.VoteBudget
+}
+
+// RoleBudgets maps a role to its vote budget, stored as a JSON object.
+type RoleBudgets map[string]int
+
+func (b RoleBudgets) Value() (driver.Value, error) {
+	if b == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (b *RoleBudgets) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*b = RoleBudgets{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RoleBudgets", value)
+	}
+	return json.Unmarshal(data, b)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links a user to their account at an OpenID Connect provider.
+type Identity struct {
+	IdentityID  uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	Provider    string    `gorm:"not null;uniqueIndex:idx_identities_provider_subject"`
+	Subject     string    `gorm:"not null;uniqueIndex:idx_identities_provider_subject"`
+	Email       string
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	LastLoginAt *time.Time
+}
+
+// OIDCLoginRequest is a sign-in in progress at a provider. The state sent
+// to the provider is stored hashed; the nonce and PKCE verifier never leave
+// the server.
+type OIDCLoginRequest struct {
+	StateHash    string    `gorm:"primaryKey"`
+	Provider     string    `gorm:"not null"`
+	Nonce        string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Vote ledger actions
+const (
+	LedgerActionCreate  = "create"
+	LedgerActionChange  = "change"
+	LedgerActionDelete  = "delete"
+	LedgerActionRestore = "restore"
+	// Fraud review actions
+	LedgerActionVoid      = "void"
+	LedgerActionReinstate = "reinstate"
+)
+
+// GenesisHash is the previous hash of the first ledger entry
+var GenesisHash = strings.Repeat("0", 64)
+
+// VoteLedgerEntry is one link in the hash-chained vote ledger. Payload is the
+// vote's JSON snapshot after the action, kept as text so that it hashes
+// exactly as written.
+type VoteLedgerEntry struct {
+	Sequence  int64     `gorm:"primaryKey;autoIncrement:false"`
+	Action    string    `gorm:"not null"`
+	VoteID    uuid.UUID `gorm:"type:uuid;not null"`
+	Payload   string    `gorm:"not null"`
+	PrevHash  string    `gorm:"not null"`
+	Hash      string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime:false"`
+}
+
+func (VoteLedgerEntry) TableName() string {
+	return "vote_ledger"
+}
+
+// ComputeHash returns the hex SHA-256 over the entry's contents and the
+// previous entry's hash. CreatedAt is taken at microsecond precision, as
+// stored by the database.
+func (e *VoteLedgerEntry) ComputeHash() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		e.PrevHash,
+		strconv.FormatInt(e.Sequence, 10),
+		e.Action,
+		e.VoteID.String(),
+		e.Payload,
+		strconv.FormatInt(e.CreatedAt.UnixMicro(), 10),
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Ledger problems found by verification
+const (
+	LedgerProblemGap          = "gap"
+	LedgerProblemBrokenLink   = "broken_link"
+	LedgerProblemHashMismatch = "hash_mismatch"
+)
+
+// LedgerProblem is a point where the ledger chain does not hold
+type LedgerProblem struct {
+	Sequence int64
+	Kind     string
+	Detail   string
+}
+
+// LedgerReport is the outcome of walking the vote ledger
+type LedgerReport struct {
+	Entries  int64
+	HeadHash string
+	Valid    bool
+	Problems []LedgerProblem
+}
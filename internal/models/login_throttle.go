@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// LoginThrottle counts the failed logins for one email address or client
+// IP, identified by ThrottleKey.
+type LoginThrottle struct {
+	ThrottleKey   string    `gorm:"primaryKey"`
+	Failures      int       `gorm:"not null;default:0"`
+	LastFailureAt time.Time `gorm:"not null"`
+	LockedUntil   *time.Time
+}
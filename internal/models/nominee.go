@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Nominee struct {
@@ -16,5 +17,9 @@ type Nominee struct {
 	CreatedAt   time.Time `gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
 
+	// DeletedAt is set when the nominee is deleted. Its votes are kept so
+	// that restoring it brings them back.
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
 	Categories []Category `gorm:"many2many:nominee_categories;joinForeignKey:NomineeID;joinReferences:CategoryID"`
 }
@@ -9,6 +9,7 @@ import (
 type NomineeCategory struct {
 	NomineeID  uuid.UUID `gorm:"type:uuid;primaryKey"`
 	CategoryID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	EditionID  uuid.UUID `gorm:"type:uuid;not null"`
 	CreatedAt  time.Time `gorm:"autoCreateTime"`
 	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
 
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode lets a user sign in once without their authenticator. Only
+// the code's hash is stored.
+type RecoveryCode struct {
+	RecoveryCodeID uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index"`
+	CodeHash       string    `gorm:"not null"`
+	UsedAt         *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "github.com/google/uuid"
+
+// NomineeResult is one nominee's standing within a category tally.
+// Results are computed from votes and never persisted. In blended categories
+// Percentage is the weighted share that decides the rank, and Jury and
+// Public hold each class's own count and share.
+type NomineeResult struct {
+	NomineeID   uuid.UUID
+	NomineeName string
+	Votes       int64
+	Percentage  float64
+	Rank        int
+	Winner      bool
+	Jury        VoteShare
+	Public      VoteShare
+}
+
+// VoteShare is a nominee's votes from one voter class and their percentage
+// of that class's votes in the category.
+type VoteShare struct {
+	Votes int64
+	Share float64
+}
+
+// CategoryResults is the tally of a single category, ordered by rank.
+// Tied is set when more than one nominee shares first place. For ranked
+// categories Votes counts first preferences, ranks follow the instant runoff
+// and Rounds records how it was decided.
+type CategoryResults struct {
+	CategoryID   uuid.UUID
+	CategoryName string
+	EditionID    uuid.UUID
+	VotingMethod string
+	JuryWeight   float64
+	TotalVotes   int64
+	JuryVotes    int64
+	PublicVotes  int64
+	Tied         bool
+	Nominees     []NomineeResult
+	Rounds       []RunoffRound
+}
+
+// RunoffRound is one counting round of an instant runoff. Tallies covers the
+// nominees still standing, Exhausted the ballots with none of them left, and
+// Eliminated the nominees dropped at the end of the round.
+type RunoffRound struct {
+	Round      int
+	Tallies    []RunoffTally
+	Exhausted  int64
+	Eliminated []uuid.UUID
+}
+
+// RunoffTally is a nominee's vote count in one runoff round.
+type RunoffTally struct {
+	NomineeID   uuid.UUID
+	NomineeName string
+	Votes       int64
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one login of a user. Access tokens carry its ID, and its
+// refresh token, stored hashed, is replaced each time it is used.
+type Session struct {
+	SessionID                uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID                   uuid.UUID `gorm:"type:uuid;not null;index"`
+	RefreshTokenHash         string    `gorm:"not null;unique"`
+	PreviousRefreshTokenHash *string   `gorm:"index"`
+	ExpiresAt                time.Time `gorm:"not null"`
+	LastUsedAt               *time.Time
+	RevokedAt                *time.Time
+	CreatedAt                time.Time `gorm:"autoCreateTime"`
+
+	// TwoFactor is set when the user entered a second factor to sign in
+	TwoFactor bool `gorm:"not null;default:false"`
+}
+
+// IsActive reports whether the session can still be used at now.
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
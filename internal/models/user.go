@@ -5,6 +5,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// User roles. Jury members vote like users, but their votes are counted in
+// the jury share of blended categories. The other roles are staff; what each
+// may do is set in package rbac.
+const (
+	RoleUser           = "user"
+	RoleAdmin          = "admin"
+	RoleJury           = "jury"
+	RoleModerator      = "moderator"
+	RoleContentEditor  = "content_editor"
+	RoleResultsOfficer = "results_officer"
 )
 
 type User struct {
@@ -17,4 +30,34 @@ type User struct {
 	CreatedAt      time.Time `gorm:"autoCreateTime"`
 	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
 	Votes          []Vote    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+
+	// EmailVerifiedAt is set once the user follows their verification link;
+	// unverified accounts cannot vote
+	EmailVerifiedAt *time.Time
+
+	// TokenVersion is embedded in each JWT issued to the user. Bumping it
+	// ends all of their sessions.
+	TokenVersion int `gorm:"not null;default:0"`
+
+	// TOTPSecret is the user's authenticator secret. Two-factor
+	// authentication is on once TOTPEnabledAt is set; until then the secret
+	// is waiting to be confirmed with a code. TOTPLastStep is the time step
+	// of the last code accepted.
+	TOTPSecret    *string
+	TOTPEnabledAt *time.Time
+	TOTPLastStep  int64 `gorm:"not null;default:0"`
+
+	// DeletedAt is set when the account is deleted. The account's votes are
+	// kept, and an admin can restore it.
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// IsEmailVerified reports whether the user has confirmed their email address.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsTwoFactorEnabled reports whether the user must enter a code to sign in.
+func (u *User) IsTwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil && u.TOTPSecret != nil
 }
@@ -1,19 +1,139 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// Voter classes, recorded on each vote when it is cast.
+const (
+	VoterClassPublic = "public"
+	VoterClassJury   = "jury"
+)
+
+// Vote statuses. Quarantined votes are held for review by the fraud analyzer
+// and void votes have been rejected; neither is counted in results.
+const (
+	VoteStatusValid       = "valid"
+	VoteStatusQuarantined = "quarantined"
+	VoteStatusVoid        = "void"
+)
+
+// VoterClassFor returns the class of votes cast by a user with role.
+func VoterClassFor(role string) string {
+	if role == RoleJury {
+		return VoterClassJury
+	}
+	return VoterClassPublic
+}
+
 type Vote struct {
 	VoteID     uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	UserID     uuid.UUID `gorm:"type:uuid;not null"`
+	EditionID  uuid.UUID `gorm:"type:uuid;not null"`
 	CategoryID uuid.UUID `gorm:"type:uuid;not null"`
 	NomineeID  uuid.UUID `gorm:"type:uuid;not null"`
-	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	VoterClass string    `gorm:"not null;default:public"`
+	Status     string    `gorm:"not null;default:valid"`
+	ReviewedAt *time.Time
+	ReviewedBy *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+
+	// ChangeCount and LastChangedAt track changes for the category's limits
+	ChangeCount   int `gorm:"not null;default:0"`
+	LastChangedAt *time.Time
+
+	// DeletedAt is set when the vote is withdrawn; deleted votes are not
+	// counted and can be restored by an admin
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	// Rankings holds the preference order of a ranked ballot, starting with
+	// NomineeID. It is empty for single-choice votes.
+	Rankings []BallotRanking `gorm:"foreignKey:VoteID;references:VoteID;constraint:OnDelete:CASCADE"`
+	Flags    []VoteFlag      `gorm:"foreignKey:VoteID;references:VoteID;constraint:OnDelete:CASCADE"`
 
 	User     User     `gorm:"foreignKey:UserID;references:UserID;constraint:OnDelete:CASCADE"`
-	Category Category `gorm:"foreignKey:CategoryID;references:CategoryID;constraint:OnDelete:CASCADE"`
-	Nominee  Nominee  `gorm:"foreignKey:NomineeID;references:NomineeID;constraint:OnDelete:CASCADE"`
+	Category Category `gorm:"foreignKey:CategoryID;references:CategoryID;constraint:OnDelete:RESTRICT"`
+	Nominee  Nominee  `gorm:"foreignKey:NomineeID;references:NomineeID;constraint:OnDelete:RESTRICT"`
+}
+
+// BallotRanking is one preference on a ranked ballot. Position 1 is the
+// voter's first choice.
+type BallotRanking struct {
+	VoteID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Position  int       `gorm:"primaryKey"`
+	NomineeID uuid.UUID `gorm:"type:uuid;not null"`
+}
+
+// RankedNominees returns the nominee IDs of the ballot in preference order.
+func (v *Vote) RankedNominees() []uuid.UUID {
+	nominees := make([]uuid.UUID, len(v.Rankings))
+	for i, ranking := range v.Rankings {
+		nominees[i] = ranking.NomineeID
+	}
+	return nominees
+}
+
+// IsLocked reports whether the vote is held or rejected by fraud review and
+// so can no longer be changed or withdrawn by its voter.
+func (v *Vote) IsLocked() bool {
+	return v.Status == VoteStatusQuarantined || v.Status == VoteStatusVoid
+}
+
+// VoteFlag records why the fraud analyzer quarantined a vote. A vote is
+// flagged at most once per rule.
+type VoteFlag struct {
+	FlagID    uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	VoteID    uuid.UUID `gorm:"type:uuid;not null"`
+	Rule      string    `gorm:"not null"`
+	Detail    string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// VoteChange records one change to a vote, with the pick before and after it.
+// Rankings are empty for single-choice votes.
+type VoteChange struct {
+	ChangeID          uuid.UUID   `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	VoteID            uuid.UUID   `gorm:"type:uuid;not null"`
+	ChangedBy         *uuid.UUID  `gorm:"type:uuid"`
+	PreviousNomineeID uuid.UUID   `gorm:"type:uuid;not null"`
+	NewNomineeID      uuid.UUID   `gorm:"type:uuid;not null"`
+	PreviousRankings  NomineeList `gorm:"type:jsonb;not null;default:'[]'"`
+	NewRankings       NomineeList `gorm:"type:jsonb;not null;default:'[]'"`
+	ChangedAt         time.Time   `gorm:"not null"`
+}
+
+// NomineeList is an ordered list of nominee IDs stored as a JSON array
+type NomineeList []uuid.UUID
+
+func (l NomineeList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (l *NomineeList) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = NomineeList{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into NomineeList", value)
+	}
+	return json.Unmarshal(data, l)
 }
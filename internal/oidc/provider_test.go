@@ -0,0 +1,96 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/nyashahama/music-awards/internal/oidc"
+	"github.com/nyashahama/music-awards/internal/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	server := oidctest.NewServer(t)
+	provider := oidc.NewProvider(server.Config("test"), nil)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+
+	identity := oidctest.Identity{Subject: "user-1", Email: "fan@example.com", EmailVerified: true, Name: "A Fan"}
+	code, state := server.Authorize(t, authURL, identity)
+	assert.Equal(t, "state-1", state)
+
+	claims, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, "fan@example.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, "A Fan", claims.Name)
+}
+
+func TestProvider_ExchangeRejects(t *testing.T) {
+	server := oidctest.NewServer(t)
+	ctx := context.Background()
+	identity := oidctest.Identity{Subject: "user-1"}
+
+	authorize := func(provider *oidc.Provider) string {
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallenge(verifier))
+		require.NoError(t, err)
+		code, _ := server.Authorize(t, authURL, identity)
+		return code
+	}
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		provider := oidc.NewProvider(server.Config("test"), nil)
+		_, err := provider.Exchange(ctx, authorize(provider), "another-verifier", "nonce")
+		assert.ErrorIs(t, err, oidc.ErrLoginFailed)
+	})
+
+	t.Run("reused code", func(t *testing.T) {
+		provider := oidc.NewProvider(server.Config("test"), nil)
+		code := authorize(provider)
+		_, err := provider.Exchange(ctx, code, verifier, "nonce")
+		require.NoError(t, err)
+		_, err = provider.Exchange(ctx, code, verifier, "nonce")
+		assert.ErrorIs(t, err, oidc.ErrLoginFailed)
+	})
+
+	t.Run("wrong nonce", func(t *testing.T) {
+		provider := oidc.NewProvider(server.Config("test"), nil)
+		_, err := provider.Exchange(ctx, authorize(provider), verifier, "other-nonce")
+		assert.ErrorIs(t, err, oidc.ErrLoginFailed)
+	})
+
+	t.Run("token for another client", func(t *testing.T) {
+		provider := oidc.NewProvider(server.Config("test"), nil)
+		code := authorize(provider)
+		config := server.Config("test")
+		config.ClientID = "other-client"
+		other := oidc.NewProvider(config, nil)
+		_, err := other.Exchange(ctx, code, verifier, "nonce")
+		assert.ErrorIs(t, err, oidc.ErrLoginFailed)
+	})
+
+	t.Run("issuer mismatch", func(t *testing.T) {
+		config := server.Config("test")
+		config.IssuerURL = server.URL + "/other"
+		provider := oidc.NewProvider(config, nil)
+		_, err := provider.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallenge(verifier))
+		assert.Error(t, err)
+	})
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// Example from RFC 7636, appendix B
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", oidc.CodeChallenge(verifier))
+}
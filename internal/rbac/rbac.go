@@ -0,0 +1,87 @@
+// Package rbac defines the permissions each user role grants.
+package rbac
+
+import (
+	"slices"
+
+	"github.com/nyashahama/music-awards/internal/models"
+)
+
+// Permission allows a kind of action, named "<resource>:<action>".
+type Permission string
+
+const (
+	EditionsWrite   Permission = "editions:write"
+	CategoriesWrite Permission = "categories:write"
+	NomineesWrite   Permission = "nominees:write"
+
+	// VotesRead allows seeing anyone's votes, and VotesModerate changing or
+	// deleting them
+	VotesRead     Permission = "votes:read"
+	VotesModerate Permission = "votes:moderate"
+	// VotesAllocate allows setting vote policies and granting or revoking
+	// votes
+	VotesAllocate Permission = "votes:allocate"
+	FraudReview   Permission = "fraud:review"
+	LedgerVerify  Permission = "ledger:verify"
+
+	// ResultsRead allows seeing results before they are published
+	ResultsRead    Permission = "results:read"
+	ResultsPublish Permission = "results:publish"
+
+	// UsersRead allows seeing any user's profile, and UsersManage changing,
+	// deleting or restoring it
+	UsersRead   Permission = "users:read"
+	UsersManage Permission = "users:manage"
+	RolesAssign Permission = "roles:assign"
+
+	// AuditRead allows reading the log of staff actions
+	AuditRead Permission = "audit:read"
+)
+
+// All lists every permission. Admins have them all.
+var All = []Permission{
+	EditionsWrite, CategoriesWrite, NomineesWrite,
+	VotesRead, VotesModerate, VotesAllocate, FraudReview, LedgerVerify,
+	ResultsRead, ResultsPublish,
+	UsersRead, UsersManage, RolesAssign,
+	AuditRead,
+}
+
+// roles maps each role to the permissions it grants. Users and jury members
+// only vote, so they have none.
+var roles = map[string][]Permission{
+	models.RoleUser:           nil,
+	models.RoleJury:           nil,
+	models.RoleModerator:      {VotesRead, VotesModerate, FraudReview, UsersRead},
+	models.RoleContentEditor:  {EditionsWrite, CategoriesWrite, NomineesWrite},
+	models.RoleResultsOfficer: {VotesRead, LedgerVerify, ResultsRead, ResultsPublish},
+	models.RoleAdmin:          All,
+}
+
+// Roles lists the roles users can be given.
+var Roles = []string{
+	models.RoleUser,
+	models.RoleJury,
+	models.RoleModerator,
+	models.RoleContentEditor,
+	models.RoleResultsOfficer,
+	models.RoleAdmin,
+}
+
+// IsRole reports whether role exists.
+func IsRole(role string) bool {
+	_, ok := roles[role]
+	return ok
+}
+
+// Permissions returns the permissions role grants, or nil for an unknown
+// role.
+func Permissions(role string) []Permission {
+	return slices.Clone(roles[role])
+}
+
+// Can reports whether role grants perm.
+func Can(role string, perm Permission) bool {
+	return slices.Contains(roles[role], perm)
+}
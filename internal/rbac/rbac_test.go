@@ -0,0 +1,39 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		role string
+		perm Permission
+		want bool
+	}{
+		{models.RoleAdmin, RolesAssign, true},
+		{models.RoleContentEditor, "nominees:write", true},
+		{models.RoleContentEditor, ResultsPublish, false},
+		{models.RoleModerator, VotesModerate, true},
+		{models.RoleModerator, UsersManage, false},
+		{models.RoleResultsOfficer, ResultsPublish, true},
+		{models.RoleJury, VotesRead, false},
+		{models.RoleUser, NomineesWrite, false},
+		{"superuser", NomineesWrite, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Can(tt.role, tt.perm), "%s %s", tt.role, tt.perm)
+	}
+}
+
+func TestRoles(t *testing.T) {
+	for _, role := range Roles {
+		assert.True(t, IsRole(role), role)
+	}
+	assert.Len(t, roles, len(Roles), "every role is listed")
+	assert.ElementsMatch(t, All, Permissions(models.RoleAdmin))
+	assert.False(t, IsRole(""))
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditRepository stores the client details behind votes and registrations,
+// and the log of staff actions
+type AuditRepository interface {
+	RecordVote(ctx context.Context, audit *models.VoteAudit) error
+	RecordRegistration(ctx context.Context, audit *models.RegistrationAudit) error
+	PurgeBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	RecordAdminAction(ctx context.Context, action *models.AdminAction) error
+	ListAdminActions(ctx context.Context, filter AdminActionFilter) ([]models.AdminAction, int64, error)
+}
+
+// AdminActionFilter selects admin actions. Zero fields match everything.
+type AdminActionFilter struct {
+	ActorID    uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	// From and To bound the time of the action, From inclusive and To
+	// exclusive
+	From, To time.Time
+
+	Limit, Offset int
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) RecordVote(ctx context.Context, audit *models.VoteAudit) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
+
+func (r *auditRepository) RecordRegistration(ctx context.Context, audit *models.RegistrationAudit) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
+
+// PurgeBefore deletes audit records created before cutoff and returns how
+// many were removed.
+func (r *auditRepository) PurgeBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{&models.VoteAudit{}, &models.RegistrationAudit{}} {
+			result := tx.Where("created_at < ?", cutoff).Delete(model)
+			if result.Error != nil {
+				return result.Error
+			}
+			purged += result.RowsAffected
+		}
+		return nil
+	})
+	return purged, err
+}
+
+func (r *auditRepository) RecordAdminAction(ctx context.Context, action *models.AdminAction) error {
+	return r.db.WithContext(ctx).Create(action).Error
+}
+
+// ListAdminActions returns a page of the actions matching filter, newest
+// first, and how many match in all.
+func (r *auditRepository) ListAdminActions(ctx context.Context, filter AdminActionFilter) ([]models.AdminAction, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.AdminAction{})
+	if filter.ActorID != uuid.Nil {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var actions []models.AdminAction
+	err := query.Order("created_at DESC, action_id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&actions).Error
+	return actions, total, err
+}
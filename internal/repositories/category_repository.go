@@ -12,11 +12,15 @@ import (
 type CategoryRepository interface {
 	Create(ctx context.Context, category *models.Category) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error)
-	GetByName(ctx context.Context, name string) (*models.Category, error)
+	GetByName(ctx context.Context, editionID uuid.UUID, name string) (*models.Category, error)
 	GetAll(ctx context.Context) ([]models.Category, error)
+	GetByEdition(ctx context.Context, editionID uuid.UUID) ([]models.Category, error)
 	GetActive(ctx context.Context) ([]models.Category, error)
 	Update(ctx context.Context, category *models.Category) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.Category, error)
+	Restore(ctx context.Context, id uuid.UUID) (bool, error)
+	CountVotes(ctx context.Context, id uuid.UUID) (int64, error)
 }
 
 type categoryRepository struct {
@@ -40,9 +44,9 @@ func (r *categoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return &category, err
 }
 
-func (r *categoryRepository) GetByName(ctx context.Context, name string) (*models.Category, error) {
+func (r *categoryRepository) GetByName(ctx context.Context, editionID uuid.UUID, name string) (*models.Category, error) {
 	var category models.Category
-	err := r.db.WithContext(ctx).First(&category, "name = ?", name).Error
+	err := r.db.WithContext(ctx).First(&category, "edition_id = ? AND name = ?", editionID, name).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -55,11 +59,17 @@ func (r *categoryRepository) GetAll(ctx context.Context) ([]models.Category, err
 	return categories, err
 }
 
+func (r *categoryRepository) GetByEdition(ctx context.Context, editionID uuid.UUID) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.WithContext(ctx).Where("edition_id = ?", editionID).Find(&categories).Error
+	return categories, err
+}
+
 func (r *categoryRepository) GetActive(ctx context.Context) ([]models.Category, error) {
 	var categories []models.Category
 	err := r.db.WithContext(ctx).
 		Select("categories.*").
-		Joins("LEFT JOIN votes ON votes.category_id = categories.category_id").
+		Joins("LEFT JOIN votes ON votes.category_id = categories.category_id AND votes.deleted_at IS NULL").
 		Group("categories.category_id").
 		Having("COUNT(votes.vote_id) > 0").
 		Find(&categories).Error
@@ -70,6 +80,37 @@ func (r *categoryRepository) Update(ctx context.Context, category *models.Catego
 	return r.db.WithContext(ctx).Save(category).Error
 }
 
+// Delete soft deletes the category; its nominee links and votes are kept.
 func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&models.Category{}, "category_id = ?", id).Error
 }
+
+// GetDeletedByID returns a soft-deleted category, or nil if there is no
+// deleted category with the ID.
+func (r *categoryRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	err := r.db.WithContext(ctx).Unscoped().First(&category, "category_id = ? AND deleted_at IS NOT NULL", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &category, err
+}
+
+// Restore undoes a soft delete. It reports whether a deleted category was
+// found.
+func (r *categoryRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&models.Category{}).
+		Where("category_id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountVotes returns the number of undeleted votes cast in the category,
+// whatever their review status.
+func (r *categoryRepository) CountVotes(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Vote{}).Where("category_id = ?", id).Count(&count).Error
+	return count, err
+}
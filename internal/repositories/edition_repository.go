@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"gorm.io/gorm"
+)
+
+type EditionRepository interface {
+	Create(ctx context.Context, edition *models.Edition) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Edition, error)
+	GetByYear(ctx context.Context, year int) (*models.Edition, error)
+	GetActive(ctx context.Context) (*models.Edition, error)
+	GetAll(ctx context.Context) ([]models.Edition, error)
+	Update(ctx context.Context, edition *models.Edition) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	SetActive(ctx context.Context, id uuid.UUID) error
+	CountCategories(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+type editionRepository struct {
+	db *gorm.DB
+}
+
+func NewEditionRepository(db *gorm.DB) EditionRepository {
+	return &editionRepository{db: db}
+}
+
+func (r *editionRepository) Create(ctx context.Context, edition *models.Edition) error {
+	return r.db.WithContext(ctx).Create(edition).Error
+}
+
+func (r *editionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Edition, error) {
+	var edition models.Edition
+	err := r.db.WithContext(ctx).First(&edition, "edition_id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &edition, err
+}
+
+func (r *editionRepository) GetByYear(ctx context.Context, year int) (*models.Edition, error) {
+	var edition models.Edition
+	err := r.db.WithContext(ctx).First(&edition, "year = ?", year).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &edition, err
+}
+
+func (r *editionRepository) GetActive(ctx context.Context) (*models.Edition, error) {
+	var edition models.Edition
+	err := r.db.WithContext(ctx).First(&edition, "is_active").Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &edition, err
+}
+
+func (r *editionRepository) GetAll(ctx context.Context) ([]models.Edition, error) {
+	var editions []models.Edition
+	err := r.db.WithContext(ctx).Order("year DESC").Find(&editions).Error
+	return editions, err
+}
+
+func (r *editionRepository) Update(ctx context.Context, edition *models.Edition) error {
+	return r.db.WithContext(ctx).Save(edition).Error
+}
+
+func (r *editionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Edition{}, "edition_id = ?", id).Error
+}
+
+// SetActive makes id the only active edition.
+func (r *editionRepository) SetActive(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deactivate first so the single-active unique index is never violated
+		if err := tx.Model(&models.Edition{}).
+			Where("is_active AND edition_id <> ?", id).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Edition{}).
+			Where("edition_id = ?", id).
+			Update("is_active", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *editionRepository) CountCategories(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Category{}).
+		Where("edition_id = ?", id).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FraudRepository reads voting activity for the fraud analyzer and records
+// the outcome of its scans and of admin reviews.
+type FraudRepository interface {
+	GetActivitySince(ctx context.Context, since time.Time) ([]VoteActivity, error)
+	Quarantine(ctx context.Context, flags []models.VoteFlag) (int64, error)
+	GetQuarantined(ctx context.Context) ([]models.Vote, error)
+	SetStatus(ctx context.Context, voteID uuid.UUID, from []string, status string, reviewerID uuid.UUID, at time.Time) (bool, error)
+}
+
+// VoteActivity is a vote together with the account details the fraud
+// detectors look at.
+type VoteActivity struct {
+	VoteID           uuid.UUID
+	UserID           uuid.UUID
+	CategoryID       uuid.UUID
+	NomineeID        uuid.UUID
+	CreatedAt        time.Time
+	AccountCreatedAt time.Time
+	// IPAddress is empty for votes without an audit record
+	IPAddress string
+}
+
+type fraudRepository struct {
+	db *gorm.DB
+}
+
+func NewFraudRepository(db *gorm.DB) FraudRepository {
+	return &fraudRepository{db: db}
+}
+
+// GetActivitySince returns every vote cast at or after since and not since
+// deleted, oldest first.
+func (r *fraudRepository) GetActivitySince(ctx context.Context, since time.Time) ([]VoteActivity, error) {
+	var activity []VoteActivity
+	err := r.db.WithContext(ctx).
+		Table("votes AS v").
+		Select(`v.vote_id, v.user_id, v.category_id, v.nominee_id, v.created_at,
+			u.created_at AS account_created_at,
+			COALESCE(va.ip_address, '') AS ip_address`).
+		Joins("JOIN users u ON u.user_id = v.user_id").
+		Joins("LEFT JOIN vote_audits va ON va.vote_id = v.vote_id").
+		Where("v.created_at >= ? AND v.deleted_at IS NULL", since).
+		Order("v.created_at, v.vote_id").
+		Scan(&activity).Error
+	return activity, err
+}
+
+// Quarantine records flags and moves the flagged votes into quarantine. Votes
+// an admin has already reviewed keep their status, so a restored vote is not
+// quarantined again by the next scan. It returns the number of votes newly
+// quarantined.
+func (r *fraudRepository) Quarantine(ctx context.Context, flags []models.VoteFlag) (int64, error) {
+	if len(flags) == 0 {
+		return 0, nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(flags))
+	var voteIDs []uuid.UUID
+	for _, flag := range flags {
+		if !seen[flag.VoteID] {
+			seen[flag.VoteID] = true
+			voteIDs = append(voteIDs, flag.VoteID)
+		}
+	}
+
+	var quarantined int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&flags).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.Vote{}).
+			Where("vote_id IN ? AND status = ? AND reviewed_at IS NULL", voteIDs, models.VoteStatusValid).
+			Update("status", models.VoteStatusQuarantined)
+		quarantined = result.RowsAffected
+		return result.Error
+	})
+	return quarantined, err
+}
+
+// GetQuarantined returns the votes awaiting review with their flags, oldest
+// first.
+func (r *fraudRepository) GetQuarantined(ctx context.Context) ([]models.Vote, error) {
+	var votes []models.Vote
+	err := r.db.WithContext(ctx).
+		Preload("Category").
+		Preload("Nominee").
+		Preload("Flags", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, rule") }).
+		Where("status = ?", models.VoteStatusQuarantined).
+		Order("created_at, vote_id").
+		Find(&votes).Error
+	return votes, err
+}
+
+// SetStatus records an admin's review of a vote, moving it to status if it
+// is currently in one of from. It reports whether the vote was updated.
+func (r *fraudRepository) SetStatus(ctx context.Context, voteID uuid.UUID, from []string, status string, reviewerID uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Vote{}).
+		Where("vote_id = ? AND status IN ?", voteID, from).
+		Updates(map[string]any{
+			"status":      status,
+			"reviewed_at": at,
+			"reviewed_by": reviewerID,
+		})
+	return result.RowsAffected > 0, result.Error
+}
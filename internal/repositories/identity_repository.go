@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdentityRepository stores the external identities users sign in with and
+// the sign-ins in progress.
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *models.Identity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error)
+	RecordLogin(ctx context.Context, id uuid.UUID, at time.Time) error
+	CreateLoginRequest(ctx context.Context, request *models.OIDCLoginRequest) error
+	ConsumeLoginRequest(ctx context.Context, provider, stateHash string, now time.Time) (*models.OIDCLoginRequest, error)
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *models.Identity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// GetByProviderSubject returns the identity, or nil if it is not linked to
+// any user.
+func (r *identityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	var identity models.Identity
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &identity, err
+}
+
+func (r *identityRepository) RecordLogin(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Identity{}).
+		Where("identity_id = ?", id).
+		Update("last_login_at", at).Error
+}
+
+func (r *identityRepository) CreateLoginRequest(ctx context.Context, request *models.OIDCLoginRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+// ConsumeLoginRequest deletes and returns the unexpired sign-in started with
+// the state, or nil if there is none. Each state can only be used once.
+func (r *identityRepository) ConsumeLoginRequest(ctx context.Context, provider, stateHash string, now time.Time) (*models.OIDCLoginRequest, error) {
+	var requests []models.OIDCLoginRequest
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("provider = ? AND state_hash = ? AND expires_at > ?", provider, stateHash, now).
+		Delete(&requests).Error
+	if err != nil || len(requests) == 0 {
+		return nil, err
+	}
+	return &requests[0], nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nyashahama/music-awards/internal/models"
+	"gorm.io/gorm"
+)
+
+// LedgerRepository appends to and reads the hash-chained vote ledger
+type LedgerRepository interface {
+	Append(ctx context.Context, entry *models.VoteLedgerEntry) error
+	ListAfter(ctx context.Context, sequence int64, limit int) ([]models.VoteLedgerEntry, error)
+}
+
+// ledgerLockKey names the advisory lock that serialises appends, so each
+// entry links to the one committed before it.
+const ledgerLockKey = "vote_ledger"
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// Append chains entry onto the ledger, filling in its sequence, previous hash,
+// timestamp and hash. It must run inside a transaction, which holds the
+// ledger lock until it ends, so that the entry commits or rolls back together
+// with the vote change it records.
+func (r *ledgerRepository) Append(ctx context.Context, entry *models.VoteLedgerEntry) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", ledgerLockKey).Error; err != nil {
+		return err
+	}
+
+	var last models.VoteLedgerEntry
+	err := db.Order("sequence DESC").Take(&last).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		entry.Sequence = 1
+		entry.PrevHash = models.GenesisHash
+	case err != nil:
+		return err
+	default:
+		entry.Sequence = last.Sequence + 1
+		entry.PrevHash = last.Hash
+	}
+
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	entry.Hash = entry.ComputeHash()
+	return db.Create(entry).Error
+}
+
+// ListAfter returns up to limit entries following sequence, in order.
+func (r *ledgerRepository) ListAfter(ctx context.Context, sequence int64, limit int) ([]models.VoteLedgerEntry, error) {
+	var entries []models.VoteLedgerEntry
+	err := r.db.WithContext(ctx).
+		Where("sequence > ?", sequence).
+		Order("sequence").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/nyashahama/music-awards/internal/models"
+	"gorm.io/gorm"
+)
+
+// LoginThrottleRepository counts failed logins to slow down password
+// guessing.
+type LoginThrottleRepository interface {
+	Get(ctx context.Context, keys []string) ([]models.LoginThrottle, error)
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, now, until time.Time) (bool, error)
+	Reset(ctx context.Context, key string) error
+}
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+func (r *loginThrottleRepository) Get(ctx context.Context, keys []string) ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	err := r.db.WithContext(ctx).
+		Where("throttle_key IN ?", keys).
+		Find(&throttles).Error
+	return throttles, err
+}
+
+const recordLoginFailureSQL = `INSERT INTO login_throttles (throttle_key, failures, last_failure_at)
+VALUES (?, 1, ?)
+ON CONFLICT (throttle_key) DO UPDATE SET
+  failures = CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END,
+  last_failure_at = EXCLUDED.last_failure_at
+RETURNING failures`
+
+// RecordFailure counts a failed login for key and returns the number of
+// failures, counting from 1 again if the last was longer than window ago.
+// Concurrent failures are all counted.
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	var failures int
+	err := r.db.WithContext(ctx).
+		Raw(recordLoginFailureSQL, key, now, now.Add(-window)).
+		Scan(&failures).Error
+	return failures, err
+}
+
+// lockLoginSQL sets the lock and returns whether the key was unlocked before.
+// The old value is read with the row locked, so of concurrent callers only
+// the first sees the key unlocked.
+const lockLoginSQL = `UPDATE login_throttles AS t SET locked_until = ?
+FROM (SELECT throttle_key, locked_until FROM login_throttles WHERE throttle_key = ? FOR UPDATE) AS prev
+WHERE t.throttle_key = prev.throttle_key
+RETURNING prev.locked_until IS NULL OR prev.locked_until <= ?`
+
+// Lock blocks logins for key until until. It reports whether key was
+// unlocked at now, before this call.
+func (r *loginThrottleRepository) Lock(ctx context.Context, key string, now, until time.Time) (bool, error) {
+	var wasUnlocked bool
+	err := r.db.WithContext(ctx).
+		Raw(lockLoginSQL, until, key, now).
+		Scan(&wasUnlocked).Error
+	return wasUnlocked, err
+}
+
+func (r *loginThrottleRepository) Reset(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).
+		Where("throttle_key = ?", key).
+		Delete(&models.LoginThrottle{}).Error
+}
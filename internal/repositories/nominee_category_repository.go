@@ -13,7 +13,9 @@ type NomineeCategoryRepository interface {
 	RemoveCategory(ctx context.Context, nomineeID, categoryID uuid.UUID) error
 	GetCategoriesForNominee(ctx context.Context, nomineeID uuid.UUID) ([]models.Category, error)
 	GetNomineesForCategory(ctx context.Context, categoryID uuid.UUID) ([]models.Nominee, error)
-	SetCategories(ctx context.Context, nomineeID uuid.UUID, categoryIDs []uuid.UUID) error
+	UpdateCategories(ctx context.Context, nomineeID uuid.UUID, added, removed []uuid.UUID) error
+	CountVotes(ctx context.Context, nomineeID uuid.UUID, categoryIDs []uuid.UUID) (int64, error)
+	ContainsNominees(ctx context.Context, categoryID uuid.UUID, nomineeIDs []uuid.UUID) (bool, error)
 }
 
 type nomineeCategoryRepository struct {
@@ -24,16 +26,24 @@ func NewNomineeCategoryRepository(db *gorm.DB) NomineeCategoryRepository {
 	return &nomineeCategoryRepository{db: db}
 }
 
+// linkCategoriesSQL copies each category's edition onto the link so that
+// links stay scoped to the same edition as their category. Deleted categories
+// are skipped.
+const linkCategoriesSQL = `
+INSERT INTO nominee_categories (nominee_id, category_id, edition_id)
+SELECT ?, category_id, edition_id FROM categories WHERE category_id IN ? AND deleted_at IS NULL
+ON CONFLICT (nominee_id, category_id) DO NOTHING`
+
 func (r *nomineeCategoryRepository) AddCategory(ctx context.Context, nomineeID, categoryID uuid.UUID) error {
-	return r.db.WithContext(ctx).Model(&models.Nominee{NomineeID: nomineeID}).
-		Association("Categories").
-		Append(&models.Category{CategoryID: categoryID})
+	return r.db.WithContext(ctx).Exec(linkCategoriesSQL, nomineeID, []uuid.UUID{categoryID}).Error
 }
 
+// RemoveCategory deletes the link between the nominee and the category.
+// Votes hold on to their link, so it fails while the link has any.
 func (r *nomineeCategoryRepository) RemoveCategory(ctx context.Context, nomineeID, categoryID uuid.UUID) error {
-	return r.db.WithContext(ctx).Model(&models.Nominee{NomineeID: nomineeID}).
-		Association("Categories").
-		Delete(&models.Category{CategoryID: categoryID})
+	return r.db.WithContext(ctx).
+		Where("nominee_id = ? AND category_id = ?", nomineeID, categoryID).
+		Delete(&models.NomineeCategory{}).Error
 }
 
 func (r *nomineeCategoryRepository) GetCategoriesForNominee(ctx context.Context, nomineeID uuid.UUID) ([]models.Category, error) {
@@ -56,26 +66,44 @@ func (r *nomineeCategoryRepository) GetNomineesForCategory(ctx context.Context,
 	return nominees, err
 }
 
-func (r *nomineeCategoryRepository) SetCategories(ctx context.Context, nomineeID uuid.UUID, categoryIDs []uuid.UUID) error {
+// UpdateCategories links the nominee to the added categories and unlinks it
+// from the removed ones, leaving its other links as they are.
+func (r *nomineeCategoryRepository) UpdateCategories(ctx context.Context, nomineeID uuid.UUID, added, removed []uuid.UUID) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Clear existing associations
-		if err := tx.Model(&models.Nominee{NomineeID: nomineeID}).
-			Association("Categories").Clear(); err != nil {
-			return err
-		}
-
-		// Only add new categories if there are any
-		if len(categoryIDs) > 0 {
-			categories := make([]models.Category, len(categoryIDs))
-			for i, id := range categoryIDs {
-				categories[i] = models.Category{CategoryID: id}
+		if len(removed) > 0 {
+			err := tx.Where("nominee_id = ? AND category_id IN ?", nomineeID, removed).
+				Delete(&models.NomineeCategory{}).Error
+			if err != nil {
+				return err
 			}
-
-			if err := tx.Model(&models.Nominee{NomineeID: nomineeID}).
-				Association("Categories").Replace(categories); err != nil {
+		}
+		if len(added) > 0 {
+			if err := tx.Exec(linkCategoriesSQL, nomineeID, added).Error; err != nil {
 				return err
 			}
 		}
 		return nil
 	})
 }
+
+// CountVotes returns the number of votes for the nominee in any of
+// categoryIDs. Deleted votes are counted too, since they can be restored.
+func (r *nomineeCategoryRepository) CountVotes(ctx context.Context, nomineeID uuid.UUID, categoryIDs []uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Vote{}).
+		Where("nominee_id = ? AND category_id IN ?", nomineeID, categoryIDs).
+		Count(&count).Error
+	return count, err
+}
+
+// ContainsNominees reports whether every one of nomineeIDs is linked to the
+// category and not deleted. nomineeIDs must not contain duplicates.
+func (r *nomineeCategoryRepository) ContainsNominees(ctx context.Context, categoryID uuid.UUID, nomineeIDs []uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.NomineeCategory{}).
+		Joins("JOIN nominees n ON n.nominee_id = nominee_categories.nominee_id AND n.deleted_at IS NULL").
+		Where("nominee_categories.category_id = ? AND nominee_categories.nominee_id IN ?", categoryID, nomineeIDs).
+		Count(&count).Error
+	return count == int64(len(nomineeIDs)), err
+}
@@ -15,6 +15,8 @@ type NomineeRepository interface {
 	GetAll(ctx context.Context) ([]models.Nominee, error)
 	Update(ctx context.Context, nominee *models.Nominee) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) (bool, error)
+	CountVotes(ctx context.Context, id uuid.UUID) (int64, error)
 }
 
 type nomineeRepository struct {
@@ -59,6 +61,26 @@ func (r *nomineeRepository) Update(ctx context.Context, nominee *models.Nominee)
 	return r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Save(nominee).Error
 }
 
+// Delete soft deletes the nominee; its category links and votes are kept.
 func (r *nomineeRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&models.Nominee{}, "nominee_id = ?", id).Error
 }
+
+// Restore undoes a soft delete. It reports whether a deleted nominee was
+// found.
+func (r *nomineeRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&models.Nominee{}).
+		Where("nominee_id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountVotes returns the number of undeleted votes for the nominee in any
+// category, whatever their review status.
+func (r *nomineeRepository) CountVotes(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Vote{}).Where("nominee_id = ?", id).Count(&count).Error
+	return count, err
+}
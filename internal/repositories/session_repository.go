@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SessionRepository stores login sessions and their refresh tokens.
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error)
+	Rotate(ctx context.Context, oldHash, newHash string, expiresAt, now time.Time) (*models.Session, error)
+	RevokeByPreviousHash(ctx context.Context, hash string, now time.Time) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID, now time.Time) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, now time.Time) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// GetByID returns the session, or nil if there is none.
+func (r *sessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	err := r.db.WithContext(ctx).First(&session, "session_id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &session, err
+}
+
+// Rotate replaces the refresh token of the active session holding oldHash
+// with newHash and extends the session to expiresAt. It returns the updated
+// session, or nil if no active session holds oldHash. Each token can only
+// be rotated once, even by concurrent requests.
+func (r *sessionRepository) Rotate(ctx context.Context, oldHash, newHash string, expiresAt, now time.Time) (*models.Session, error) {
+	var session models.Session
+	result := r.db.WithContext(ctx).
+		Model(&session).
+		Clauses(clause.Returning{}).
+		Where("refresh_token_hash = ? AND revoked_at IS NULL AND expires_at > ?", oldHash, now).
+		Updates(map[string]any{
+			"refresh_token_hash":          newHash,
+			"previous_refresh_token_hash": oldHash,
+			"expires_at":                  expiresAt,
+			"last_used_at":                now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// RevokeByPreviousHash revokes the session whose last replaced refresh token
+// has hash. It reports whether a session was revoked.
+func (r *sessionRepository) RevokeByPreviousHash(ctx context.Context, hash string, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("previous_refresh_token_hash = ? AND revoked_at IS NULL", hash).
+		Update("revoked_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID, now time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("session_id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+// RevokeAllForUser ends every session of the user.
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenRepository stores the single-use tokens emailed to users.
+type TokenRepository interface {
+	Create(ctx context.Context, token *models.UserToken) error
+	Consume(ctx context.Context, purpose, hash string, now time.Time) (*models.UserToken, error)
+	Revoke(ctx context.Context, userID uuid.UUID, purpose string, now time.Time) error
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) Create(ctx context.Context, token *models.UserToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// Consume marks the unexpired, unused token with hash as used and returns
+// it, or nil if there is no such token. A token can only be consumed once,
+// even by concurrent requests.
+func (r *tokenRepository) Consume(ctx context.Context, purpose, hash string, now time.Time) (*models.UserToken, error) {
+	var token models.UserToken
+	result := r.db.WithContext(ctx).
+		Model(&token).
+		Clauses(clause.Returning{}).
+		Where("purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", purpose, hash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// Revoke uses up every outstanding token of the user for purpose, so that
+// only a token issued afterwards is accepted.
+func (r *tokenRepository) Revoke(ctx context.Context, userID uuid.UUID, purpose string, now time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", now).Error
+}
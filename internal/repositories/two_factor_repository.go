@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository stores users' TOTP settings and recovery codes.
+type TwoFactorRepository interface {
+	SetSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	Enable(ctx context.Context, userID uuid.UUID, at time.Time) error
+	Disable(ctx context.Context, userID uuid.UUID) error
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, hash string, now time.Time) (bool, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// SetSecret stores a secret waiting to be confirmed, replacing any earlier
+// one. It does nothing once two-factor authentication is on.
+func (r *twoFactorRepository) SetSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("user_id = ? AND totp_enabled_at IS NULL", userID).
+		Updates(map[string]any{"totp_secret": secret, "totp_last_step": 0}).Error
+}
+
+func (r *twoFactorRepository) Enable(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("user_id = ? AND totp_secret IS NOT NULL", userID).
+		Update("totp_enabled_at", at).Error
+}
+
+func (r *twoFactorRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{"totp_secret": nil, "totp_enabled_at": nil, "totp_last_step": 0}).Error
+}
+
+// UseStep records that a code from time step was accepted. It reports false
+// if a code from the same or a later step was already accepted, so each code
+// works once even under concurrent requests.
+func (r *twoFactorRepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("user_id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReplaceRecoveryCodes deletes the user's recovery codes and stores hashes
+// as the new ones.
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&models.RecoveryCode{}).Error
+	if err != nil || len(hashes) == 0 {
+		return err
+	}
+	codes := make([]models.RecoveryCode, len(hashes))
+	for i, hash := range hashes {
+		codes[i] = models.RecoveryCode{RecoveryCodeID: uuid.New(), UserID: userID, CodeHash: hash}
+	}
+	return r.db.WithContext(ctx).Create(&codes).Error
+}
+
+// ConsumeRecoveryCode marks the user's unused recovery code with hash as
+// used. It reports false if there is no such code.
+func (r *twoFactorRepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, hash string, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", now)
+	return result.RowsAffected > 0, result.Error
+}
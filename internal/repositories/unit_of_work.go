@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Tx exposes repositories bound to a single database transaction.
+type Tx interface {
+	Users() UserRepository
+	Votes() VoteRepository
+	Audits() AuditRepository
+	Ledger() LedgerRepository
+	Fraud() FraudRepository
+	Tokens() TokenRepository
+	Sessions() SessionRepository
+	Identities() IdentityRepository
+	TwoFactor() TwoFactorRepository
+}
+
+// UnitOfWork runs fn inside a database transaction. The transaction commits
+// when fn returns nil and rolls back otherwise, returning fn's error as is.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(tx Tx) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(tx Tx) error) error {
+	return u.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+		return fn(&gormTx{db: db})
+	})
+}
+
+type gormTx struct {
+	db *gorm.DB
+}
+
+func (t *gormTx) Users() UserRepository {
+	return NewUserRepository(t.db)
+}
+
+func (t *gormTx) Votes() VoteRepository {
+	return NewVoteRepository(t.db)
+}
+
+func (t *gormTx) Audits() AuditRepository {
+	return NewAuditRepository(t.db)
+}
+
+func (t *gormTx) Ledger() LedgerRepository {
+	return NewLedgerRepository(t.db)
+}
+
+func (t *gormTx) Fraud() FraudRepository {
+	return NewFraudRepository(t.db)
+}
+
+func (t *gormTx) Tokens() TokenRepository {
+	return NewTokenRepository(t.db)
+}
+
+func (t *gormTx) Sessions() SessionRepository {
+	return NewSessionRepository(t.db)
+}
+
+func (t *gormTx) Identities() IdentityRepository {
+	return NewIdentityRepository(t.db)
+}
+
+func (t *gormTx) TwoFactor() TwoFactorRepository {
+	return NewTwoFactorRepository(t.db)
+}
@@ -4,21 +4,33 @@ package repositories
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetAll(ctx context.Context) ([]models.User, error)
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	Restore(ctx context.Context, id uuid.UUID) (bool, error)
+	MarkEmailVerified(ctx context.Context, id uuid.UUID, at time.Time) error
+	ResetPassword(ctx context.Context, id uuid.UUID, passwordHash string) error
 	DecrementAvailableVotes(ctx context.Context, userID uuid.UUID) error
 	IncrementAvailableVotes(ctx context.Context, userID uuid.UUID) error
+	AdjustAvailableVotes(ctx context.Context, userID uuid.UUID, delta int) error
+	ResetAvailableVotes(ctx context.Context, editionID uuid.UUID, budget int, roleBudgets map[string]int) (int64, error)
 }
 
 type userRepository struct {
@@ -42,6 +54,19 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	return &user, err
 }
 
+// GetByIDForUpdate locks the user's row until the surrounding transaction
+// ends. It must be called through a UnitOfWork.
+func (r *userRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&user, "user_id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &user, err
+}
+
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	err := r.db.WithContext(ctx).
@@ -53,6 +78,17 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return &user, err
 }
 
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).
+		Where("username = ?", username).
+		First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &user, err
+}
+
 func (r *userRepository) GetAll(ctx context.Context) ([]models.User, error) {
 	var users []models.User
 	err := r.db.WithContext(ctx).Find(&users).Error
@@ -63,10 +99,53 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	return r.db.WithContext(ctx).Save(user).Error
 }
 
+// Delete soft deletes the user; their votes are kept.
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&models.User{}, "user_id = ?", id).Error
 }
 
+// GetDeletedByID returns a soft-deleted user, or nil if there is no deleted
+// user with the ID.
+func (r *userRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Unscoped().First(&user, "user_id = ? AND deleted_at IS NOT NULL", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &user, err
+}
+
+// Restore undoes a soft delete. It reports whether a deleted user was found.
+func (r *userRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&models.User{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkEmailVerified records when the user verified their email address. An
+// earlier verification is kept.
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("user_id = ? AND email_verified_at IS NULL", id).
+		Update("email_verified_at", at).Error
+}
+
+// ResetPassword replaces the user's password and bumps their token version,
+// so that tokens issued before the reset are rejected.
+func (r *userRepository) ResetPassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("user_id = ?", id).
+		Updates(map[string]any{
+			"password_hash": passwordHash,
+			"token_version": gorm.Expr("token_version + 1"),
+		}).Error
+}
+
 func (r *userRepository) DecrementAvailableVotes(ctx context.Context, userID uuid.UUID) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var user models.User
@@ -89,3 +168,46 @@ func (r *userRepository) IncrementAvailableVotes(ctx context.Context, userID uui
 		Where("user_id = ?", userID).
 		Update("available_votes", gorm.Expr("available_votes + 1")).Error
 }
+
+// AdjustAvailableVotes adds delta to the user's budget, never going below zero.
+func (r *userRepository) AdjustAvailableVotes(ctx context.Context, userID uuid.UUID, delta int) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Update("available_votes", gorm.Expr("GREATEST(available_votes + ?, 0)", delta)).Error
+}
+
+// ResetAvailableVotes sets every user's budget to budget, or to their role's
+// entry in roleBudgets, less the votes they have already cast in the edition.
+// Void votes were rejected and give their vote back. Deleted users are left
+// as they are. It returns the number of users updated.
+func (r *userRepository) ResetAvailableVotes(ctx context.Context, editionID uuid.UUID, budget int, roleBudgets map[string]int) (int64, error) {
+	roles := make([]string, 0, len(roleBudgets))
+	for role := range roleBudgets {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	var expr strings.Builder
+	var args []any
+	if len(roles) > 0 {
+		expr.WriteString("CASE role")
+		for _, role := range roles {
+			expr.WriteString(" WHEN ? THEN CAST(? AS INT)")
+			args = append(args, role, roleBudgets[role])
+		}
+		expr.WriteString(" ELSE CAST(? AS INT) END")
+	} else {
+		expr.WriteString("CAST(? AS INT)")
+	}
+	args = append(args, budget, editionID, models.VoteStatusVoid)
+
+	result := r.db.WithContext(ctx).Exec(`UPDATE users u
+		SET available_votes = GREATEST(`+expr.String()+` -
+			(SELECT COUNT(*) FROM votes v
+				WHERE v.user_id = u.user_id AND v.edition_id = ? AND v.status <> ?
+					AND v.deleted_at IS NULL), 0),
+			updated_at = NOW()
+		WHERE u.deleted_at IS NULL`, args...)
+	return result.RowsAffected, result.Error
+}
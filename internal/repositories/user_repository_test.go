@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// openTestDB migrates the Postgres database at TEST_DATABASE_URL, a
+// postgres:// URL, and returns a transaction on it that is rolled back when
+// the test ends. Tests that need it are skipped when the variable is unset.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	m, err := migrate.New("file://../../migrations", url)
+	require.NoError(t, err)
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		t.Fatalf("migrating test database: %v", err)
+	}
+	m.Close()
+
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+	require.NoError(t, err)
+	tx := db.Begin()
+	require.NoError(t, tx.Error)
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+func TestUserRepository_ResetAvailableVotes(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	edition := models.Edition{EditionID: uuid.New(), Name: "Test Edition", Year: 10000 + rand.IntN(1000000)}
+	category := models.Category{CategoryID: uuid.New(), EditionID: edition.EditionID, Name: "Best Album"}
+	nominee := models.Nominee{NomineeID: uuid.New(), Name: "Nominee"}
+	link := models.NomineeCategory{NomineeID: nominee.NomineeID, CategoryID: category.CategoryID, EditionID: edition.EditionID}
+	newUser := func(name string) models.User {
+		return models.User{UserID: uuid.New(), Username: name + "-" + uuid.NewString(), Email: name + "-" + uuid.NewString() + "@example.com", PasswordHash: "x", Role: models.RoleUser}
+	}
+	voter, deleted := newUser("voter"), newUser("deleted")
+	vote := func(user models.User, status string) *models.Vote {
+		return &models.Vote{VoteID: uuid.New(), UserID: user.UserID, EditionID: edition.EditionID, CategoryID: category.CategoryID, NomineeID: nominee.NomineeID, Status: status}
+	}
+
+	for _, row := range []any{&edition, &category, &nominee, &link, &voter, &deleted,
+		vote(voter, models.VoteStatusValid), vote(voter, models.VoteStatusQuarantined), vote(voter, models.VoteStatusVoid)} {
+		require.NoError(t, db.Omit(clause.Associations).Create(row).Error)
+	}
+	require.NoError(t, db.Model(&models.User{}).Where("user_id = ?", deleted.UserID).
+		Updates(map[string]any{"available_votes": 0, "deleted_at": time.Now()}).Error)
+
+	_, err := NewUserRepository(db).ResetAvailableVotes(ctx, edition.EditionID, 5, nil)
+	require.NoError(t, err)
+
+	var got models.User
+	require.NoError(t, db.First(&got, "user_id = ?", voter.UserID).Error)
+	assert.Equal(t, 3, got.AvailableVotes, "the void vote is given back")
+	require.NoError(t, db.Unscoped().First(&got, "user_id = ?", deleted.UserID).Error)
+	assert.Equal(t, 0, got.AvailableVotes, "deleted users are left alone")
+}
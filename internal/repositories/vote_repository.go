@@ -7,16 +7,39 @@ import (
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type VoteRepository interface {
 	Create(ctx context.Context, vote *models.Vote) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Vote, error)
-	GetAll(ctx context.Context) ([]models.Vote, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Vote, error)
+	GetDeletedByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Vote, error)
+	GetByCategories(ctx context.Context, categoryIDs []uuid.UUID) ([]models.Vote, error)
 	GetByUser(ctx context.Context, userID uuid.UUID) ([]models.Vote, error)
 	GetByUserAndCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Vote, error)
 	Update(ctx context.Context, vote *models.Vote) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) (bool, error)
+	ReplaceRankings(ctx context.Context, voteID uuid.UUID, rankings []models.BallotRanking) error
+	RecordChange(ctx context.Context, change *models.VoteChange) error
+	GetChanges(ctx context.Context, voteID uuid.UUID) ([]models.VoteChange, error)
+	GetBallots(ctx context.Context, categoryID uuid.UUID) ([][]uuid.UUID, error)
+	TallyByCategory(ctx context.Context, categoryID uuid.UUID) ([]NomineeTally, error)
+	TallyByEdition(ctx context.Context, editionID uuid.UUID) ([]NomineeTally, error)
+}
+
+// NomineeTally is the vote count of one nominee in one category, with the
+// split between jury and public votes. Nominees without votes are included
+// with a zero count.
+type NomineeTally struct {
+	CategoryID  uuid.UUID
+	NomineeID   uuid.UUID
+	NomineeName string
+	Votes       int64
+	JuryVotes   int64
+	PublicVotes int64
+	Percentage  float64
 }
 
 type voteRepository struct {
@@ -36,6 +59,7 @@ func (r *voteRepository) GetByID(ctx context.Context, voteID uuid.UUID) (*models
 	err := r.db.WithContext(ctx).
 		Preload("Category").
 		Preload("Nominee").
+		Preload("Rankings", orderRankings).
 		Where("vote_id = ?", voteID).
 		First(&vote).Error
 	if err != nil {
@@ -44,12 +68,32 @@ func (r *voteRepository) GetByID(ctx context.Context, voteID uuid.UUID) (*models
 	return &vote, nil
 }
 
-func (r *voteRepository) GetAll(ctx context.Context) ([]models.Vote, error) {
+// GetByIDForUpdate locks the vote's row until the surrounding transaction
+// ends. Only the ballot's rankings are preloaded; they change solely under
+// this lock.
+func (r *voteRepository) GetByIDForUpdate(ctx context.Context, voteID uuid.UUID) (*models.Vote, error) {
+	var vote models.Vote
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Rankings", orderRankings).
+		Where("vote_id = ?", voteID).
+		First(&vote).Error
+	if err != nil {
+		return nil, err
+	}
+	return &vote, nil
+}
+
+// GetByCategories returns the votes cast in any of categoryIDs.
+func (r *voteRepository) GetByCategories(ctx context.Context, categoryIDs []uuid.UUID) ([]models.Vote, error) {
+	if len(categoryIDs) == 0 {
+		return nil, nil
+	}
 	var votes []models.Vote
-	// Preload the relationships for GetAll as well
 	err := r.db.WithContext(ctx).
 		Preload("Category").
 		Preload("Nominee").
+		Where("category_id IN ?", categoryIDs).
 		Find(&votes).Error
 	return votes, err
 }
@@ -60,6 +104,7 @@ func (r *voteRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]mod
 	err := r.db.WithContext(ctx).
 		Preload("Category").
 		Preload("Nominee").
+		Preload("Rankings", orderRankings).
 		Where("user_id = ?", userID).
 		Find(&votes).Error
 	return votes, err
@@ -84,6 +129,126 @@ func (r *voteRepository) Update(ctx context.Context, vote *models.Vote) error {
 	return r.db.WithContext(ctx).Save(vote).Error
 }
 
+// Delete soft deletes the vote; its rankings and history are kept.
 func (r *voteRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&models.Vote{}, "vote_id = ?", id).Error
 }
+
+// GetDeletedByIDForUpdate returns a soft-deleted vote and locks its row until
+// the surrounding transaction ends. Votes that are not deleted are not found.
+func (r *voteRepository) GetDeletedByIDForUpdate(ctx context.Context, voteID uuid.UUID) (*models.Vote, error) {
+	var vote models.Vote
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Rankings", orderRankings).
+		Where("vote_id = ? AND deleted_at IS NOT NULL", voteID).
+		First(&vote).Error
+	if err != nil {
+		return nil, err
+	}
+	return &vote, nil
+}
+
+// Restore undoes a soft delete. It reports whether a deleted vote was found.
+func (r *voteRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&models.Vote{}).
+		Where("vote_id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReplaceRankings swaps the ballot's preference order for rankings.
+func (r *voteRepository) ReplaceRankings(ctx context.Context, voteID uuid.UUID, rankings []models.BallotRanking) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.BallotRanking{}, "vote_id = ?", voteID).Error; err != nil {
+			return err
+		}
+		if len(rankings) == 0 {
+			return nil
+		}
+		return tx.Create(&rankings).Error
+	})
+}
+
+func (r *voteRepository) RecordChange(ctx context.Context, change *models.VoteChange) error {
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+// GetChanges returns the vote's change history, oldest first.
+func (r *voteRepository) GetChanges(ctx context.Context, voteID uuid.UUID) ([]models.VoteChange, error) {
+	var changes []models.VoteChange
+	err := r.db.WithContext(ctx).
+		Where("vote_id = ?", voteID).
+		Order("changed_at, change_id").
+		Find(&changes).Error
+	return changes, err
+}
+
+// GetBallots returns every valid, undeleted ballot cast in the category as nominee IDs in
+// preference order. Votes without rankings, such as those cast before the
+// category switched to ranked choice, are single-preference ballots.
+func (r *voteRepository) GetBallots(ctx context.Context, categoryID uuid.UUID) ([][]uuid.UUID, error) {
+	var rows []struct {
+		VoteID    uuid.UUID
+		NomineeID uuid.UUID
+	}
+	err := r.db.WithContext(ctx).
+		Table("votes AS v").
+		Select("v.vote_id, COALESCE(br.nominee_id, v.nominee_id) AS nominee_id").
+		Joins("LEFT JOIN ballot_rankings br ON br.vote_id = v.vote_id").
+		Where("v.category_id = ? AND v.status = ? AND v.deleted_at IS NULL", categoryID, models.VoteStatusValid).
+		Order("v.vote_id, br.position").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var ballots [][]uuid.UUID
+	for i, row := range rows {
+		if i == 0 || row.VoteID != rows[i-1].VoteID {
+			ballots = append(ballots, nil)
+		}
+		ballots[len(ballots)-1] = append(ballots[len(ballots)-1], row.NomineeID)
+	}
+	return ballots, nil
+}
+
+func (r *voteRepository) TallyByCategory(ctx context.Context, categoryID uuid.UUID) ([]NomineeTally, error) {
+	return r.tally(ctx, "nc.category_id = ?", categoryID)
+}
+
+func (r *voteRepository) TallyByEdition(ctx context.Context, editionID uuid.UUID) ([]NomineeTally, error) {
+	return r.tally(ctx, "nc.edition_id = ?", editionID)
+}
+
+// tally counts valid votes per nominee-category link matching where, with
+// each nominee's share of its category's total computed in the same query.
+// Quarantined, void and deleted votes are left out, as are deleted nominees.
+func (r *voteRepository) tally(ctx context.Context, where string, args ...any) ([]NomineeTally, error) {
+	var tallies []NomineeTally
+	err := r.db.WithContext(ctx).
+		Table("nominee_categories AS nc").
+		Select(`nc.category_id,
+			n.nominee_id,
+			n.name AS nominee_name,
+			COUNT(v.vote_id) AS votes,
+			COUNT(v.vote_id) FILTER (WHERE v.voter_class = 'jury') AS jury_votes,
+			COUNT(v.vote_id) FILTER (WHERE v.voter_class <> 'jury') AS public_votes,
+			COALESCE(ROUND(COUNT(v.vote_id) * 100.0 /
+				NULLIF(SUM(COUNT(v.vote_id)) OVER (PARTITION BY nc.category_id), 0), 2), 0) AS percentage`).
+		Joins("JOIN nominees n ON n.nominee_id = nc.nominee_id AND n.deleted_at IS NULL").
+		Joins(`LEFT JOIN votes v ON v.nominee_id = nc.nominee_id AND v.category_id = nc.category_id
+			AND v.status = ? AND v.deleted_at IS NULL`, models.VoteStatusValid).
+		Where(where, args...).
+		Group("nc.category_id, n.nominee_id, n.name").
+		Order("nc.category_id, votes DESC, n.name").
+		Scan(&tallies).Error
+	return tallies, err
+}
+
+func orderRankings(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,24 +17,52 @@ type JWTClaims struct {
 	Username string `json:"username"`
 	Role     string `json:"role"`
 	Email    string `json:"email"`
+
+	// TokenVersion is the user's token version when the token was issued
+	TokenVersion int `json:"ver"`
+	// SessionID is the login session the token belongs to
+	SessionID string `json:"sid"`
+	// AMR lists how the user authenticated (RFC 8176)
+	AMR []string `json:"amr,omitempty"`
+
 	jwt.RegisteredClaims
 }
 
 var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
+// ErrSessionRevoked is returned for a well-formed token whose session has
+// since been ended, for example by a password reset.
+var ErrSessionRevoked = errors.New("session has been revoked")
+
 var ValidateJWT = validateJWT
 
-func GenerateJWT(userID uuid.UUID, username, role, email string) (string, error) {
+// AMRTwoFactor is the authentication method of tokens whose session was
+// started with a second factor.
+const AMRTwoFactor = "mfa"
+
+// AccessTokenTTL is how long an access token is accepted. Clients use their
+// refresh token to get a new one.
+const AccessTokenTTL = 15 * time.Minute
+
+func GenerateJWT(userID uuid.UUID, username, role, email string, tokenVersion int, sessionID uuid.UUID, amr ...string) (string, error) {
 	claims := JWTClaims{
-		UserID:   userID.String(),
-		Username: username,
-		Role:     role,
-		Email:    email,
+		UserID:       userID.String(),
+		Username:     username,
+		Role:         role,
+		Email:        email,
+		TokenVersion: tokenVersion,
+		SessionID:    sessionID.String(),
+		AMR:          amr,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if keySet != nil {
+		token := jwt.NewWithClaims(keySet.signing.Method, claims)
+		token.Header["kid"] = keySet.signing.ID
+		return token.SignedString(keySet.signing.Private)
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
@@ -41,11 +70,20 @@ func GenerateJWT(userID uuid.UUID, username, role, email string) (string, error)
 // Actual implementation
 func validateJWT(tokenStr string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &JWTClaims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			// Once tokens are signed with a key set, whoever holds the
+			// shared secret must not be able to mint them, except during
+			// an explicit grace period
+			if keySet != nil && (!acceptSecret || len(jwtSecret) == 0) {
+				return nil, errors.New("HS256 tokens are not accepted")
+			}
+			return jwtSecret, nil
+		}
+		if keySet == nil {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return jwtSecret, nil
-	})
+		return keySet.verificationKey(token)
+	}, jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}))
 	if err != nil {
 		return nil, fmt.Errorf("token parse error: %w", err)
 	}
@@ -56,3 +94,9 @@ func validateJWT(tokenStr string) (*JWTClaims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// HasTwoFactor reports whether the token's session was started with a
+// second factor.
+func (c *JWTClaims) HasTwoFactor() bool {
+	return slices.Contains(c.AMR, AMRTwoFactor)
+}
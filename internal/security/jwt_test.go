@@ -22,8 +22,9 @@ func TestGenerateAndValidateJWT(t *testing.T) {
 	username := "Nyashaa"
 	role := "admin"
 	email := "nyashahama45@gmail.com"
+	sessionID := uuid.New()
 
-	token, err := GenerateJWT(userID, username, role, email)
+	token, err := GenerateJWT(userID, username, role, email, 3, sessionID)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -33,8 +34,17 @@ func TestGenerateAndValidateJWT(t *testing.T) {
 	assert.Equal(t, username, claims.Username)
 	assert.Equal(t, role, claims.Role)
 	assert.Equal(t, email, claims.Email)
+	assert.Equal(t, 3, claims.TokenVersion)
+	assert.Equal(t, sessionID.String(), claims.SessionID)
 
-	assert.WithinDuration(t, time.Now().Add(24*time.Hour), claims.ExpiresAt.Time, time.Minute)
+	assert.WithinDuration(t, time.Now().Add(AccessTokenTTL), claims.ExpiresAt.Time, time.Minute)
+	assert.False(t, claims.HasTwoFactor())
+
+	token, err = GenerateJWT(userID, username, role, email, 3, sessionID, AMRTwoFactor)
+	assert.NoError(t, err)
+	claims, err = ValidateJWT(token)
+	assert.NoError(t, err)
+	assert.True(t, claims.HasTwoFactor())
 }
 
 func TestValidateJWT_InvalidToken(t *testing.T) {
@@ -95,7 +105,7 @@ func TestValidateJWT_DifferentSecret(t *testing.T) {
 	jwtSecret = []byte(os.Getenv("JWT_SECRET"))
 
 	userID := uuid.New()
-	token, err := GenerateJWT(userID, "testuser", "user", "test@example.com")
+	token, err := GenerateJWT(userID, "testuser", "user", "test@example.com", 0, uuid.New())
 	assert.NoError(t, err)
 
 	// Try to validate with different secret
@@ -111,7 +121,7 @@ func TestJWTClaims_Structure(t *testing.T) {
 	setupTestEnv()
 
 	userID := uuid.New()
-	token, err := GenerateJWT(userID, "testuser", "admin", "test@example.com")
+	token, err := GenerateJWT(userID, "testuser", "admin", "test@example.com", 0, uuid.New())
 	assert.NoError(t, err)
 
 	claims, err := ValidateJWT(token)
@@ -140,7 +150,7 @@ func TestValidateJWT_EmptySecret(t *testing.T) {
 	os.Setenv("JWT_SECRET", "valid-secret")
 	jwtSecret = []byte("valid-secret")
 	userID := uuid.New()
-	token, err := GenerateJWT(userID, "testuser", "user", "test@example.com")
+	token, err := GenerateJWT(userID, "testuser", "user", "test@example.com", 0, uuid.New())
 	assert.NoError(t, err)
 
 	// Now set empty secret and try to validate
@@ -165,7 +175,7 @@ func TestGenerateJWT_WithEmptySecret(t *testing.T) {
 	userID := uuid.New()
 
 	// This should not error even with empty secret
-	token, err := GenerateJWT(userID, "testuser", "user", "test@example.com")
+	token, err := GenerateJWT(userID, "testuser", "user", "test@example.com", 0, uuid.New())
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -184,7 +194,7 @@ func TestJWT_TokenFormat(t *testing.T) {
 
 	fixedUUID, _ := uuid.Parse("12470f7b-f5ae-431c-b2fc-81d7147614f6")
 
-	token, err := GenerateJWT(fixedUUID, "Nyashaa", "admin", "nyashahama45@gmail.com")
+	token, err := GenerateJWT(fixedUUID, "Nyashaa", "admin", "nyashahama45@gmail.com", 0, uuid.New())
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -0,0 +1,237 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is an asymmetric key that access tokens are signed or verified
+// with. Tokens name the key that signed them in their kid header.
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	// Private is nil for keys that are only used to verify tokens
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// KeySet holds the keys tokens are verified with, one of which signs new
+// tokens. Keeping a retired key in the set lets tokens it signed run out
+// their lifetime after rotation.
+type KeySet struct {
+	signing *SigningKey
+	keys    map[string]*SigningKey
+}
+
+// NewKeySet builds a key set that signs with the key named signingID.
+func NewKeySet(signingID string, keys ...SigningKey) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*SigningKey, len(keys))}
+	for i := range keys {
+		key := &keys[i]
+		if key.ID == "" {
+			return nil, errors.New("signing key has no ID")
+		}
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate signing key ID %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+	signing, ok := set.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key ID %q", signingID)
+	}
+	if signing.Private == nil {
+		return nil, fmt.Errorf("signing key %q has no private key", signingID)
+	}
+	set.signing = signing
+	return set, nil
+}
+
+// keySet, when set, signs and verifies access tokens. Without it tokens are
+// signed with the HS256 JWT_SECRET.
+var keySet *KeySet
+
+// acceptSecret keeps HS256 tokens valid alongside a key set
+var acceptSecret bool
+
+// UseKeySet switches token signing to the asymmetric keys in set. Tokens
+// signed with JWT_SECRET are rejected from then on, unless
+// AcceptSecretTokens allows them. Passing nil goes back to JWT_SECRET.
+func UseKeySet(set *KeySet) {
+	keySet = set
+}
+
+// AcceptSecretTokens sets whether tokens signed with JWT_SECRET are still
+// accepted while a key set is in use, so that sessions can survive the
+// switch to asymmetric keys for a grace period.
+func AcceptSecretTokens(accept bool) {
+	acceptSecret = accept
+}
+
+// UseSecret replaces the HS256 secret, which is otherwise read from
+// JWT_SECRET when the program starts.
+func UseSecret(secret []byte) {
+	jwtSecret = secret
+}
+
+// ParseKeyPEM reads a PEM encoded RSA or Ed25519 key. Private keys may be
+// PKCS #8 or PKCS #1; public keys are PKIX.
+func ParseKeyPEM(id string, data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("key %q: no PEM data", id)
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return SigningKey{}, fmt.Errorf("key %q: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	key := SigningKey{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodEdDSA, k
+	default:
+		return SigningKey{}, fmt.Errorf("key %q: unsupported key type %T", id, parsed)
+	}
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519 and EC
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// PublicKey decodes an RSA, P-256 or Ed25519 key.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := func(field, value string) ([]byte, error) {
+		b, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("key %q: invalid %s", k.KeyID, field)
+		}
+		return b, nil
+	}
+
+	switch {
+	case k.KeyType == "RSA":
+		n, err := decode("n", k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode("e", k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.KeyType == "EC" && k.Curve == "P-256":
+		x, err := decode("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode("y", k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("key %q: point is not on the curve", k.KeyID)
+		}
+		return pub, nil
+	case k.KeyType == "OKP" && k.Curve == "Ed25519":
+		x, err := decode("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("key %q: invalid x", k.KeyID)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("key %q: unsupported key type %s %s", k.KeyID, k.KeyType, k.Curve)
+	}
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every key in the set, ordered by ID, for
+// other services to verify tokens with.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	if s == nil {
+		return jwks
+	}
+	for _, key := range s.keys {
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID })
+	return jwks
+}
+
+// verificationKey returns the public key a token names in its kid header,
+// checking that the token was signed with that key's algorithm.
+func (s *KeySet) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.Public, nil
+}
@@ -0,0 +1,211 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRSAKey(t *testing.T, id string) SigningKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return SigningKey{ID: id, Method: jwt.SigningMethodRS256, Private: private, Public: &private.PublicKey}
+}
+
+func newEd25519Key(t *testing.T, id string) SigningKey {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return SigningKey{ID: id, Method: jwt.SigningMethodEdDSA, Private: private, Public: public}
+}
+
+func useKeySet(t *testing.T, signingID string, keys ...SigningKey) {
+	t.Helper()
+	set, err := NewKeySet(signingID, keys...)
+	require.NoError(t, err)
+	UseKeySet(set)
+	t.Cleanup(func() { UseKeySet(nil) })
+}
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	setupTestEnv()
+
+	for _, key := range []SigningKey{newRSAKey(t, "rsa-1"), newEd25519Key(t, "ed-1")} {
+		t.Run(key.Method.Alg(), func(t *testing.T) {
+			useKeySet(t, key.ID, key)
+			userID := uuid.New()
+
+			token, err := GenerateJWT(userID, "testuser", "user", "test@example.com", 0, uuid.New())
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+			assert.Equal(t, key.Method.Alg(), parsed.Header["alg"])
+
+			claims, err := ValidateJWT(token)
+			require.NoError(t, err)
+			assert.Equal(t, userID.String(), claims.UserID)
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	setupTestEnv()
+	oldKey, newKey := newRSAKey(t, "2025-01"), newEd25519Key(t, "2025-06")
+
+	useKeySet(t, oldKey.ID, oldKey, newKey)
+	oldToken, err := GenerateJWT(uuid.New(), "testuser", "user", "test@example.com", 0, uuid.New())
+	require.NoError(t, err)
+
+	// After switching, tokens from the old key are accepted until it is dropped
+	useKeySet(t, newKey.ID, oldKey, newKey)
+	_, err = ValidateJWT(oldToken)
+	assert.NoError(t, err)
+
+	useKeySet(t, newKey.ID, newKey)
+	_, err = ValidateJWT(oldToken)
+	assert.ErrorContains(t, err, "unknown signing key")
+}
+
+func TestKeySet_RejectsMismatchedAlgorithm(t *testing.T) {
+	setupTestEnv()
+	rsaKey, edKey := newRSAKey(t, "rsa-1"), newEd25519Key(t, "ed-1")
+	useKeySet(t, edKey.ID, rsaKey, edKey)
+
+	// Signed with the RSA key but naming the Ed25519 key
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, JWTClaims{UserID: uuid.New().String()})
+	token.Header["kid"] = edKey.ID
+	signed, err := token.SignedString(rsaKey.Private)
+	require.NoError(t, err)
+
+	_, err = ValidateJWT(signed)
+	assert.ErrorContains(t, err, "unexpected signing method")
+}
+
+func TestKeySet_SecretFallback(t *testing.T) {
+	setupTestEnv()
+	hsToken, err := GenerateJWT(uuid.New(), "testuser", "user", "test@example.com", 0, uuid.New())
+	require.NoError(t, err)
+
+	useKeySet(t, "ed-1", newEd25519Key(t, "ed-1"))
+	_, err = ValidateJWT(hsToken)
+	assert.Error(t, err, "the shared secret no longer mints tokens")
+
+	AcceptSecretTokens(true)
+	defer AcceptSecretTokens(false)
+	_, err = ValidateJWT(hsToken)
+	assert.NoError(t, err, "tokens issued before the switch stay valid during the grace period")
+
+	UseSecret(nil)
+	defer setupTestEnv()
+	_, err = ValidateJWT(hsToken)
+	assert.Error(t, err)
+}
+
+func TestValidateJWT_RejectsOtherHMACMethods(t *testing.T) {
+	setupTestEnv()
+	claims := JWTClaims{UserID: uuid.New().String(), Role: "admin"}
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodHS384, jwt.SigningMethodHS512} {
+		signed, err := jwt.NewWithClaims(method, claims).SignedString(jwtSecret)
+		require.NoError(t, err)
+
+		_, err = ValidateJWT(signed)
+		assert.Error(t, err, method.Alg())
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey, edKey := newRSAKey(t, "b-rsa"), newEd25519Key(t, "a-ed")
+	set, err := NewKeySet(rsaKey.ID, rsaKey, edKey)
+	require.NoError(t, err)
+
+	jwks := set.JWKS()
+
+	require.Len(t, jwks.Keys, 2)
+	ed, rsaJWK := jwks.Keys[0], jwks.Keys[1]
+	assert.Equal(t, JWK{KeyType: "OKP", KeyID: "a-ed", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: ed.X}, ed)
+	assert.NotEmpty(t, ed.X)
+	assert.Equal(t, "RSA", rsaJWK.KeyType)
+	assert.Equal(t, "RS256", rsaJWK.Algorithm)
+	assert.Equal(t, "AQAB", rsaJWK.E)
+	assert.NotEmpty(t, rsaJWK.N)
+
+	assert.Empty(t, (*KeySet)(nil).JWKS().Keys)
+}
+
+func TestJWK_PublicKey(t *testing.T) {
+	rsaKey, edKey := newRSAKey(t, "rsa"), newEd25519Key(t, "ed")
+	set, err := NewKeySet(rsaKey.ID, rsaKey, edKey)
+	require.NoError(t, err)
+
+	for _, jwk := range set.JWKS().Keys {
+		public, err := jwk.PublicKey()
+		require.NoError(t, err)
+		assert.Equal(t, set.keys[jwk.KeyID].Public, public, jwk.KeyID)
+	}
+
+	_, err = JWK{KeyID: "bad", KeyType: "OKP", Curve: "Ed25519", X: "AQID"}.PublicKey()
+	assert.Error(t, err)
+	_, err = JWK{KeyID: "oct", KeyType: "oct"}.PublicKey()
+	assert.Error(t, err)
+}
+
+func TestNewKeySet_Invalid(t *testing.T) {
+	key := newEd25519Key(t, "ed-1")
+	publicOnly := SigningKey{ID: "pub", Method: key.Method, Public: key.Public}
+
+	_, err := NewKeySet("missing", key)
+	assert.Error(t, err)
+	_, err = NewKeySet(key.ID, key, key)
+	assert.Error(t, err)
+	_, err = NewKeySet(publicOnly.ID, publicOnly)
+	assert.Error(t, err)
+}
+
+func TestParseKeyPEM(t *testing.T) {
+	rsaKey, edKey := newRSAKey(t, ""), newEd25519Key(t, "")
+	der := func(der []byte, err error) []byte {
+		require.NoError(t, err)
+		return der
+	}
+	encode := func(blockType string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	}
+
+	tests := []struct {
+		name        string
+		pem         []byte
+		wantAlg     string
+		wantPrivate bool
+	}{
+		{"PKCS #8 RSA", encode("PRIVATE KEY", der(x509.MarshalPKCS8PrivateKey(rsaKey.Private))), "RS256", true},
+		{"PKCS #1 RSA", encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey.Private.(*rsa.PrivateKey))), "RS256", true},
+		{"PKCS #8 Ed25519", encode("PRIVATE KEY", der(x509.MarshalPKCS8PrivateKey(edKey.Private))), "EdDSA", true},
+		{"PKIX Ed25519", encode("PUBLIC KEY", der(x509.MarshalPKIXPublicKey(edKey.Public))), "EdDSA", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKeyPEM("k1", tt.pem)
+
+			require.NoError(t, err)
+			assert.Equal(t, "k1", key.ID)
+			assert.Equal(t, tt.wantAlg, key.Method.Alg())
+			assert.Equal(t, tt.wantPrivate, key.Private != nil)
+			assert.NotNil(t, key.Public)
+		})
+	}
+
+	_, err := ParseKeyPEM("k1", []byte("not a key"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteReceipt_SignAndVerify(t *testing.T) {
+	UseReceiptSecret([]byte("test-receipt-secret"))
+
+	receipt := VoteReceipt{
+		VoteID:     uuid.New(),
+		CategoryID: uuid.New(),
+		Nominees:   []uuid.UUID{uuid.New()},
+		CastAt:     time.Date(2025, 3, 1, 20, 0, 0, 123456789, time.UTC),
+	}
+
+	code := SignVoteReceipt(receipt)
+	assert.NotContains(t, code, receipt.Nominees[0].String())
+
+	voteID, signature, err := ParseVoteReceipt(code)
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.VoteID, voteID)
+
+	// The database only keeps microseconds
+	stored := receipt
+	stored.CastAt = receipt.CastAt.Truncate(time.Microsecond)
+	assert.True(t, VerifyVoteReceipt(stored, signature))
+
+	changed := receipt
+	changed.Nominees = []uuid.UUID{uuid.New()}
+	assert.False(t, VerifyVoteReceipt(changed, signature))
+
+	// Signing keys for tokens can change without touching receipts
+	UseSecret([]byte("another-jwt-secret"))
+	assert.True(t, VerifyVoteReceipt(stored, signature))
+
+	UseReceiptSecret([]byte("another-secret"))
+	assert.False(t, VerifyVoteReceipt(stored, signature))
+}
+
+func TestParseVoteReceipt_Malformed(t *testing.T) {
+	for _, code := range []string{
+		"",
+		"no-separator",
+		"not-a-uuid.AAAA",
+		uuid.NewString() + ".!!!",
+		uuid.NewString() + ".c2hvcnQ",
+	} {
+		_, _, err := ParseVoteReceipt(code)
+		assert.ErrorIs(t, err, ErrMalformedReceipt, code)
+	}
+}
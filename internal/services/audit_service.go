@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/fingerprint"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+)
+
+const (
+	defaultAdminActionPageSize = 50
+	maxAdminActionPageSize     = 200
+)
+
+var ErrInvalidAuditQuery = errors.New("invalid audit query")
+
+// AuditService enforces the retention period of request audit records and
+// keeps the log of staff actions
+type AuditService interface {
+	Purge(ctx context.Context) (int64, error)
+	Run(ctx context.Context, interval time.Duration)
+	RecordAdminAction(ctx context.Context, action *models.AdminAction) error
+	ListAdminActions(ctx context.Context, filter repositories.AdminActionFilter, page, pageSize int) (*AdminActionPage, error)
+}
+
+// AdminActionPage is one page of the admin audit log, newest first.
+type AdminActionPage struct {
+	Actions  []models.AdminAction
+	Page     int
+	PageSize int
+	Total    int64
+}
+
+type auditService struct {
+	auditRepo repositories.AuditRepository
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewAuditService(auditRepo repositories.AuditRepository, retention time.Duration) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Purge deletes audit records older than the retention period.
+func (s *auditService) Purge(ctx context.Context) (int64, error) {
+	purged, err := s.auditRepo.PurgeBefore(ctx, s.now().Add(-s.retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge audit records: %w", err)
+	}
+	return purged, nil
+}
+
+// Run purges expired audit records now and then every interval until ctx is
+// done.
+func (s *auditService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.Purge(ctx)
+		if err != nil {
+			log.Printf("audit purge failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("audit purge removed %d records", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecordAdminAction adds an action to the admin audit log. Admin actions are
+// not purged.
+func (s *auditService) RecordAdminAction(ctx context.Context, action *models.AdminAction) error {
+	if action.ActionID == uuid.Nil {
+		action.ActionID = uuid.New()
+	}
+	if err := s.auditRepo.RecordAdminAction(ctx, action); err != nil {
+		return fmt.Errorf("failed to record admin action: %w", err)
+	}
+	return nil
+}
+
+// ListAdminActions returns page, counted from 1, of the admin actions
+// matching filter. A zero page or pageSize picks the first page or the
+// default size; pages are at most maxAdminActionPageSize long.
+func (s *auditService) ListAdminActions(ctx context.Context, filter repositories.AdminActionFilter, page, pageSize int) (*AdminActionPage, error) {
+	switch {
+	case page < 0, pageSize < 0:
+		return nil, fmt.Errorf("%w: page and page size must not be negative", ErrInvalidAuditQuery)
+	case !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To):
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidAuditQuery)
+	}
+	page = max(page, 1)
+	if pageSize == 0 {
+		pageSize = defaultAdminActionPageSize
+	}
+	pageSize = min(pageSize, maxAdminActionPageSize)
+
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+	actions, total, err := s.auditRepo.ListAdminActions(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin actions: %w", err)
+	}
+	return &AdminActionPage{Actions: actions, Page: page, PageSize: pageSize, Total: total}, nil
+}
+
+// recordVoteAudit stores the fingerprint of the request casting a vote.
+// Votes cast outside an HTTP request carry no fingerprint and are not audited.
+func recordVoteAudit(ctx context.Context, audits repositories.AuditRepository, voteID uuid.UUID) error {
+	fp, ok := fingerprint.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return audits.RecordVote(ctx, &models.VoteAudit{
+		VoteID:    voteID,
+		IPAddress: fp.IP,
+		UserAgent: fp.UserAgent,
+		RequestID: fp.RequestID,
+	})
+}
+
+// recordRegistrationAudit stores the fingerprint of the request registering
+// an account.
+func recordRegistrationAudit(ctx context.Context, audits repositories.AuditRepository, userID uuid.UUID) error {
+	fp, ok := fingerprint.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return audits.RecordRegistration(ctx, &models.RegistrationAudit{
+		UserID:    userID,
+		IPAddress: fp.IP,
+		UserAgent: fp.UserAgent,
+		RequestID: fp.RequestID,
+	})
+}
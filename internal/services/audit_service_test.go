@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/fingerprint"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) RecordVote(ctx context.Context, audit *models.VoteAudit) error {
+	args := m.Called(ctx, audit)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) RecordRegistration(ctx context.Context, audit *models.RegistrationAudit) error {
+	args := m.Called(ctx, audit)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) PurgeBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAuditRepository) RecordAdminAction(ctx context.Context, action *models.AdminAction) error {
+	args := m.Called(ctx, action)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) ListAdminActions(ctx context.Context, filter repositories.AdminActionFilter) ([]models.AdminAction, int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.AdminAction), args.Get(1).(int64), args.Error(2)
+}
+
+var testFingerprint = fingerprint.Fingerprint{IP: "203.0.113.7", UserAgent: "Mozilla/5.0", RequestID: "req-1"}
+
+func TestAuditService_Purge(t *testing.T) {
+	auditRepo := new(MockAuditRepository)
+	service := NewAuditService(auditRepo, 90*24*time.Hour).(*auditService)
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	auditRepo.On("PurgeBefore", mock.Anything, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)).Return(int64(4), nil)
+
+	purged, err := service.Purge(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), purged)
+}
+
+func TestAuditService_ListAdminActions(t *testing.T) {
+	actorID := uuid.New()
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		filter     repositories.AdminActionFilter
+		page, size int
+		wantLimit  int
+		wantOffset int
+		wantErr    error
+	}{
+		{"defaults", repositories.AdminActionFilter{}, 0, 0, defaultAdminActionPageSize, 0, nil},
+		{"later page", repositories.AdminActionFilter{ActorID: actorID}, 3, 20, 20, 40, nil},
+		{"page size capped", repositories.AdminActionFilter{}, 1, 1000, maxAdminActionPageSize, 0, nil},
+		{"negative page", repositories.AdminActionFilter{}, -1, 0, 0, 0, ErrInvalidAuditQuery},
+		{"empty range", repositories.AdminActionFilter{From: from, To: from}, 1, 10, 0, 0, ErrInvalidAuditQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditRepo := new(MockAuditRepository)
+			service := NewAuditService(auditRepo, 90*24*time.Hour)
+			want := tt.filter
+			want.Limit, want.Offset = tt.wantLimit, tt.wantOffset
+			actions := []models.AdminAction{{ActorID: actorID, Action: "PUT /api/nominees/:id"}}
+			auditRepo.On("ListAdminActions", mock.Anything, want).Return(actions, int64(41), nil)
+
+			result, err := service.ListAdminActions(context.Background(), tt.filter, tt.page, tt.size)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				auditRepo.AssertNotCalled(t, "ListAdminActions", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, actions, result.Actions)
+			assert.Equal(t, tt.wantLimit, result.PageSize)
+			assert.Equal(t, int64(41), result.Total)
+		})
+	}
+}
+
+func TestUserService_RegisterRecordsFingerprint(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	auditRepo := new(MockAuditRepository)
+	editionRepo := new(MockEditionRepository)
+	editionRepo.On("GetActive", mock.Anything).Return(nil, nil)
+	mockRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(nil, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil)
+	auditRepo.On("RecordRegistration", mock.Anything, mock.MatchedBy(func(audit *models.RegistrationAudit) bool {
+		return audit.IPAddress == testFingerprint.IP &&
+			audit.UserAgent == testFingerprint.UserAgent &&
+			audit.RequestID == testFingerprint.RequestID
+	})).Return(nil)
+	service := newTestUserService(mockRepo, editionRepo, &mockUnitOfWork{users: mockRepo, audits: auditRepo})
+
+	ctx := fingerprint.NewContext(context.Background(), testFingerprint)
+	user, err := service.Register(ctx, "testuser", "test@example.com", "ValidPass123!")
+
+	assert.NoError(t, err)
+	auditRepo.AssertCalled(t, "RecordRegistration", mock.Anything, mock.MatchedBy(func(audit *models.RegistrationAudit) bool {
+		return audit.UserID == user.UserID
+	}))
+}
+
+func TestVotingMechanismService_CastVoteRecordsFingerprint(t *testing.T) {
+	userID, categoryID, nomineeID := uuid.New(), uuid.New(), uuid.New()
+	voteRepo, userRepo, categoryRepo, service := setupVoteTest()
+	auditRepo := service.uow.(*mockUnitOfWork).audits
+	categoryRepo.On("GetByID", mock.Anything, categoryID).Return(&models.Category{CategoryID: categoryID, EditionID: uuid.New()}, nil)
+	userRepo.On("GetByIDForUpdate", mock.Anything, userID).Return(&models.User{UserID: userID, AvailableVotes: 1, EmailVerifiedAt: &votingNow}, nil)
+	userRepo.On("DecrementAvailableVotes", mock.Anything, userID).Return(nil)
+	voteRepo.On("GetByUserAndCategory", mock.Anything, userID, categoryID).Return(nil, nil)
+	voteRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Vote")).Return(nil)
+	auditRepo.On("RecordVote", mock.Anything, mock.AnythingOfType("*models.VoteAudit")).Return(nil)
+
+	ctx := fingerprint.NewContext(context.Background(), testFingerprint)
+	vote, err := service.CastVote(ctx, userID, nomineeID, categoryID)
+
+	assert.NoError(t, err)
+	auditRepo.AssertCalled(t, "RecordVote", mock.Anything, &models.VoteAudit{
+		VoteID:    vote.VoteID,
+		IPAddress: testFingerprint.IP,
+		UserAgent: testFingerprint.UserAgent,
+		RequestID: testFingerprint.RequestID,
+	})
+}
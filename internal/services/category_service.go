@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/models"
@@ -13,30 +14,55 @@ import (
 var (
 	ErrCategoryNotFound = errors.New("category not found")
 	ErrCategoryExists   = errors.New("category name already exists")
+	ErrCategoryHasVotes = errors.New("category has votes")
+
+	ErrInvalidVotingWindow = errors.New("voting window must close after it opens")
+	ErrInvalidResultsState = errors.New("invalid results state")
+	ErrInvalidVotingMethod = errors.New("invalid voting method")
+	ErrInvalidJuryWeight   = errors.New("jury weight must be between 0 and 1")
+
+	ErrInvalidVoteChangeLimits = errors.New("vote change limits must not be negative")
 )
 
 // CategoryService handles category operations
 type CategoryService interface {
-	CreateCategory(ctx context.Context, name, description string) (*models.Category, error)
+	CreateCategory(ctx context.Context, editionID uuid.UUID, name, description string) (*models.Category, error)
 	UpdateCategory(ctx context.Context, categoryID uuid.UUID, name, description string) (*models.Category, error)
-	DeleteCategory(ctx context.Context, categoryID uuid.UUID) error
+	DeleteCategory(ctx context.Context, categoryID uuid.UUID, force bool) error
+	RestoreCategory(ctx context.Context, categoryID uuid.UUID) (*models.Category, error)
 	GetCategoryDetails(ctx context.Context, categoryID uuid.UUID) (*models.Category, error)
 	ListAllCategories(ctx context.Context) ([]models.Category, error)
+	ListEditionCategories(ctx context.Context, editionID uuid.UUID) ([]models.Category, error)
 	ListActiveCategories(ctx context.Context) ([]models.Category, error)
+	ScheduleVotingWindow(ctx context.Context, categoryID uuid.UUID, opensAt, closesAt *time.Time) (*models.Category, error)
+	ExtendVotingWindow(ctx context.Context, categoryID uuid.UUID, closesAt time.Time) (*models.Category, error)
+	CloseVoting(ctx context.Context, categoryID uuid.UUID) (*models.Category, error)
+	SetResultsState(ctx context.Context, categoryID uuid.UUID, state string) (*models.Category, error)
+	PublishResults(ctx context.Context, categoryID uuid.UUID, publishAt *time.Time) (*models.Category, error)
+	SetVotingMethod(ctx context.Context, categoryID uuid.UUID, method string) (*models.Category, error)
+	SetJuryWeight(ctx context.Context, categoryID uuid.UUID, juryWeight float64) (*models.Category, error)
+	SetVoteChangeLimits(ctx context.Context, categoryID uuid.UUID, maxChanges *int, cooldownSeconds int) (*models.Category, error)
 }
 
 type categoryService struct {
-	repo repositories.CategoryRepository
+	repo        repositories.CategoryRepository
+	editionRepo repositories.EditionRepository
 }
 
-func NewCategoryService(repo repositories.CategoryRepository) CategoryService {
-	return &categoryService{repo: repo}
+func NewCategoryService(repo repositories.CategoryRepository, editionRepo repositories.EditionRepository) CategoryService {
+	return &categoryService{repo: repo, editionRepo: editionRepo}
 }
 
-func (s *categoryService) CreateCategory(ctx context.Context, name, description string) (*models.Category, error) {
-	// Check for existing category
+// CreateCategory adds a category to editionID, or to the active edition when
+// editionID is uuid.Nil. Its results start in admin preview.
+func (s *categoryService) CreateCategory(ctx context.Context, editionID uuid.UUID, name, description string) (*models.Category, error) {
+	edition, err := resolveEdition(ctx, s.editionRepo, editionID)
+	if err != nil {
+		return nil, err
+	}
 
-	existing, err := s.repo.GetByName(ctx, name)
+	// Check for existing category within the edition
+	existing, err := s.repo.GetByName(ctx, edition.EditionID, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check category name: %w", err)
 	}
@@ -45,9 +71,11 @@ func (s *categoryService) CreateCategory(ctx context.Context, name, description
 	}
 
 	category := &models.Category{
-		CategoryID:  uuid.New(),
-		Name:        name,
-		Description: description,
+		CategoryID:   uuid.New(),
+		EditionID:    edition.EditionID,
+		Name:         name,
+		Description:  description,
+		ResultsState: models.ResultsPreview,
 	}
 
 	if err := s.repo.Create(ctx, category); err != nil {
@@ -67,7 +95,7 @@ func (s *categoryService) UpdateCategory(ctx context.Context, categoryID uuid.UU
 
 	// Check for name conflict if name changed
 	if name != "" && name != category.Name {
-		existing, err := s.repo.GetByName(ctx, name)
+		existing, err := s.repo.GetByName(ctx, category.EditionID, name)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check category name: %w", err)
 		}
@@ -87,8 +115,56 @@ func (s *categoryService) UpdateCategory(ctx context.Context, categoryID uuid.UU
 	return category, nil
 }
 
-func (s *categoryService) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
-	return s.repo.Delete(ctx, categoryID)
+// DeleteCategory soft deletes a category. A category with votes is only
+// deleted when force is set; its votes are kept for when it is restored.
+func (s *categoryService) DeleteCategory(ctx context.Context, categoryID uuid.UUID, force bool) error {
+	if _, err := s.getCategory(ctx, categoryID); err != nil {
+		return err
+	}
+
+	if !force {
+		votes, err := s.repo.CountVotes(ctx, categoryID)
+		if err != nil {
+			return fmt.Errorf("failed to count category votes: %w", err)
+		}
+		if votes > 0 {
+			return fmt.Errorf("%w: %d votes would stop counting", ErrCategoryHasVotes, votes)
+		}
+	}
+
+	if err := s.repo.Delete(ctx, categoryID); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	return nil
+}
+
+// RestoreCategory brings back a deleted category with its nominees and
+// votes, unless its name has been reused in the edition since.
+func (s *categoryService) RestoreCategory(ctx context.Context, categoryID uuid.UUID) (*models.Category, error) {
+	deleted, err := s.repo.GetDeletedByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	if deleted == nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	existing, err := s.repo.GetByName(ctx, deleted.EditionID, deleted.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check category name: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrCategoryExists
+	}
+
+	restored, err := s.repo.Restore(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore category: %w", err)
+	}
+	if !restored {
+		return nil, ErrCategoryNotFound
+	}
+	return s.getCategory(ctx, categoryID)
 }
 
 func (s *categoryService) GetCategoryDetails(ctx context.Context, categoryID uuid.UUID) (*models.Category, error) {
@@ -106,6 +182,198 @@ func (s *categoryService) ListAllCategories(ctx context.Context) ([]models.Categ
 	return s.repo.GetAll(ctx)
 }
 
+// ListEditionCategories lists the categories of editionID, or of the active
+// edition when editionID is uuid.Nil.
+func (s *categoryService) ListEditionCategories(ctx context.Context, editionID uuid.UUID) ([]models.Category, error) {
+	edition, err := resolveEdition(ctx, s.editionRepo, editionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByEdition(ctx, edition.EditionID)
+}
+
 func (s *categoryService) ListActiveCategories(ctx context.Context) ([]models.Category, error) {
     return s.repo.GetActive(ctx)
 }
+
+func (s *categoryService) ScheduleVotingWindow(ctx context.Context, categoryID uuid.UUID, opensAt, closesAt *time.Time) (*models.Category, error) {
+	if opensAt != nil && closesAt != nil && !closesAt.After(*opensAt) {
+		return nil, ErrInvalidVotingWindow
+	}
+
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	category.VotingOpensAt = opensAt
+	category.VotingClosesAt = closesAt
+
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to schedule voting window: %w", err)
+	}
+	return category, nil
+}
+
+func (s *categoryService) ExtendVotingWindow(ctx context.Context, categoryID uuid.UUID, closesAt time.Time) (*models.Category, error) {
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// An extension may only push the deadline later, never pull it earlier
+	if !closesAt.After(time.Now()) {
+		return nil, ErrInvalidVotingWindow
+	}
+	if category.VotingClosesAt != nil && !closesAt.After(*category.VotingClosesAt) {
+		return nil, ErrInvalidVotingWindow
+	}
+	if category.VotingOpensAt != nil && !closesAt.After(*category.VotingOpensAt) {
+		return nil, ErrInvalidVotingWindow
+	}
+
+	category.VotingClosesAt = &closesAt
+
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to extend voting window: %w", err)
+	}
+	return category, nil
+}
+
+func (s *categoryService) CloseVoting(ctx context.Context, categoryID uuid.UUID) (*models.Category, error) {
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if category.VotingClosesAt != nil && !category.VotingClosesAt.After(now) {
+		// Already closed, nothing to do
+		return category, nil
+	}
+
+	// Closing a window that has not opened yet drops the pending opening time
+	if category.VotingOpensAt != nil && !category.VotingOpensAt.Before(now) {
+		category.VotingOpensAt = nil
+	}
+	category.VotingClosesAt = &now
+
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to close voting: %w", err)
+	}
+	return category, nil
+}
+
+// SetResultsState moves results back to hidden or admin preview. Any pending
+// scheduled publication is cancelled; use PublishResults to publish.
+func (s *categoryService) SetResultsState(ctx context.Context, categoryID uuid.UUID, state string) (*models.Category, error) {
+	if state != models.ResultsHidden && state != models.ResultsPreview {
+		return nil, ErrInvalidResultsState
+	}
+
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	category.ResultsState = state
+	category.ResultsPublishAt = nil
+
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to update results state: %w", err)
+	}
+	return category, nil
+}
+
+// PublishResults publishes immediately when publishAt is nil or already past,
+// otherwise it schedules publication and leaves the current state in place
+// until then.
+func (s *categoryService) PublishResults(ctx context.Context, categoryID uuid.UUID, publishAt *time.Time) (*models.Category, error) {
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if publishAt == nil || !publishAt.After(time.Now()) {
+		category.ResultsState = models.ResultsPublished
+		category.ResultsPublishAt = nil
+	} else {
+		category.ResultsPublishAt = publishAt
+	}
+
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to publish results: %w", err)
+	}
+	return category, nil
+}
+
+// SetVotingMethod switches the category between single picks and ranked
+// ballots. Votes already cast stay valid: a single pick counts as a ballot
+// with one preference, and a ranked ballot's first preference as a pick.
+func (s *categoryService) SetVotingMethod(ctx context.Context, categoryID uuid.UUID, method string) (*models.Category, error) {
+	if method != models.VotingMethodSingle && method != models.VotingMethodRanked {
+		return nil, ErrInvalidVotingMethod
+	}
+
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	category.VotingMethod = method
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to update voting method: %w", err)
+	}
+	return category, nil
+}
+
+// SetJuryWeight sets the share of the category's result decided by the jury,
+// e.g. 0.5 for a 50/50 blend with the public vote. Zero disables blending.
+func (s *categoryService) SetJuryWeight(ctx context.Context, categoryID uuid.UUID, juryWeight float64) (*models.Category, error) {
+	if juryWeight < 0 || juryWeight > 1 {
+		return nil, ErrInvalidJuryWeight
+	}
+
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	category.JuryWeight = juryWeight
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to update jury weight: %w", err)
+	}
+	return category, nil
+}
+
+// SetVoteChangeLimits caps how many times each vote in the category may be
+// changed and how long voters must wait between changes. A nil maxChanges
+// removes the cap.
+func (s *categoryService) SetVoteChangeLimits(ctx context.Context, categoryID uuid.UUID, maxChanges *int, cooldownSeconds int) (*models.Category, error) {
+	if (maxChanges != nil && *maxChanges < 0) || cooldownSeconds < 0 {
+		return nil, ErrInvalidVoteChangeLimits
+	}
+
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	category.MaxVoteChanges = maxChanges
+	category.VoteChangeCooldownSeconds = cooldownSeconds
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to update vote change limits: %w", err)
+	}
+	return category, nil
+}
+
+func (s *categoryService) getCategory(ctx context.Context, categoryID uuid.UUID) (*models.Category, error) {
+	category, err := s.repo.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+	return category, nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCategoryService_DeleteCategory(t *testing.T) {
+	categoryID := uuid.New()
+	setup := func(votes int64) (*MockCategoryRepository, CategoryService) {
+		repo := new(MockCategoryRepository)
+		repo.On("GetByID", mock.Anything, categoryID).Return(&models.Category{CategoryID: categoryID}, nil)
+		repo.On("CountVotes", mock.Anything, categoryID).Return(votes, nil)
+		repo.On("Delete", mock.Anything, categoryID).Return(nil)
+		return repo, NewCategoryService(repo, new(MockEditionRepository))
+	}
+
+	t.Run("refuses a category with votes", func(t *testing.T) {
+		repo, service := setup(3)
+
+		err := service.DeleteCategory(context.Background(), categoryID, false)
+
+		assert.ErrorIs(t, err, ErrCategoryHasVotes)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		repo, service := setup(3)
+
+		err := service.DeleteCategory(context.Background(), categoryID, true)
+
+		assert.NoError(t, err)
+		repo.AssertCalled(t, "Delete", mock.Anything, categoryID)
+		repo.AssertNotCalled(t, "CountVotes", mock.Anything, mock.Anything)
+	})
+
+	t.Run("without votes", func(t *testing.T) {
+		repo, service := setup(0)
+
+		err := service.DeleteCategory(context.Background(), categoryID, false)
+
+		assert.NoError(t, err)
+		repo.AssertCalled(t, "Delete", mock.Anything, categoryID)
+	})
+}
+
+func TestCategoryService_RestoreCategory(t *testing.T) {
+	deleted := &models.Category{CategoryID: uuid.New(), EditionID: uuid.New(), Name: "Best Album"}
+
+	t.Run("name reused since", func(t *testing.T) {
+		repo := new(MockCategoryRepository)
+		repo.On("GetDeletedByID", mock.Anything, deleted.CategoryID).Return(deleted, nil)
+		repo.On("GetByName", mock.Anything, deleted.EditionID, deleted.Name).Return(&models.Category{CategoryID: uuid.New()}, nil)
+		service := NewCategoryService(repo, new(MockEditionRepository))
+
+		_, err := service.RestoreCategory(context.Background(), deleted.CategoryID)
+
+		assert.ErrorIs(t, err, ErrCategoryExists)
+		repo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+	})
+
+	t.Run("not deleted", func(t *testing.T) {
+		repo := new(MockCategoryRepository)
+		repo.On("GetDeletedByID", mock.Anything, deleted.CategoryID).Return(nil, nil)
+		service := NewCategoryService(repo, new(MockEditionRepository))
+
+		_, err := service.RestoreCategory(context.Background(), deleted.CategoryID)
+
+		assert.ErrorIs(t, err, ErrCategoryNotFound)
+	})
+}
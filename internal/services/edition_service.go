@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+)
+
+var (
+	ErrEditionNotFound = errors.New("edition not found")
+	ErrEditionExists   = errors.New("an edition already exists for that year")
+	ErrEditionInUse    = errors.New("edition still has categories")
+	ErrNoActiveEdition = errors.New("no active edition")
+)
+
+// EditionService manages awards editions (seasons)
+type EditionService interface {
+	CreateEdition(ctx context.Context, name string, year int) (*models.Edition, error)
+	UpdateEdition(ctx context.Context, editionID uuid.UUID, name string, year int) (*models.Edition, error)
+	DeleteEdition(ctx context.Context, editionID uuid.UUID) error
+	ActivateEdition(ctx context.Context, editionID uuid.UUID) (*models.Edition, error)
+	GetEdition(ctx context.Context, editionID uuid.UUID) (*models.Edition, error)
+	GetActiveEdition(ctx context.Context) (*models.Edition, error)
+	ListEditions(ctx context.Context) ([]models.Edition, error)
+}
+
+type editionService struct {
+	repo repositories.EditionRepository
+}
+
+func NewEditionService(repo repositories.EditionRepository) EditionService {
+	return &editionService{repo: repo}
+}
+
+func (s *editionService) CreateEdition(ctx context.Context, name string, year int) (*models.Edition, error) {
+	existing, err := s.repo.GetByYear(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check edition year: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrEditionExists
+	}
+
+	edition := &models.Edition{
+		EditionID:  uuid.New(),
+		Name:       name,
+		Year:       year,
+		VotePolicy: models.VotePolicyFixed,
+		VoteBudget: models.DefaultVoteBudget,
+	}
+
+	if err := s.repo.Create(ctx, edition); err != nil {
+		return nil, fmt.Errorf("failed to create edition: %w", err)
+	}
+	return edition, nil
+}
+
+func (s *editionService) UpdateEdition(ctx context.Context, editionID uuid.UUID, name string, year int) (*models.Edition, error) {
+	edition, err := s.GetEdition(ctx, editionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for year conflict if year changed
+	if year != 0 && year != edition.Year {
+		existing, err := s.repo.GetByYear(ctx, year)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check edition year: %w", err)
+		}
+		if existing != nil {
+			return nil, ErrEditionExists
+		}
+		edition.Year = year
+	}
+
+	if name != "" {
+		edition.Name = name
+	}
+
+	if err := s.repo.Update(ctx, edition); err != nil {
+		return nil, fmt.Errorf("failed to update edition: %w", err)
+	}
+	return edition, nil
+}
+
+func (s *editionService) DeleteEdition(ctx context.Context, editionID uuid.UUID) error {
+	if _, err := s.GetEdition(ctx, editionID); err != nil {
+		return err
+	}
+
+	count, err := s.repo.CountCategories(ctx, editionID)
+	if err != nil {
+		return fmt.Errorf("failed to count edition categories: %w", err)
+	}
+	if count > 0 {
+		return ErrEditionInUse
+	}
+
+	if err := s.repo.Delete(ctx, editionID); err != nil {
+		return fmt.Errorf("failed to delete edition: %w", err)
+	}
+	return nil
+}
+
+func (s *editionService) ActivateEdition(ctx context.Context, editionID uuid.UUID) (*models.Edition, error) {
+	edition, err := s.GetEdition(ctx, editionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetActive(ctx, editionID); err != nil {
+		return nil, fmt.Errorf("failed to activate edition: %w", err)
+	}
+	edition.IsActive = true
+	return edition, nil
+}
+
+func (s *editionService) GetEdition(ctx context.Context, editionID uuid.UUID) (*models.Edition, error) {
+	edition, err := s.repo.GetByID(ctx, editionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edition: %w", err)
+	}
+	if edition == nil {
+		return nil, ErrEditionNotFound
+	}
+	return edition, nil
+}
+
+func (s *editionService) GetActiveEdition(ctx context.Context) (*models.Edition, error) {
+	return resolveEdition(ctx, s.repo, uuid.Nil)
+}
+
+func (s *editionService) ListEditions(ctx context.Context) ([]models.Edition, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// resolveEdition loads editionID, falling back to the active edition when
+// editionID is uuid.Nil.
+func resolveEdition(ctx context.Context, repo repositories.EditionRepository, editionID uuid.UUID) (*models.Edition, error) {
+	if editionID != uuid.Nil {
+		edition, err := repo.GetByID(ctx, editionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get edition: %w", err)
+		}
+		if edition == nil {
+			return nil, ErrEditionNotFound
+		}
+		return edition, nil
+	}
+
+	edition, err := repo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active edition: %w", err)
+	}
+	if edition == nil {
+		return nil, ErrNoActiveEdition
+	}
+	return edition, nil
+}
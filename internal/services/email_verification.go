@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/nyashahama/music-awards/internal/security"
+)
+
+// emailVerificationTTL is how long a verification link stays valid
+const emailVerificationTTL = 48 * time.Hour
+
+var ErrInvalidVerificationToken = errors.New("verification link is invalid or has expired")
+
+// VerifyEmail consumes a verification token and marks its user's email
+// address as verified.
+func (s *userService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	if token == "" {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	now := s.now()
+	var userID uuid.UUID
+	err := s.uow.Do(ctx, func(tx repositories.Tx) error {
+		consumed, err := tx.Tokens().Consume(ctx, models.TokenPurposeEmailVerification, security.HashToken(token), now)
+		if err != nil {
+			return fmt.Errorf("failed to consume verification token: %w", err)
+		}
+		if consumed == nil {
+			return ErrInvalidVerificationToken
+		}
+		userID = consumed.UserID
+		if err := tx.Users().MarkEmailVerified(ctx, userID, now); err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	return user, nil
+}
+
+// ResendVerification emails a new verification link to an unverified
+// account, invalidating any earlier link. It succeeds without sending
+// anything when there is no such account, so callers cannot probe which
+// addresses are registered.
+func (s *userService) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, strings.ToLower(email))
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.IsEmailVerified() {
+		return nil
+	}
+
+	var link string
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		if err := tx.Tokens().Revoke(ctx, user.UserID, models.TokenPurposeEmailVerification, s.now()); err != nil {
+			return fmt.Errorf("failed to revoke verification tokens: %w", err)
+		}
+		link, err = s.issueVerificationToken(ctx, tx.Tokens(), user.UserID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	go s.sendVerificationEmail(user.Email, link)
+	return nil
+}
+
+// issueVerificationToken stores a new verification token for the user and
+// returns the link to email them.
+func (s *userService) issueVerificationToken(ctx context.Context, tokens repositories.TokenRepository, userID uuid.UUID) (string, error) {
+	return s.issueToken(ctx, tokens, userID, models.TokenPurposeEmailVerification, emailVerificationTTL, s.links.VerifyEmail)
+}
+
+// issueToken stores a new single-use token for purpose and returns link with
+// the token appended.
+func (s *userService) issueToken(ctx context.Context, tokens repositories.TokenRepository, userID uuid.UUID, purpose string, ttl time.Duration, link string) (string, error) {
+	token, hash, err := security.NewToken()
+	if err != nil {
+		return "", err
+	}
+	err = tokens.Create(ctx, &models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: s.now().Add(ttl),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store %s token: %w", purpose, err)
+	}
+	return link + "?token=" + url.QueryEscape(token), nil
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/nyashahama/music-awards/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) Create(ctx context.Context, token *models.UserToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) Consume(ctx context.Context, purpose, hash string, now time.Time) (*models.UserToken, error) {
+	args := m.Called(ctx, purpose, hash, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) Revoke(ctx context.Context, userID uuid.UUID, purpose string, now time.Time) error {
+	args := m.Called(ctx, userID, purpose, now)
+	return args.Error(0)
+}
+
+var verificationNow = time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+
+const testVerifyEmailURL = "https://awards.example.com/api/verify-email"
+
+// newTestUserService builds a user service that stores tokens and sessions
+// in uow's repositories, or in permissive mocks if it has none, counts
+// failed logins in memory, and drops emails.
+func newTestUserService(userRepo repositories.UserRepository, editionRepo repositories.EditionRepository, uow *mockUnitOfWork) *userService {
+	if uow.tokens == nil {
+		uow.tokens = new(MockTokenRepository)
+		uow.tokens.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+		uow.tokens.On("Revoke", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	}
+	if uow.sessions == nil {
+		uow.sessions = new(MockSessionRepository)
+		uow.sessions.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	}
+	links := AccountLinks{VerifyEmail: testVerifyEmailURL}
+	service := NewUserService(userRepo, editionRepo, uow.sessions, newMemLoginThrottleRepository(), uow, links).(*userService)
+	service.sendVerificationEmail = func(string, string) {}
+	service.sendPasswordResetEmail = func(string, string) {}
+	service.sendAccountLockedEmail = func(string, time.Time) {}
+	service.now = func() time.Time { return verificationNow }
+	return service
+}
+
+type sentEmail struct {
+	recipient, link string
+}
+
+func captureEmails(service *userService) <-chan sentEmail {
+	sent := make(chan sentEmail, 1)
+	service.sendVerificationEmail = func(recipient, link string) {
+		sent <- sentEmail{recipient, link}
+	}
+	return sent
+}
+
+// tokenFromLink returns the token query parameter of a verification link.
+func tokenFromLink(t *testing.T, link string) string {
+	t.Helper()
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, testVerifyEmailURL, parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	return parsed.Query().Get("token")
+}
+
+func TestUserService_RegisterSendsVerificationEmail(t *testing.T) {
+	userRepo, editionRepo, tokenRepo := new(MockUserRepository), new(MockEditionRepository), new(MockTokenRepository)
+	editionRepo.On("GetActive", mock.Anything).Return(nil, nil)
+	userRepo.On("GetByEmail", mock.Anything, "new@example.com").Return(nil, nil)
+	userRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil)
+	var stored *models.UserToken
+	tokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.UserToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.UserToken) }).
+		Return(nil)
+	service := newTestUserService(userRepo, editionRepo, &mockUnitOfWork{users: userRepo, audits: new(MockAuditRepository), tokens: tokenRepo})
+	sent := captureEmails(service)
+
+	user, err := service.Register(context.Background(), "newuser", "New@Example.com", "ValidPass123!")
+
+	require.NoError(t, err)
+	assert.False(t, user.IsEmailVerified())
+	email := <-sent
+	assert.Equal(t, "new@example.com", email.recipient)
+	token := tokenFromLink(t, email.link)
+	require.NotNil(t, stored)
+	assert.Equal(t, user.UserID, stored.UserID)
+	assert.Equal(t, models.TokenPurposeEmailVerification, stored.Purpose)
+	assert.Equal(t, security.HashToken(token), stored.TokenHash, "only the hash is stored")
+	assert.Equal(t, verificationNow.Add(emailVerificationTTL), stored.ExpiresAt)
+}
+
+func TestUserService_VerifyEmail(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("valid token", func(t *testing.T) {
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		tokenRepo.On("Consume", mock.Anything, models.TokenPurposeEmailVerification, security.HashToken("abc"), verificationNow).
+			Return(&models.UserToken{UserID: userID}, nil)
+		userRepo.On("MarkEmailVerified", mock.Anything, userID, verificationNow).Return(nil)
+		userRepo.On("GetByID", mock.Anything, userID).Return(&models.User{UserID: userID, EmailVerifiedAt: &verificationNow}, nil)
+
+		user, err := service.VerifyEmail(context.Background(), "abc")
+
+		require.NoError(t, err)
+		assert.True(t, user.IsEmailVerified())
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown, used or expired token", func(t *testing.T) {
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		tokenRepo.On("Consume", mock.Anything, models.TokenPurposeEmailVerification, security.HashToken("abc"), verificationNow).
+			Return(nil, nil)
+
+		_, err := service.VerifyEmail(context.Background(), "abc")
+
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+		userRepo.AssertNotCalled(t, "MarkEmailVerified", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		_, service := setupTest()
+
+		_, err := service.VerifyEmail(context.Background(), "")
+
+		assert.ErrorIs(t, err, ErrInvalidVerificationToken)
+	})
+}
+
+func TestUserService_ResendVerification(t *testing.T) {
+	t.Run("unverified account gets a new link", func(t *testing.T) {
+		user := createTestUser()
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		sent := captureEmails(service)
+		userRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(user, nil)
+		tokenRepo.On("Revoke", mock.Anything, user.UserID, models.TokenPurposeEmailVerification, verificationNow).Return(nil)
+		tokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.UserToken")).Return(nil)
+
+		err := service.ResendVerification(context.Background(), "Test@Example.com")
+
+		require.NoError(t, err)
+		email := <-sent
+		assert.Equal(t, user.Email, email.recipient)
+		assert.NotEmpty(t, tokenFromLink(t, email.link))
+		tokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("verified or unknown accounts are ignored", func(t *testing.T) {
+		verified := createTestUser()
+		verified.EmailVerifiedAt = &verificationNow
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		userRepo.On("GetByEmail", mock.Anything, verified.Email).Return(verified, nil)
+		userRepo.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, nil)
+
+		assert.NoError(t, service.ResendVerification(context.Background(), verified.Email))
+		assert.NoError(t, service.ResendVerification(context.Background(), "nobody@example.com"))
+		tokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_UpdateUserEmailNeedsVerification(t *testing.T) {
+	t.Run("new address", func(t *testing.T) {
+		user := createTestUser()
+		user.EmailVerifiedAt = &verificationNow
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		sent := captureEmails(service)
+		userRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+		userRepo.On("GetByEmail", mock.Anything, "new@example.com").Return(nil, nil)
+		var saved models.User
+		userRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User")).
+			Run(func(args mock.Arguments) { saved = *args.Get(1).(*models.User) }).
+			Return(nil)
+		tokenRepo.On("Revoke", mock.Anything, user.UserID, models.TokenPurposeEmailVerification, verificationNow).Return(nil)
+		tokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.UserToken")).Return(nil)
+
+		updated, err := service.UpdateUser(context.Background(), user.UserID, map[string]any{"email": "New@Example.com"})
+
+		require.NoError(t, err)
+		assert.False(t, updated.IsEmailVerified())
+		assert.Equal(t, "new@example.com", saved.Email)
+		assert.Nil(t, saved.EmailVerifiedAt)
+		email := <-sent
+		assert.Equal(t, "new@example.com", email.recipient)
+		assert.NotEmpty(t, tokenFromLink(t, email.link))
+		tokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("same address", func(t *testing.T) {
+		user := createTestUser()
+		user.EmailVerifiedAt = &verificationNow
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		userRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+		userRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+		userRepo.On("Update", mock.Anything, user).Return(nil)
+
+		updated, err := service.UpdateUser(context.Background(), user.UserID, map[string]any{"email": "TEST@example.com"})
+
+		require.NoError(t, err)
+		assert.True(t, updated.IsEmailVerified())
+		tokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+)
+
+// Fraud detection rules, recorded on each flag
+const (
+	FraudRuleNomineeVelocity   = "nominee_velocity"
+	FraudRuleIdenticalSequence = "identical_sequence"
+	FraudRuleFreshAccountBurst = "fresh_account_burst"
+	FraudRuleSharedIP          = "shared_ip"
+)
+
+// FraudRules tunes the fraud detectors. A zero threshold disables its rule.
+type FraudRules struct {
+	// Lookback is how far back each scan reads votes.
+	Lookback time.Duration
+
+	// A nominee receiving VelocityThreshold votes in one category within
+	// VelocityWindow is a velocity spike.
+	VelocityWindow    time.Duration
+	VelocityThreshold int
+
+	// SequenceThreshold accounts casting the same picks, in the same order,
+	// across at least SequenceMinLength categories vote as one.
+	SequenceMinLength int
+	SequenceThreshold int
+
+	// Accounts younger than FreshAccountAge when they vote are fresh;
+	// FreshBurstThreshold fresh votes for one nominee within
+	// FreshBurstWindow are a burst.
+	FreshAccountAge     time.Duration
+	FreshBurstWindow    time.Duration
+	FreshBurstThreshold int
+
+	// SharedIPThreshold accounts voting from one IP address within the
+	// lookback are treated as one voter.
+	SharedIPThreshold int
+}
+
+// DefaultFraudRules returns thresholds suited to a public awards vote.
+func DefaultFraudRules() FraudRules {
+	return FraudRules{
+		Lookback:            24 * time.Hour,
+		VelocityWindow:      5 * time.Minute,
+		VelocityThreshold:   100,
+		SequenceMinLength:   3,
+		SequenceThreshold:   5,
+		FreshAccountAge:     time.Hour,
+		FreshBurstWindow:    10 * time.Minute,
+		FreshBurstThreshold: 20,
+		SharedIPThreshold:   10,
+	}
+}
+
+// detectFraud runs every enabled detector over activity, which must be
+// ordered by time, and returns one flag per vote and rule.
+func detectFraud(rules FraudRules, activity []repositories.VoteActivity) []models.VoteFlag {
+	var flags []models.VoteFlag
+	flags = append(flags, detectNomineeVelocity(rules, activity)...)
+	flags = append(flags, detectIdenticalSequences(rules, activity)...)
+	flags = append(flags, detectFreshAccountBursts(rules, activity)...)
+	flags = append(flags, detectSharedIPs(rules, activity)...)
+	return flags
+}
+
+// detectNomineeVelocity flags the votes of any window in which a nominee
+// received too many votes in one category.
+func detectNomineeVelocity(rules FraudRules, activity []repositories.VoteActivity) []models.VoteFlag {
+	if rules.VelocityThreshold <= 0 {
+		return nil
+	}
+	detail := fmt.Sprintf("%d or more votes for the nominee within %s", rules.VelocityThreshold, rules.VelocityWindow)
+	return detectBursts(groupByNominee(activity), rules.VelocityWindow, rules.VelocityThreshold,
+		FraudRuleNomineeVelocity, detail)
+}
+
+// detectFreshAccountBursts flags votes for a nominee from many newly created
+// accounts in a short window.
+func detectFreshAccountBursts(rules FraudRules, activity []repositories.VoteActivity) []models.VoteFlag {
+	if rules.FreshBurstThreshold <= 0 {
+		return nil
+	}
+	var fresh []repositories.VoteActivity
+	for _, vote := range activity {
+		if vote.CreatedAt.Sub(vote.AccountCreatedAt) < rules.FreshAccountAge {
+			fresh = append(fresh, vote)
+		}
+	}
+	detail := fmt.Sprintf("%d or more votes for the nominee within %s from accounts under %s old",
+		rules.FreshBurstThreshold, rules.FreshBurstWindow, rules.FreshAccountAge)
+	return detectBursts(groupByNominee(fresh), rules.FreshBurstWindow, rules.FreshBurstThreshold,
+		FraudRuleFreshAccountBurst, detail)
+}
+
+// detectIdenticalSequences flags the votes of accounts that picked the same
+// nominees in the same order as enough other accounts.
+func detectIdenticalSequences(rules FraudRules, activity []repositories.VoteActivity) []models.VoteFlag {
+	if rules.SequenceThreshold <= 0 {
+		return nil
+	}
+
+	byUser := make(map[uuid.UUID][]repositories.VoteActivity)
+	var users []uuid.UUID
+	for _, vote := range activity {
+		if _, ok := byUser[vote.UserID]; !ok {
+			users = append(users, vote.UserID)
+		}
+		byUser[vote.UserID] = append(byUser[vote.UserID], vote)
+	}
+
+	bySequence := make(map[string][]uuid.UUID)
+	var sequences []string
+	for _, userID := range users {
+		votes := byUser[userID]
+		if len(votes) < rules.SequenceMinLength {
+			continue
+		}
+		picks := make([]string, len(votes))
+		for i, vote := range votes {
+			picks[i] = vote.CategoryID.String() + ":" + vote.NomineeID.String()
+		}
+		sequence := strings.Join(picks, ",")
+		if _, ok := bySequence[sequence]; !ok {
+			sequences = append(sequences, sequence)
+		}
+		bySequence[sequence] = append(bySequence[sequence], userID)
+	}
+
+	var flags []models.VoteFlag
+	for _, sequence := range sequences {
+		accounts := bySequence[sequence]
+		if len(accounts) < rules.SequenceThreshold {
+			continue
+		}
+		detail := fmt.Sprintf("%d accounts cast the same %d votes in the same order",
+			len(accounts), len(byUser[accounts[0]]))
+		for _, userID := range accounts {
+			for _, vote := range byUser[userID] {
+				flags = append(flags, models.VoteFlag{VoteID: vote.VoteID, Rule: FraudRuleIdenticalSequence, Detail: detail})
+			}
+		}
+	}
+	return flags
+}
+
+// detectSharedIPs flags votes from IP addresses that many different accounts
+// voted from. Votes without a recorded address are ignored.
+func detectSharedIPs(rules FraudRules, activity []repositories.VoteActivity) []models.VoteFlag {
+	if rules.SharedIPThreshold <= 0 {
+		return nil
+	}
+
+	accounts := make(map[string]map[uuid.UUID]bool)
+	for _, vote := range activity {
+		if vote.IPAddress == "" {
+			continue
+		}
+		if accounts[vote.IPAddress] == nil {
+			accounts[vote.IPAddress] = make(map[uuid.UUID]bool)
+		}
+		accounts[vote.IPAddress][vote.UserID] = true
+	}
+
+	var flags []models.VoteFlag
+	for _, vote := range activity {
+		shared := len(accounts[vote.IPAddress])
+		if vote.IPAddress == "" || shared < rules.SharedIPThreshold {
+			continue
+		}
+		detail := fmt.Sprintf("%d accounts voted from %s", shared, vote.IPAddress)
+		flags = append(flags, models.VoteFlag{VoteID: vote.VoteID, Rule: FraudRuleSharedIP, Detail: detail})
+	}
+	return flags
+}
+
+// groupByNominee splits time-ordered activity per category and nominee,
+// keeping the order within each group. Groups are returned in a stable order.
+func groupByNominee(activity []repositories.VoteActivity) [][]repositories.VoteActivity {
+	type key struct{ category, nominee uuid.UUID }
+	index := make(map[key]int)
+	var groups [][]repositories.VoteActivity
+	for _, vote := range activity {
+		k := key{vote.CategoryID, vote.NomineeID}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], vote)
+	}
+	return groups
+}
+
+// detectBursts slides a window over each time-ordered group and flags every
+// vote that falls in a window holding at least threshold votes.
+func detectBursts(groups [][]repositories.VoteActivity, window time.Duration, threshold int, rule, detail string) []models.VoteFlag {
+	var flags []models.VoteFlag
+	for _, votes := range groups {
+		flagged := make([]bool, len(votes))
+		start, next := 0, 0 // next is the first vote not yet flagged
+		for end := range votes {
+			for votes[end].CreatedAt.Sub(votes[start].CreatedAt) >= window {
+				start++
+			}
+			if end-start+1 < threshold {
+				continue
+			}
+			for i := max(start, next); i <= end; i++ {
+				flagged[i] = true
+			}
+			next = end + 1
+		}
+		for i, vote := range votes {
+			if flagged[i] {
+				flags = append(flags, models.VoteFlag{VoteID: vote.VoteID, Rule: rule, Detail: detail})
+			}
+		}
+	}
+	return flags
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+)
+
+var ErrInvalidVoteReview = errors.New("vote cannot be reviewed in its current status")
+
+// FraudService flags suspicious voting patterns and manages the review of
+// quarantined votes. Quarantined and void votes are not counted in results.
+type FraudService interface {
+	Analyze(ctx context.Context) (int64, error)
+	Run(ctx context.Context, interval time.Duration)
+	ListQuarantined(ctx context.Context) ([]models.Vote, error)
+	VoidVote(ctx context.Context, voteID, reviewerID uuid.UUID) (*models.Vote, error)
+	RestoreVote(ctx context.Context, voteID, reviewerID uuid.UUID) (*models.Vote, error)
+}
+
+type fraudService struct {
+	fraudRepo repositories.FraudRepository
+	voteRepo  repositories.VoteRepository
+	uow       repositories.UnitOfWork
+	rules     FraudRules
+	now       func() time.Time
+}
+
+func NewFraudService(
+	fraudRepo repositories.FraudRepository,
+	voteRepo repositories.VoteRepository,
+	uow repositories.UnitOfWork,
+	rules FraudRules,
+) FraudService {
+	return &fraudService{
+		fraudRepo: fraudRepo,
+		voteRepo:  voteRepo,
+		uow:       uow,
+		rules:     rules,
+		now:       time.Now,
+	}
+}
+
+// Analyze scans recent votes and quarantines those matching a fraud rule. It
+// returns the number of votes newly quarantined.
+func (s *fraudService) Analyze(ctx context.Context) (int64, error) {
+	activity, err := s.fraudRepo.GetActivitySince(ctx, s.now().Add(-s.rules.Lookback))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get voting activity: %w", err)
+	}
+
+	flags := detectFraud(s.rules, activity)
+	quarantined, err := s.fraudRepo.Quarantine(ctx, flags)
+	if err != nil {
+		return 0, fmt.Errorf("failed to quarantine votes: %w", err)
+	}
+	return quarantined, nil
+}
+
+// Run calls Analyze every interval until ctx is done.
+func (s *fraudService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			quarantined, err := s.Analyze(ctx)
+			if err != nil {
+				log.Printf("fraud scan failed: %v", err)
+				continue
+			}
+			if quarantined > 0 {
+				log.Printf("fraud scan quarantined %d votes", quarantined)
+			}
+		}
+	}
+}
+
+func (s *fraudService) ListQuarantined(ctx context.Context) ([]models.Vote, error) {
+	return s.fraudRepo.GetQuarantined(ctx)
+}
+
+// VoidVote rejects a vote. Void votes stay on record but are never counted,
+// and the voter's budget is not refunded.
+func (s *fraudService) VoidVote(ctx context.Context, voteID, reviewerID uuid.UUID) (*models.Vote, error) {
+	return s.review(ctx, voteID, reviewerID, models.VoteStatusVoid, models.LedgerActionVoid,
+		models.VoteStatusValid, models.VoteStatusQuarantined)
+}
+
+// RestoreVote counts a quarantined or void vote again. Later scans leave a
+// restored vote alone.
+func (s *fraudService) RestoreVote(ctx context.Context, voteID, reviewerID uuid.UUID) (*models.Vote, error) {
+	return s.review(ctx, voteID, reviewerID, models.VoteStatusValid, models.LedgerActionReinstate,
+		models.VoteStatusQuarantined, models.VoteStatusVoid)
+}
+
+// review moves a vote to status and records action in the vote ledger, in a
+// single transaction, since it decides whether the vote is counted.
+func (s *fraudService) review(ctx context.Context, voteID, reviewerID uuid.UUID, status, action string, from ...string) (*models.Vote, error) {
+	// Surfaces the repository's not-found error for unknown votes
+	if _, err := s.voteRepo.GetByID(ctx, voteID); err != nil {
+		return nil, err
+	}
+
+	var vote *models.Vote
+	err := s.uow.Do(ctx, func(tx repositories.Tx) error {
+		updated, err := tx.Fraud().SetStatus(ctx, voteID, from, status, reviewerID, s.now())
+		if err != nil {
+			return fmt.Errorf("failed to review vote: %w", err)
+		}
+		if !updated {
+			return ErrInvalidVoteReview
+		}
+		if vote, err = tx.Votes().GetByID(ctx, voteID); err != nil {
+			return err
+		}
+		return appendVoteLedger(ctx, tx.Ledger(), action, vote)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vote, nil
+}
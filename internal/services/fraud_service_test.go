@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockFraudRepository struct {
+	mock.Mock
+}
+
+func (m *MockFraudRepository) GetActivitySince(ctx context.Context, since time.Time) ([]repositories.VoteActivity, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]repositories.VoteActivity), args.Error(1)
+}
+
+func (m *MockFraudRepository) Quarantine(ctx context.Context, flags []models.VoteFlag) (int64, error) {
+	args := m.Called(ctx, flags)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFraudRepository) GetQuarantined(ctx context.Context) ([]models.Vote, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Vote), args.Error(1)
+}
+
+func (m *MockFraudRepository) SetStatus(ctx context.Context, voteID uuid.UUID, from []string, status string, reviewerID uuid.UUID, at time.Time) (bool, error) {
+	args := m.Called(ctx, voteID, from, status, reviewerID, at)
+	return args.Bool(0), args.Error(1)
+}
+
+var fraudNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// votesAt returns one vote for nominee in category per offset from fraudNow,
+// each from a distinct account created accountAge before it voted.
+func votesAt(category, nominee uuid.UUID, accountAge time.Duration, offsets ...time.Duration) []repositories.VoteActivity {
+	activity := make([]repositories.VoteActivity, len(offsets))
+	for i, offset := range offsets {
+		at := fraudNow.Add(offset)
+		activity[i] = repositories.VoteActivity{
+			VoteID:           uuid.New(),
+			UserID:           uuid.New(),
+			CategoryID:       category,
+			NomineeID:        nominee,
+			CreatedAt:        at,
+			AccountCreatedAt: at.Add(-accountAge),
+		}
+	}
+	return activity
+}
+
+func flaggedVotes(flags []models.VoteFlag, rule string) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, flag := range flags {
+		if flag.Rule == rule {
+			ids = append(ids, flag.VoteID)
+		}
+	}
+	return ids
+}
+
+func TestDetectNomineeVelocity(t *testing.T) {
+	rules := FraudRules{VelocityWindow: time.Minute, VelocityThreshold: 3}
+	category, nominee, other := uuid.New(), uuid.New(), uuid.New()
+
+	// Three votes within a minute, then a straggler outside the window
+	spike := votesAt(category, nominee, 24*time.Hour, 0, 20*time.Second, 50*time.Second, 5*time.Minute)
+	// The same pace spread over two nominees never reaches the threshold
+	spread := append(
+		votesAt(category, other, 24*time.Hour, 0, 30*time.Second),
+		votesAt(uuid.New(), other, 24*time.Hour, 10*time.Second)...,
+	)
+
+	flags := detectNomineeVelocity(rules, append(spike, spread...))
+
+	assert.Equal(t, []uuid.UUID{spike[0].VoteID, spike[1].VoteID, spike[2].VoteID},
+		flaggedVotes(flags, FraudRuleNomineeVelocity))
+}
+
+func TestDetectIdenticalSequences(t *testing.T) {
+	rules := FraudRules{SequenceMinLength: 2, SequenceThreshold: 3}
+	categoryA, categoryB := uuid.New(), uuid.New()
+	nomineeA, nomineeB := uuid.New(), uuid.New()
+
+	var activity []repositories.VoteActivity
+	var ring []uuid.UUID
+	account := func(picks ...[2]uuid.UUID) []uuid.UUID {
+		userID := uuid.New()
+		var ids []uuid.UUID
+		for _, pick := range picks {
+			vote := repositories.VoteActivity{VoteID: uuid.New(), UserID: userID, CategoryID: pick[0], NomineeID: pick[1]}
+			activity = append(activity, vote)
+			ids = append(ids, vote.VoteID)
+		}
+		return ids
+	}
+	for i := 0; i < 3; i++ {
+		ring = append(ring, account([2]uuid.UUID{categoryA, nomineeA}, [2]uuid.UUID{categoryB, nomineeB})...)
+	}
+	// Same picks in another order, and a single vote, are not part of the ring
+	account([2]uuid.UUID{categoryB, nomineeB}, [2]uuid.UUID{categoryA, nomineeA})
+	account([2]uuid.UUID{categoryA, nomineeA})
+
+	flags := detectIdenticalSequences(rules, activity)
+
+	assert.ElementsMatch(t, ring, flaggedVotes(flags, FraudRuleIdenticalSequence))
+}
+
+func TestDetectFreshAccountBursts(t *testing.T) {
+	rules := FraudRules{FreshAccountAge: time.Hour, FreshBurstWindow: 10 * time.Minute, FreshBurstThreshold: 2}
+	category, nominee := uuid.New(), uuid.New()
+
+	fresh := votesAt(category, nominee, 5*time.Minute, 0, time.Minute)
+	established := votesAt(category, nominee, 48*time.Hour, 30*time.Second, 90*time.Second)
+
+	flags := detectFreshAccountBursts(rules, append(fresh, established...))
+
+	assert.Equal(t, []uuid.UUID{fresh[0].VoteID, fresh[1].VoteID}, flaggedVotes(flags, FraudRuleFreshAccountBurst))
+}
+
+func TestDetectSharedIPs(t *testing.T) {
+	rules := FraudRules{SharedIPThreshold: 3}
+	category, nominee := uuid.New(), uuid.New()
+
+	activity := votesAt(category, nominee, 24*time.Hour, 0, time.Minute, 2*time.Minute, 3*time.Minute, 4*time.Minute)
+	for i := range activity[:3] {
+		activity[i].IPAddress = "198.51.100.4"
+	}
+	// One account voting repeatedly from its own address is not shared
+	activity[3].IPAddress = "198.51.100.9"
+	activity[4].IPAddress = "198.51.100.9"
+	activity[4].UserID = activity[3].UserID
+
+	flags := detectSharedIPs(rules, activity)
+
+	assert.Equal(t, []uuid.UUID{activity[0].VoteID, activity[1].VoteID, activity[2].VoteID},
+		flaggedVotes(flags, FraudRuleSharedIP))
+}
+
+func TestDetectFraud_DisabledRules(t *testing.T) {
+	activity := votesAt(uuid.New(), uuid.New(), 0, 0, 0, 0, 0, 0)
+
+	assert.Empty(t, detectFraud(FraudRules{}, activity))
+}
+
+func setupFraudTest() (*MockFraudRepository, *MockVoteRepository, *MockLedgerRepository, *fraudService) {
+	fraudRepo := new(MockFraudRepository)
+	voteRepo := new(MockVoteRepository)
+	ledgerRepo := new(MockLedgerRepository)
+	ledgerRepo.On("Append", mock.Anything, mock.Anything).Return(nil).Maybe()
+	uow := &mockUnitOfWork{votes: voteRepo, fraud: fraudRepo, ledger: ledgerRepo}
+	rules := DefaultFraudRules()
+	rules.VelocityThreshold = 3
+	service := NewFraudService(fraudRepo, voteRepo, uow, rules).(*fraudService)
+	service.now = func() time.Time { return fraudNow }
+	return fraudRepo, voteRepo, ledgerRepo, service
+}
+
+func TestFraudService_Analyze(t *testing.T) {
+	fraudRepo, _, _, service := setupFraudTest()
+	activity := votesAt(uuid.New(), uuid.New(), 24*time.Hour, 0, time.Second, 2*time.Second)
+
+	fraudRepo.On("GetActivitySince", mock.Anything, fraudNow.Add(-24*time.Hour)).Return(activity, nil)
+	fraudRepo.On("Quarantine", mock.Anything, mock.MatchedBy(func(flags []models.VoteFlag) bool {
+		return len(flaggedVotes(flags, FraudRuleNomineeVelocity)) == 3
+	})).Return(int64(3), nil)
+
+	quarantined, err := service.Analyze(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), quarantined)
+	fraudRepo.AssertExpectations(t)
+}
+
+func TestFraudService_Review(t *testing.T) {
+	voteID, reviewerID := uuid.New(), uuid.New()
+
+	t.Run("void a quarantined vote", func(t *testing.T) {
+		fraudRepo, voteRepo, ledgerRepo, service := setupFraudTest()
+		voided := &models.Vote{VoteID: voteID, Status: models.VoteStatusVoid, ReviewedBy: &reviewerID}
+		voteRepo.On("GetByID", mock.Anything, voteID).Return(voided, nil)
+		fraudRepo.On("SetStatus", mock.Anything, voteID,
+			[]string{models.VoteStatusValid, models.VoteStatusQuarantined},
+			models.VoteStatusVoid, reviewerID, fraudNow).Return(true, nil)
+
+		vote, err := service.VoidVote(context.Background(), voteID, reviewerID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, voided, vote)
+		ledgerRepo.AssertCalled(t, "Append", mock.Anything, mock.MatchedBy(func(entry *models.VoteLedgerEntry) bool {
+			return entry.Action == models.LedgerActionVoid && entry.VoteID == voteID &&
+				strings.Contains(entry.Payload, `"reviewed_by":"`+reviewerID.String()+`"`)
+		}))
+	})
+
+	t.Run("restore a vote that is already valid", func(t *testing.T) {
+		fraudRepo, voteRepo, ledgerRepo, service := setupFraudTest()
+		voteRepo.On("GetByID", mock.Anything, voteID).Return(&models.Vote{VoteID: voteID, Status: models.VoteStatusValid}, nil)
+		fraudRepo.On("SetStatus", mock.Anything, voteID,
+			[]string{models.VoteStatusQuarantined, models.VoteStatusVoid},
+			models.VoteStatusValid, reviewerID, fraudNow).Return(false, nil)
+
+		_, err := service.RestoreVote(context.Background(), voteID, reviewerID)
+
+		assert.ErrorIs(t, err, ErrInvalidVoteReview)
+		ledgerRepo.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown vote", func(t *testing.T) {
+		fraudRepo, voteRepo, _, service := setupFraudTest()
+		voteRepo.On("GetByID", mock.Anything, voteID).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.VoidVote(context.Background(), voteID, reviewerID)
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		fraudRepo.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
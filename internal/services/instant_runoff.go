@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+)
+
+// instantRunoff counts ranked ballots over candidates. Every round each ballot
+// counts for its highest-ranked candidate still standing. A candidate holding
+// more than half of the continuing ballots wins; otherwise the candidate with
+// the lowest count is eliminated. Counting stops without a majority when
+// every remaining candidate has the same count, in which case they all share
+// first place. Preferences for nominees outside candidates are skipped.
+//
+// A tie for the lowest count is broken by the earlier rounds, most recent
+// first: whoever had fewer votes in the latest round that separates them goes.
+// As the first round counts first preferences, that decides ties between
+// candidates that were ever apart. Candidates tied in every round are told
+// apart by ID, and the one whose ID sorts last is eliminated, so that anyone
+// recounting the same ballots gets the same result.
+func instantRunoff(candidates []uuid.UUID, ballots [][]uuid.UUID) (rounds []models.RunoffRound, winners []uuid.UUID) {
+	standing := make(map[uuid.UUID]bool, len(candidates))
+	for _, id := range candidates {
+		standing[id] = true
+	}
+
+	// history keeps each earlier round's counts to break ties with
+	var history []map[uuid.UUID]int64
+	for len(standing) > 0 {
+		counts := make(map[uuid.UUID]int64, len(standing))
+		var exhausted, continuing int64
+		for _, ballot := range ballots {
+			counted := false
+			for _, id := range ballot {
+				if standing[id] {
+					counts[id]++
+					continuing++
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				exhausted++
+			}
+		}
+
+		round := models.RunoffRound{Round: len(rounds) + 1, Exhausted: exhausted}
+		lowest, highest := int64(-1), int64(0)
+		for _, id := range candidates {
+			if !standing[id] {
+				continue
+			}
+			round.Tallies = append(round.Tallies, models.RunoffTally{NomineeID: id, Votes: counts[id]})
+			if lowest < 0 || counts[id] < lowest {
+				lowest = counts[id]
+			}
+			highest = max(highest, counts[id])
+		}
+		sort.SliceStable(round.Tallies, func(i, j int) bool {
+			return round.Tallies[i].Votes > round.Tallies[j].Votes
+		})
+
+		if leader := round.Tallies[0]; leader.Votes*2 > continuing {
+			return append(rounds, round), []uuid.UUID{leader.NomineeID}
+		}
+		if lowest == highest {
+			if highest > 0 {
+				for _, tally := range round.Tallies {
+					winners = append(winners, tally.NomineeID)
+				}
+			}
+			return append(rounds, round), winners
+		}
+
+		var last []uuid.UUID
+		for _, tally := range round.Tallies {
+			if tally.Votes == lowest {
+				last = append(last, tally.NomineeID)
+			}
+		}
+		eliminated := breakRunoffTie(last, history)
+		round.Eliminated = []uuid.UUID{eliminated}
+		delete(standing, eliminated)
+		rounds = append(rounds, round)
+		history = append(history, counts)
+	}
+	return rounds, nil
+}
+
+// breakRunoffTie picks which of the candidates tied for the lowest count is
+// eliminated, as described on instantRunoff.
+func breakRunoffTie(tied []uuid.UUID, history []map[uuid.UUID]int64) uuid.UUID {
+	for i := len(history) - 1; i >= 0 && len(tied) > 1; i-- {
+		counts := history[i]
+		fewest := counts[tied[0]]
+		for _, id := range tied[1:] {
+			fewest = min(fewest, counts[id])
+		}
+		var next []uuid.UUID
+		for _, id := range tied {
+			if counts[id] == fewest {
+				next = append(next, id)
+			}
+		}
+		tied = next
+	}
+	return slices.MaxFunc(tied, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+}
+
+// buildRankedCategoryResults decides a ranked-choice category by instant
+// runoff. Tallies supply the nominees and their first-preference counts.
+// Nominees still standing in the last round are ranked by their count there,
+// with equal counts sharing a rank, followed by the eliminated nominees in
+// reverse order of elimination.
+func buildRankedCategoryResults(category *models.Category, tallies []repositories.NomineeTally, ballots [][]uuid.UUID) models.CategoryResults {
+	results := models.CategoryResults{
+		CategoryID:   category.CategoryID,
+		CategoryName: category.Name,
+		EditionID:    category.EditionID,
+		VotingMethod: models.VotingMethodRanked,
+		TotalVotes:   int64(len(ballots)),
+	}
+
+	candidates := make([]uuid.UUID, len(tallies))
+	byNominee := make(map[uuid.UUID]repositories.NomineeTally, len(tallies))
+	for i, tally := range tallies {
+		candidates[i] = tally.NomineeID
+		byNominee[tally.NomineeID] = tally
+	}
+
+	rounds, winners := instantRunoff(candidates, ballots)
+	for i := range rounds {
+		for j := range rounds[i].Tallies {
+			rounds[i].Tallies[j].NomineeName = byNominee[rounds[i].Tallies[j].NomineeID].NomineeName
+		}
+	}
+	results.Rounds = rounds
+	results.Tied = len(winners) > 1
+
+	isWinner := make(map[uuid.UUID]bool, len(winners))
+	for _, id := range winners {
+		isWinner[id] = true
+	}
+
+	var groups [][]uuid.UUID
+	if len(rounds) > 0 {
+		final := rounds[len(rounds)-1]
+		for i, tally := range final.Tallies {
+			if i == 0 || tally.Votes != final.Tallies[i-1].Votes {
+				groups = append(groups, nil)
+			}
+			groups[len(groups)-1] = append(groups[len(groups)-1], tally.NomineeID)
+		}
+		for i := len(rounds) - 1; i >= 0; i-- {
+			if len(rounds[i].Eliminated) > 0 {
+				groups = append(groups, rounds[i].Eliminated)
+			}
+		}
+	}
+
+	for _, group := range groups {
+		rank := len(results.Nominees) + 1
+		for _, id := range group {
+			tally := byNominee[id]
+			results.Nominees = append(results.Nominees, models.NomineeResult{
+				NomineeID:   id,
+				NomineeName: tally.NomineeName,
+				Votes:       tally.Votes,
+				Percentage:  tally.Percentage,
+				Rank:        rank,
+				Winner:      isWinner[id],
+			})
+		}
+	}
+	return results
+}
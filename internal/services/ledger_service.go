@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+)
+
+// ledgerBatchSize is how many entries Verify reads at a time
+const ledgerBatchSize = 1000
+
+// maxLedgerProblems caps the problems Verify reports; one tampered entry
+// usually breaks every link after it.
+const maxLedgerProblems = 100
+
+// LedgerService checks the integrity of the vote ledger
+type LedgerService interface {
+	Verify(ctx context.Context) (*models.LedgerReport, error)
+}
+
+type ledgerService struct {
+	ledgerRepo repositories.LedgerRepository
+}
+
+func NewLedgerService(ledgerRepo repositories.LedgerRepository) LedgerService {
+	return &ledgerService{ledgerRepo: ledgerRepo}
+}
+
+// Verify walks the ledger from the first entry, checking that sequences have
+// no gaps, that each entry links to the previous entry's hash, and that each
+// stored hash matches the entry's contents.
+func (s *ledgerService) Verify(ctx context.Context) (*models.LedgerReport, error) {
+	report := &models.LedgerReport{HeadHash: models.GenesisHash}
+	problem := func(sequence int64, kind, detail string) {
+		if len(report.Problems) < maxLedgerProblems {
+			report.Problems = append(report.Problems, models.LedgerProblem{Sequence: sequence, Kind: kind, Detail: detail})
+		}
+	}
+
+	var sequence int64
+	var problems int
+	for {
+		entries, err := s.ledgerRepo.ListAfter(ctx, sequence, ledgerBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vote ledger: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.Sequence != sequence+1 {
+				problems++
+				problem(entry.Sequence, models.LedgerProblemGap,
+					fmt.Sprintf("entries %d to %d are missing", sequence+1, entry.Sequence-1))
+			}
+			if entry.PrevHash != report.HeadHash {
+				problems++
+				problem(entry.Sequence, models.LedgerProblemBrokenLink,
+					fmt.Sprintf("previous hash %s does not match %s", entry.PrevHash, report.HeadHash))
+			}
+			if hash := entry.ComputeHash(); entry.Hash != hash {
+				problems++
+				problem(entry.Sequence, models.LedgerProblemHashMismatch,
+					fmt.Sprintf("stored hash %s does not match contents hash %s", entry.Hash, hash))
+			}
+
+			sequence = entry.Sequence
+			report.HeadHash = entry.Hash
+			report.Entries++
+		}
+
+		if len(entries) < ledgerBatchSize {
+			break
+		}
+	}
+
+	report.Valid = problems == 0
+	return report, nil
+}
+
+// ledgerPayload is the snapshot of a vote recorded in the ledger
+type ledgerPayload struct {
+	VoteID     uuid.UUID   `json:"vote_id"`
+	UserID     uuid.UUID   `json:"user_id"`
+	EditionID  uuid.UUID   `json:"edition_id"`
+	CategoryID uuid.UUID   `json:"category_id"`
+	NomineeID  uuid.UUID   `json:"nominee_id"`
+	Rankings   []uuid.UUID `json:"rankings,omitempty"`
+	VoterClass string      `json:"voter_class"`
+	ReviewedBy *uuid.UUID  `json:"reviewed_by,omitempty"`
+}
+
+// appendVoteLedger records action on vote in the ledger. It must be called
+// inside the transaction that performs the action.
+func appendVoteLedger(ctx context.Context, ledger repositories.LedgerRepository, action string, vote *models.Vote) error {
+	payload, err := json.Marshal(ledgerPayload{
+		VoteID:     vote.VoteID,
+		UserID:     vote.UserID,
+		EditionID:  vote.EditionID,
+		CategoryID: vote.CategoryID,
+		NomineeID:  vote.NomineeID,
+		Rankings:   vote.RankedNominees(),
+		VoterClass: vote.VoterClass,
+		ReviewedBy: vote.ReviewedBy,
+	})
+	if err != nil {
+		return err
+	}
+	if err := ledger.Append(ctx, &models.VoteLedgerEntry{
+		Action:  action,
+		VoteID:  vote.VoteID,
+		Payload: string(payload),
+	}); err != nil {
+		return fmt.Errorf("failed to append to vote ledger: %w", err)
+	}
+	return nil
+}
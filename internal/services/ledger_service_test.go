@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) Append(ctx context.Context, entry *models.VoteLedgerEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockLedgerRepository) ListAfter(ctx context.Context, sequence int64, limit int) ([]models.VoteLedgerEntry, error) {
+	args := m.Called(ctx, sequence, limit)
+	return args.Get(0).([]models.VoteLedgerEntry), args.Error(1)
+}
+
+// buildLedger chains n create entries the way the repository appends them.
+func buildLedger(n int) []models.VoteLedgerEntry {
+	entries := make([]models.VoteLedgerEntry, n)
+	prev := models.GenesisHash
+	for i := range entries {
+		entries[i] = models.VoteLedgerEntry{
+			Sequence:  int64(i + 1),
+			Action:    models.LedgerActionCreate,
+			VoteID:    uuid.New(),
+			Payload:   `{}`,
+			PrevHash:  prev,
+			CreatedAt: votingNow,
+		}
+		entries[i].Hash = entries[i].ComputeHash()
+		prev = entries[i].Hash
+	}
+	return entries
+}
+
+func TestLedgerService_Verify(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(entries []models.VoteLedgerEntry) []models.VoteLedgerEntry
+		want   []string
+	}{
+		{
+			name:   "intact",
+			tamper: func(entries []models.VoteLedgerEntry) []models.VoteLedgerEntry { return entries },
+		},
+		{
+			name: "edited payload",
+			tamper: func(entries []models.VoteLedgerEntry) []models.VoteLedgerEntry {
+				entries[1].Payload = `{"nominee_id":"someone-else"}`
+				return entries
+			},
+			want: []string{models.LedgerProblemHashMismatch},
+		},
+		{
+			name: "edited payload with recomputed hash",
+			tamper: func(entries []models.VoteLedgerEntry) []models.VoteLedgerEntry {
+				entries[1].Payload = `{"nominee_id":"someone-else"}`
+				entries[1].Hash = entries[1].ComputeHash()
+				return entries
+			},
+			want: []string{models.LedgerProblemBrokenLink},
+		},
+		{
+			name: "deleted entry",
+			tamper: func(entries []models.VoteLedgerEntry) []models.VoteLedgerEntry {
+				return append(entries[:1], entries[2:]...)
+			},
+			want: []string{models.LedgerProblemGap, models.LedgerProblemBrokenLink},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := tt.tamper(buildLedger(3))
+			ledgerRepo := new(MockLedgerRepository)
+			ledgerRepo.On("ListAfter", mock.Anything, int64(0), ledgerBatchSize).Return(entries, nil)
+			service := NewLedgerService(ledgerRepo)
+
+			report, err := service.Verify(context.Background())
+
+			require.NoError(t, err)
+			var kinds []string
+			for _, problem := range report.Problems {
+				kinds = append(kinds, problem.Kind)
+			}
+			assert.Equal(t, tt.want, kinds)
+			assert.Equal(t, len(tt.want) == 0, report.Valid)
+			assert.Equal(t, int64(len(entries)), report.Entries)
+			assert.Equal(t, entries[len(entries)-1].Hash, report.HeadHash)
+		})
+	}
+}
+
+func TestVotingMechanismService_RecordsLedger(t *testing.T) {
+	user := models.User{UserID: uuid.New(), AvailableVotes: 5, EmailVerifiedAt: &votingNow}
+	store := newMemStore(user)
+	categoryID := uuid.New()
+	service := setupConcurrentVoteTest(store, categoryID)
+	ctx := context.Background()
+
+	vote, err := service.CastVote(ctx, user.UserID, uuid.New(), categoryID)
+	require.NoError(t, err)
+	changed, err := service.ChangeVote(ctx, vote.VoteID, user.UserID, uuid.New())
+	require.NoError(t, err)
+	service.voteRepo.(*MockVoteRepository).On("GetByID", mock.Anything, vote.VoteID).Return(changed, nil)
+	require.NoError(t, service.DeleteVote(ctx, vote.VoteID))
+
+	require.Len(t, store.ledger, 3)
+	for i, action := range []string{models.LedgerActionCreate, models.LedgerActionChange, models.LedgerActionDelete} {
+		assert.Equal(t, action, store.ledger[i].Action)
+		assert.Equal(t, vote.VoteID, store.ledger[i].VoteID)
+	}
+	var payload ledgerPayload
+	require.NoError(t, json.Unmarshal([]byte(store.ledger[1].Payload), &payload))
+	assert.Equal(t, changed.NomineeID, payload.NomineeID)
+
+	report, err := NewLedgerService(store.Ledger()).Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+	assert.Equal(t, int64(3), report.Entries)
+}
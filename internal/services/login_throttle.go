@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nyashahama/music-awards/internal/fingerprint"
+	"github.com/nyashahama/music-awards/internal/models"
+)
+
+// loginFailureWindow is how long a failed login is remembered. Counts start
+// again after this long without a failure.
+const loginFailureWindow = 24 * time.Hour
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+
+// LoginThrottledError reports how long until login may be tried again. It
+// matches ErrTooManyLoginAttempts.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s: retry in %s", ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrTooManyLoginAttempts
+}
+
+// throttlePolicy sets how failed logins slow down further attempts from the
+// same email address or IP.
+type throttlePolicy struct {
+	// freeAttempts failures are allowed before logins are delayed
+	freeAttempts int
+	// baseDelay follows the first failure beyond freeAttempts and doubles
+	// with each further failure, up to maxDelay
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	// lockoutAttempts failures lock logins for lockoutDuration
+	lockoutAttempts int
+	lockoutDuration time.Duration
+}
+
+var (
+	// accountThrottle applies per email address. Lockout is reached quickly
+	// since a real user rarely mistypes their password ten times.
+	accountThrottle = throttlePolicy{
+		freeAttempts:    4,
+		baseDelay:       time.Second,
+		maxDelay:        time.Minute,
+		lockoutAttempts: 10,
+		lockoutDuration: 15 * time.Minute,
+	}
+	// ipThrottle applies per client IP, which many users may share behind
+	// a NAT
+	ipThrottle = throttlePolicy{
+		freeAttempts:    20,
+		baseDelay:       time.Second,
+		maxDelay:        time.Minute,
+		lockoutAttempts: 100,
+		lockoutDuration: time.Hour,
+	}
+)
+
+// delay returns how long logins are blocked after failures failed attempts.
+func (p throttlePolicy) delay(failures int) time.Duration {
+	switch {
+	case failures >= p.lockoutAttempts:
+		return p.lockoutDuration
+	case failures <= p.freeAttempts:
+		return 0
+	}
+	delay := p.baseDelay
+	for i := p.freeAttempts + 1; i < failures && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.maxDelay)
+}
+
+// loginThrottle is one count of failed logins a login attempt adds to.
+type loginThrottle struct {
+	key     string
+	policy  throttlePolicy
+	account bool
+}
+
+// loginThrottles returns the counts for email and, within an HTTP request,
+// for the client's IP.
+func (s *userService) loginThrottles(ctx context.Context, email string) []loginThrottle {
+	throttles := []loginThrottle{{key: "email:" + email, policy: s.accountThrottle, account: true}}
+	if fp, ok := fingerprint.FromContext(ctx); ok && fp.IP != "" {
+		throttles = append(throttles, loginThrottle{key: "ip:" + fp.IP, policy: s.ipThrottle})
+	}
+	return throttles
+}
+
+// checkLoginThrottle returns a LoginThrottledError if any of throttles is
+// locked at now.
+func (s *userService) checkLoginThrottle(ctx context.Context, throttles []loginThrottle, now time.Time) error {
+	keys := make([]string, len(throttles))
+	for i, throttle := range throttles {
+		keys[i] = throttle.key
+	}
+	records, err := s.throttleRepo.Get(ctx, keys)
+	if err != nil {
+		return fmt.Errorf("failed to get login throttles: %w", err)
+	}
+
+	var retryAfter time.Duration
+	for _, record := range records {
+		if record.LockedUntil != nil && record.LockedUntil.After(now) {
+			retryAfter = max(retryAfter, record.LockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login against throttles and blocks
+// them for as long as their policies say. The account owner, if there is
+// one, is emailed each time their account goes from unlocked to locked out,
+// including when failures continue after an earlier lockout ran out.
+func (s *userService) recordLoginFailure(ctx context.Context, throttles []loginThrottle, user *models.User, now time.Time) error {
+	for _, throttle := range throttles {
+		failures, err := s.throttleRepo.RecordFailure(ctx, throttle.key, now, loginFailureWindow)
+		if err != nil {
+			return fmt.Errorf("failed to record login failure: %w", err)
+		}
+		delay := throttle.policy.delay(failures)
+		if delay == 0 {
+			continue
+		}
+		until := now.Add(delay)
+		wasUnlocked, err := s.throttleRepo.Lock(ctx, throttle.key, now, until)
+		if err != nil {
+			return fmt.Errorf("failed to lock login: %w", err)
+		}
+		lockout := failures >= throttle.policy.lockoutAttempts
+		if throttle.account && user != nil && lockout && wasUnlocked {
+			go s.sendAccountLockedEmail(user.Email, until)
+		}
+	}
+	return nil
+}
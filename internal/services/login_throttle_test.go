@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nyashahama/music-awards/internal/fingerprint"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memLoginThrottleRepository counts failed logins in memory.
+type memLoginThrottleRepository struct {
+	throttles map[string]*models.LoginThrottle
+}
+
+func newMemLoginThrottleRepository() *memLoginThrottleRepository {
+	return &memLoginThrottleRepository{throttles: make(map[string]*models.LoginThrottle)}
+}
+
+func (r *memLoginThrottleRepository) Get(ctx context.Context, keys []string) ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	for _, key := range keys {
+		if throttle, ok := r.throttles[key]; ok {
+			throttles = append(throttles, *throttle)
+		}
+	}
+	return throttles, nil
+}
+
+func (r *memLoginThrottleRepository) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	throttle, ok := r.throttles[key]
+	if !ok || throttle.LastFailureAt.Before(now.Add(-window)) {
+		throttle = &models.LoginThrottle{ThrottleKey: key}
+		r.throttles[key] = throttle
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	return throttle.Failures, nil
+}
+
+func (r *memLoginThrottleRepository) Lock(ctx context.Context, key string, now, until time.Time) (bool, error) {
+	throttle := r.throttles[key]
+	wasUnlocked := throttle.LockedUntil == nil || !throttle.LockedUntil.After(now)
+	throttle.LockedUntil = &until
+	return wasUnlocked, nil
+}
+
+func (r *memLoginThrottleRepository) Reset(ctx context.Context, key string) error {
+	delete(r.throttles, key)
+	return nil
+}
+
+func TestThrottlePolicy_Delay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 0},
+		{4, 0},
+		{5, time.Second},
+		{6, 2 * time.Second},
+		{9, 16 * time.Second},
+		{10, 15 * time.Minute},
+		{25, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, accountThrottle.delay(tt.failures), tt.failures)
+	}
+
+	assert.Equal(t, ipThrottle.maxDelay, ipThrottle.delay(ipThrottle.lockoutAttempts-1))
+}
+
+// setupLoginThrottleTest returns a user service whose clock the test moves
+// with advance.
+func setupLoginThrottleTest() (*MockUserRepository, *userService, *models.User, func(time.Duration)) {
+	userRepo := new(MockUserRepository)
+	service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo})
+	user := createTestUser()
+	user.PasswordHash, _ = hashPassword("correctpassword")
+	userRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	userRepo.On("GetByEmail", mock.Anything, mock.Anything).Return(nil, nil)
+
+	now := verificationNow
+	service.now = func() time.Time { return now }
+	return userRepo, service, user, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestUserService_LoginBackoff(t *testing.T) {
+	_, service, user, advance := setupLoginThrottleTest()
+	ctx := context.Background()
+
+	for range accountThrottle.freeAttempts {
+		_, err := service.Login(ctx, user.Email, "wrong")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	_, err := service.Login(ctx, user.Email, "wrong")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = service.Login(ctx, user.Email, "correctpassword")
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled), "the right password is refused while blocked")
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	assert.Equal(t, time.Second, throttled.RetryAfter)
+
+	advance(time.Second)
+	_, err = service.Login(ctx, user.Email, "wrong")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = service.Login(ctx, user.Email, "wrong")
+	require.True(t, errors.As(err, &throttled))
+	assert.Equal(t, 2*time.Second, throttled.RetryAfter, "the delay doubles")
+
+	advance(2 * time.Second)
+	tokens, err := service.Login(ctx, user.Email, "correctpassword")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	_, err = service.Login(ctx, user.Email, "wrong")
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "a successful login resets the count")
+	_, err = service.Login(ctx, user.Email, "correctpassword")
+	assert.NoError(t, err)
+}
+
+func TestUserService_LoginLockout(t *testing.T) {
+	_, service, user, advance := setupLoginThrottleTest()
+	ctx := context.Background()
+	type lockedEmail struct {
+		recipient string
+		until     time.Time
+	}
+	emails := make(chan lockedEmail, 2)
+	service.sendAccountLockedEmail = func(recipient string, until time.Time) {
+		emails <- lockedEmail{recipient, until}
+	}
+
+	var lockedAt time.Time
+	for i := 1; i <= accountThrottle.lockoutAttempts; i++ {
+		_, err := service.Login(ctx, user.Email, "wrong")
+		require.ErrorIs(t, err, ErrInvalidCredentials, "attempt %d", i)
+		lockedAt = service.now()
+		advance(accountThrottle.maxDelay)
+	}
+
+	select {
+	case email := <-emails:
+		assert.Equal(t, user.Email, email.recipient)
+		assert.Equal(t, lockedAt.Add(accountThrottle.lockoutDuration), email.until)
+	case <-time.After(time.Second):
+		t.Fatal("lockout email not sent")
+	}
+
+	_, err := service.Login(ctx, user.Email, "correctpassword")
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled))
+	assert.Equal(t, accountThrottle.lockoutDuration-accountThrottle.maxDelay, throttled.RetryAfter)
+
+	// Failing again once the lockout ends locks the account straight away
+	advance(accountThrottle.lockoutDuration)
+	_, err = service.Login(ctx, user.Email, "wrong")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	select {
+	case email := <-emails:
+		assert.Equal(t, service.now().Add(accountThrottle.lockoutDuration), email.until)
+	case <-time.After(time.Second):
+		t.Fatal("second lockout email not sent")
+	}
+
+	// A failure counted while already locked out, as from a login racing
+	// the one that locked it, sends nothing more
+	throttles := service.loginThrottles(ctx, user.Email)
+	require.NoError(t, service.recordLoginFailure(ctx, throttles, user, service.now()))
+	select {
+	case <-emails:
+		t.Fatal("the owner is only emailed when the account becomes locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestUserService_LoginThrottlesIP(t *testing.T) {
+	_, service, user, _ := setupLoginThrottleTest()
+	service.ipThrottle = throttlePolicy{freeAttempts: 2, baseDelay: time.Minute, maxDelay: time.Minute, lockoutAttempts: 10, lockoutDuration: time.Hour}
+	ctx := fingerprint.NewContext(context.Background(), testFingerprint)
+
+	// Guesses spread over many accounts still count against the IP
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, err := service.Login(ctx, email, "wrong")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	_, err := service.Login(ctx, user.Email, "correctpassword")
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+
+	_, err = service.Login(context.Background(), user.Email, "correctpassword")
+	assert.NoError(t, err, "other clients are not blocked")
+}
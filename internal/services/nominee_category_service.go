@@ -2,12 +2,16 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/models"
 	"github.com/nyashahama/music-awards/internal/repositories"
 )
 
+var ErrCategoryLinkHasVotes = errors.New("nominee has votes in the category")
+
 type NomineeCategoryService interface {
 	AddCategory(ctx context.Context, nomineeID, categoryID uuid.UUID) error
 	RemoveCategory(ctx context.Context, nomineeID, categoryID uuid.UUID) error
@@ -17,17 +21,21 @@ type NomineeCategoryService interface {
 }
 
 type nomineeCategoryService struct {
-	repo repositories.NomineeCategoryRepository
+	repo         repositories.NomineeCategoryRepository
+	categoryRepo repositories.CategoryRepository
 }
 
-func NewNomineeCategoryService(repo repositories.NomineeCategoryRepository) NomineeCategoryService {
-	return &nomineeCategoryService{repo: repo}
+func NewNomineeCategoryService(repo repositories.NomineeCategoryRepository, categoryRepo repositories.CategoryRepository) NomineeCategoryService {
+	return &nomineeCategoryService{repo: repo, categoryRepo: categoryRepo}
 }
 
 func (s *nomineeCategoryService) AddCategory(ctx context.Context, nomineeID, categoryID uuid.UUID) error {
 	if nomineeID == uuid.Nil || categoryID == uuid.Nil {
 		return ErrInvalidID
 	}
+	if err := s.checkCategoriesExist(ctx, []uuid.UUID{categoryID}); err != nil {
+		return err
+	}
 	return s.repo.AddCategory(ctx, nomineeID, categoryID)
 }
 
@@ -35,14 +43,83 @@ func (s *nomineeCategoryService) RemoveCategory(ctx context.Context, nomineeID,
 	if nomineeID == uuid.Nil || categoryID == uuid.Nil {
 		return ErrInvalidID
 	}
+	if err := s.checkNoVotes(ctx, nomineeID, []uuid.UUID{categoryID}); err != nil {
+		return err
+	}
 	return s.repo.RemoveCategory(ctx, nomineeID, categoryID)
 }
 
+// SetCategories links the nominee to exactly categoryIDs. Only the links that
+// change are touched, and none may be removed while it has votes.
 func (s *nomineeCategoryService) SetCategories(ctx context.Context, nomineeID uuid.UUID, categoryIDs []uuid.UUID) error {
 	if nomineeID == uuid.Nil {
 		return ErrInvalidID
 	}
-	return s.repo.SetCategories(ctx, nomineeID, categoryIDs)
+
+	current, err := s.repo.GetCategoriesForNominee(ctx, nomineeID)
+	if err != nil {
+		return fmt.Errorf("failed to get nominee categories: %w", err)
+	}
+	linked := make(map[uuid.UUID]bool, len(current))
+	for _, category := range current {
+		linked[category.CategoryID] = true
+	}
+	wanted := make(map[uuid.UUID]bool, len(categoryIDs))
+	var added, removed []uuid.UUID
+	for _, id := range categoryIDs {
+		if !linked[id] && !wanted[id] {
+			added = append(added, id)
+		}
+		wanted[id] = true
+	}
+	for _, category := range current {
+		if !wanted[category.CategoryID] {
+			removed = append(removed, category.CategoryID)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	if err := s.checkCategoriesExist(ctx, added); err != nil {
+		return err
+	}
+	if err := s.checkNoVotes(ctx, nomineeID, removed); err != nil {
+		return err
+	}
+	return s.repo.UpdateCategories(ctx, nomineeID, added, removed)
+}
+
+// checkCategoriesExist returns ErrCategoryNotFound unless every category in
+// categoryIDs exists and is not deleted.
+func (s *nomineeCategoryService) checkCategoriesExist(ctx context.Context, categoryIDs []uuid.UUID) error {
+	for _, categoryID := range categoryIDs {
+		category, err := s.categoryRepo.GetByID(ctx, categoryID)
+		if err != nil {
+			return fmt.Errorf("failed to validate category: %w", err)
+		}
+		if category == nil {
+			return ErrCategoryNotFound
+		}
+	}
+	return nil
+}
+
+// checkNoVotes returns ErrCategoryLinkHasVotes if the nominee has votes in
+// any of categoryIDs. Removing such a link would orphan the votes, bypassing
+// vote deletion and its ledger entries.
+func (s *nomineeCategoryService) checkNoVotes(ctx context.Context, nomineeID uuid.UUID, categoryIDs []uuid.UUID) error {
+	if len(categoryIDs) == 0 {
+		return nil
+	}
+	votes, err := s.repo.CountVotes(ctx, nomineeID, categoryIDs)
+	if err != nil {
+		return fmt.Errorf("failed to count votes: %w", err)
+	}
+	if votes > 0 {
+		return fmt.Errorf("%w: %d votes would be lost", ErrCategoryLinkHasVotes, votes)
+	}
+	return nil
 }
 
 func (s *nomineeCategoryService) GetCategories(ctx context.Context, nomineeID uuid.UUID) ([]models.Category, error) {
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNomineeCategoryService_AddCategory(t *testing.T) {
+	nomineeID, categoryID := uuid.New(), uuid.New()
+	setup := func(category *models.Category) (*MockNomineeCategoryRepository, NomineeCategoryService) {
+		repo, categoryRepo := new(MockNomineeCategoryRepository), new(MockCategoryRepository)
+		categoryRepo.On("GetByID", mock.Anything, categoryID).Return(category, nil)
+		repo.On("AddCategory", mock.Anything, nomineeID, categoryID).Return(nil)
+		return repo, NewNomineeCategoryService(repo, categoryRepo)
+	}
+
+	t.Run("unknown category", func(t *testing.T) {
+		repo, service := setup(nil)
+
+		err := service.AddCategory(context.Background(), nomineeID, categoryID)
+
+		assert.ErrorIs(t, err, ErrCategoryNotFound)
+		repo.AssertNotCalled(t, "AddCategory", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("existing category", func(t *testing.T) {
+		repo, service := setup(&models.Category{CategoryID: categoryID})
+
+		err := service.AddCategory(context.Background(), nomineeID, categoryID)
+
+		assert.NoError(t, err)
+		repo.AssertCalled(t, "AddCategory", mock.Anything, nomineeID, categoryID)
+	})
+}
+
+func TestNomineeCategoryService_RemoveCategory(t *testing.T) {
+	nomineeID, categoryID := uuid.New(), uuid.New()
+	setup := func(votes int64) (*MockNomineeCategoryRepository, NomineeCategoryService) {
+		repo := new(MockNomineeCategoryRepository)
+		repo.On("CountVotes", mock.Anything, nomineeID, []uuid.UUID{categoryID}).Return(votes, nil)
+		repo.On("RemoveCategory", mock.Anything, nomineeID, categoryID).Return(nil)
+		return repo, NewNomineeCategoryService(repo, new(MockCategoryRepository))
+	}
+
+	t.Run("refuses a link with votes", func(t *testing.T) {
+		repo, service := setup(2)
+
+		err := service.RemoveCategory(context.Background(), nomineeID, categoryID)
+
+		assert.ErrorIs(t, err, ErrCategoryLinkHasVotes)
+		repo.AssertNotCalled(t, "RemoveCategory", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("without votes", func(t *testing.T) {
+		repo, service := setup(0)
+
+		err := service.RemoveCategory(context.Background(), nomineeID, categoryID)
+
+		assert.NoError(t, err)
+		repo.AssertCalled(t, "RemoveCategory", mock.Anything, nomineeID, categoryID)
+	})
+}
+
+func TestNomineeCategoryService_SetCategories(t *testing.T) {
+	nomineeID := uuid.New()
+	kept, dropped, added := uuid.New(), uuid.New(), uuid.New()
+	unknown := uuid.New()
+	setup := func(votes int64) (*MockNomineeCategoryRepository, NomineeCategoryService) {
+		repo, categoryRepo := new(MockNomineeCategoryRepository), new(MockCategoryRepository)
+		repo.On("GetCategoriesForNominee", mock.Anything, nomineeID).
+			Return([]models.Category{{CategoryID: kept}, {CategoryID: dropped}}, nil)
+		repo.On("CountVotes", mock.Anything, nomineeID, []uuid.UUID{dropped}).Return(votes, nil)
+		repo.On("UpdateCategories", mock.Anything, nomineeID, mock.Anything, mock.Anything).Return(nil)
+		categoryRepo.On("GetByID", mock.Anything, added).Return(&models.Category{CategoryID: added}, nil)
+		categoryRepo.On("GetByID", mock.Anything, unknown).Return(nil, nil)
+		return repo, NewNomineeCategoryService(repo, categoryRepo)
+	}
+
+	t.Run("only changes the links that differ", func(t *testing.T) {
+		repo, service := setup(0)
+
+		err := service.SetCategories(context.Background(), nomineeID, []uuid.UUID{kept, added, added})
+
+		assert.NoError(t, err)
+		repo.AssertCalled(t, "UpdateCategories", mock.Anything, nomineeID, []uuid.UUID{added}, []uuid.UUID{dropped})
+	})
+
+	t.Run("refuses to drop a link with votes", func(t *testing.T) {
+		repo, service := setup(5)
+
+		err := service.SetCategories(context.Background(), nomineeID, []uuid.UUID{kept, added})
+
+		assert.ErrorIs(t, err, ErrCategoryLinkHasVotes)
+		repo.AssertNotCalled(t, "UpdateCategories", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		repo, service := setup(0)
+
+		err := service.SetCategories(context.Background(), nomineeID, []uuid.UUID{kept, dropped, unknown})
+
+		assert.ErrorIs(t, err, ErrCategoryNotFound)
+		repo.AssertNotCalled(t, "UpdateCategories", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		repo, service := setup(5)
+
+		err := service.SetCategories(context.Background(), nomineeID, []uuid.UUID{dropped, kept})
+
+		assert.NoError(t, err)
+		repo.AssertNotCalled(t, "CountVotes", mock.Anything, mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "UpdateCategories", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
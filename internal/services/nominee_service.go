@@ -15,12 +15,14 @@ import (
 var (
 	ErrNomineeNotFound = errors.New("nominee not found")
 	ErrInvalidJSON     = errors.New("invalid JSON data")
+	ErrNomineeHasVotes = errors.New("nominee has votes")
 )
 
 type NomineeService interface {
 	CreateNominee(ctx context.Context, req dtos.CreateNomineeRequest) (*models.Nominee, error)
 	UpdateNominee(ctx context.Context, nomineeID uuid.UUID, req dtos.UpdateNomineeRequest) (*models.Nominee, error)
-	DeleteNominee(ctx context.Context, nomineeID uuid.UUID) error
+	DeleteNominee(ctx context.Context, nomineeID uuid.UUID, force bool) error
+	RestoreNominee(ctx context.Context, nomineeID uuid.UUID) (*models.Nominee, error)
 	GetNomineeDetails(ctx context.Context, nomineeID uuid.UUID) (*models.Nominee, error)
 	GetAllNominees(ctx context.Context) ([]models.Nominee, error)
 }
@@ -75,7 +77,7 @@ func (s *nomineeService) CreateNominee(ctx context.Context, req dtos.CreateNomin
 
 	// Set categories if provided
 	if len(req.CategoryIDs) > 0 {
-		if err := s.nomineeCategoryRepo.SetCategories(ctx, nominee.NomineeID, req.CategoryIDs); err != nil {
+		if err := s.nomineeCategoryRepo.UpdateCategories(ctx, nominee.NomineeID, req.CategoryIDs, nil); err != nil {
 			return nil, fmt.Errorf("failed to set categories: %w", err)
 		}
 	}
@@ -132,13 +134,47 @@ func (s *nomineeService) UpdateNominee(ctx context.Context, nomineeID uuid.UUID,
 	return s.repo.GetByID(ctx, nomineeID)
 }
 
-func (s *nomineeService) DeleteNominee(ctx context.Context, nomineeID uuid.UUID) error {
+// DeleteNominee soft deletes a nominee. A nominee with votes is only deleted
+// when force is set; its votes are kept but stop being counted until the
+// nominee is restored.
+func (s *nomineeService) DeleteNominee(ctx context.Context, nomineeID uuid.UUID, force bool) error {
+	nominee, err := s.repo.GetByID(ctx, nomineeID)
+	if err != nil {
+		return fmt.Errorf("failed to get nominee: %w", err)
+	}
+	if nominee == nil {
+		return ErrNomineeNotFound
+	}
+
+	if !force {
+		votes, err := s.repo.CountVotes(ctx, nomineeID)
+		if err != nil {
+			return fmt.Errorf("failed to count nominee votes: %w", err)
+		}
+		if votes > 0 {
+			return fmt.Errorf("%w: %d votes would stop counting", ErrNomineeHasVotes, votes)
+		}
+	}
+
 	if err := s.repo.Delete(ctx, nomineeID); err != nil {
 		return fmt.Errorf("failed to delete nominee: %w", err)
 	}
 	return nil
 }
 
+// RestoreNominee brings back a deleted nominee with its category links and
+// votes.
+func (s *nomineeService) RestoreNominee(ctx context.Context, nomineeID uuid.UUID) (*models.Nominee, error) {
+	restored, err := s.repo.Restore(ctx, nomineeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore nominee: %w", err)
+	}
+	if !restored {
+		return nil, ErrNomineeNotFound
+	}
+	return s.repo.GetByID(ctx, nomineeID)
+}
+
 func (s *nomineeService) GetNomineeDetails(ctx context.Context, nomineeID uuid.UUID) (*models.Nominee, error) {
 	nominee, err := s.repo.GetByID(ctx, nomineeID)
 	if err != nil {
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/oidc"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/nyashahama/music-awards/internal/security"
+	"github.com/nyashahama/music-awards/internal/validation"
+)
+
+// oidcLoginTTL is how long the user has to sign in at the provider
+const oidcLoginTTL = 10 * time.Minute
+
+var (
+	ErrUnknownProvider   = errors.New("unknown sign-in provider")
+	ErrInvalidLoginState = errors.New("sign-in request is invalid or has expired")
+)
+
+// OIDCProvider is an OpenID Connect provider users can sign in with.
+// *oidc.Provider implements it.
+type OIDCProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Claims, error)
+}
+
+// OIDCService signs users in through OpenID Connect providers using the
+// authorization code flow with PKCE.
+type OIDCService interface {
+	StartLogin(ctx context.Context, provider string) (string, error)
+	CompleteLogin(ctx context.Context, provider, state, code string) (*AuthTokens, error)
+}
+
+type oidcService struct {
+	providers    map[string]OIDCProvider
+	userRepo     repositories.UserRepository
+	editionRepo  repositories.EditionRepository
+	identityRepo repositories.IdentityRepository
+	sessionRepo  repositories.SessionRepository
+	uow          repositories.UnitOfWork
+	now          func() time.Time
+}
+
+func NewOIDCService(
+	userRepo repositories.UserRepository,
+	editionRepo repositories.EditionRepository,
+	identityRepo repositories.IdentityRepository,
+	sessionRepo repositories.SessionRepository,
+	uow repositories.UnitOfWork,
+	providers []OIDCProvider,
+) OIDCService {
+	byName := make(map[string]OIDCProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &oidcService{
+		providers:    byName,
+		userRepo:     userRepo,
+		editionRepo:  editionRepo,
+		identityRepo: identityRepo,
+		sessionRepo:  sessionRepo,
+		uow:          uow,
+		now:          time.Now,
+	}
+}
+
+// StartLogin records a sign-in request and returns the provider URL to send
+// the user to. Only a hash of the state is stored, and the nonce and PKCE
+// verifier stay on the server.
+func (s *oidcService) StartLogin(ctx context.Context, name string) (string, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+
+	state, stateHash, err := security.NewToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, _, err := security.NewToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, _, err := security.NewToken()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", err
+	}
+
+	request := &models.OIDCLoginRequest{
+		StateHash:    stateHash,
+		Provider:     name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    s.now().Add(oidcLoginTTL),
+	}
+	if err := s.identityRepo.CreateLoginRequest(ctx, request); err != nil {
+		return "", fmt.Errorf("failed to store sign-in request: %w", err)
+	}
+	return authURL, nil
+}
+
+// CompleteLogin handles the provider's redirect back. It exchanges the code
+// for the user's identity, finds or creates the user it belongs to, and
+// signs them in as Login does.
+func (s *oidcService) CompleteLogin(ctx context.Context, name, state, code string) (*AuthTokens, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	if state == "" || code == "" {
+		return nil, ErrInvalidLoginState
+	}
+
+	request, err := s.identityRepo.ConsumeLoginRequest(ctx, name, security.HashToken(state), s.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sign-in request: %w", err)
+	}
+	if request == nil {
+		return nil, ErrInvalidLoginState
+	}
+
+	claims, err := provider.Exchange(ctx, code, request.CodeVerifier, request.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userForIdentity(ctx, name, claims)
+	if err != nil {
+		return nil, err
+	}
+	return signIn(ctx, s.uow, s.sessionRepo, user, s.now())
+}
+
+// userForIdentity returns the user linked to the identity, linking or
+// creating one on first sign-in. An identity is only linked to an existing
+// account when both the provider and the account have verified the email,
+// so nobody can take over an account by registering its email first.
+func (s *oidcService) userForIdentity(ctx context.Context, provider string, claims *oidc.Claims) (*models.User, error) {
+	now := s.now()
+
+	identity, err := s.identityRepo.GetByProviderSubject(ctx, provider, claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+	if identity != nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return nil, ErrInvalidCredentials
+		}
+		if err := s.identityRepo.RecordLogin(ctx, identity.IdentityID, now); err != nil {
+			return nil, fmt.Errorf("failed to record sign-in: %w", err)
+		}
+		return user, nil
+	}
+
+	email := strings.ToLower(claims.Email)
+	if !validation.ValidateEmail(email) {
+		return nil, fmt.Errorf("%w: provider did not share an email address", oidc.ErrLoginFailed)
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if user != nil && (!claims.EmailVerified || user.EmailVerifiedAt == nil) {
+		return nil, ErrEmailExists
+	}
+
+	create := user == nil
+	if create {
+		if user, err = s.newOIDCUser(ctx, email, claims, now); err != nil {
+			return nil, err
+		}
+	}
+
+	identity = &models.Identity{
+		IdentityID:  uuid.New(),
+		UserID:      user.UserID,
+		Provider:    provider,
+		Subject:     claims.Subject,
+		Email:       email,
+		LastLoginAt: &now,
+	}
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		if create {
+			if err := tx.Users().Create(ctx, user); err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+			if err := recordRegistrationAudit(ctx, tx.Audits(), user.UserID); err != nil {
+				return fmt.Errorf("failed to audit registration: %w", err)
+			}
+		}
+		if err := tx.Identities().Create(ctx, identity); err != nil {
+			return fmt.Errorf("failed to link identity: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// newOIDCUser builds the account for a first sign-in. It gets a random
+// password, so it can only sign in with a password after a reset.
+func (s *oidcService) newOIDCUser(ctx context.Context, email string, claims *oidc.Claims, now time.Time) (*models.User, error) {
+	password, _, err := security.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash the password: %w", err)
+	}
+
+	budget := models.DefaultVoteBudget
+	edition, err := s.editionRepo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active edition: %w", err)
+	}
+	if edition != nil {
+		budget = edition.BudgetFor(models.RoleUser)
+	}
+
+	user := &models.User{
+		UserID:         uuid.New(),
+		Email:          email,
+		PasswordHash:   hashedPassword,
+		Role:           models.RoleUser,
+		AvailableVotes: budget,
+	}
+	// Usernames are unique, so the user's ID is appended to the name the
+	// provider suggests
+	user.Username = oidcUsername(claims, email) + "-" + user.UserID.String()[:8]
+	if claims.EmailVerified {
+		user.EmailVerifiedAt = &now
+	}
+	return user, nil
+}
+
+func oidcUsername(claims *oidc.Claims, email string) string {
+	for _, name := range []string{claims.PreferredUsername, claims.Name} {
+		if name = strings.Join(strings.Fields(name), "_"); name != "" {
+			return name
+		}
+	}
+	return email[:strings.Index(email, "@")]
+}
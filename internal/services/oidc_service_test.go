@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/oidc"
+	"github.com/nyashahama/music-awards/internal/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memIdentityRepository keeps identities and sign-in requests in memory.
+type memIdentityRepository struct {
+	identities []*models.Identity
+	requests   map[string]*models.OIDCLoginRequest
+}
+
+func newMemIdentityRepository() *memIdentityRepository {
+	return &memIdentityRepository{requests: make(map[string]*models.OIDCLoginRequest)}
+}
+
+func (r *memIdentityRepository) Create(ctx context.Context, identity *models.Identity) error {
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *memIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memIdentityRepository) RecordLogin(ctx context.Context, id uuid.UUID, at time.Time) error {
+	for _, identity := range r.identities {
+		if identity.IdentityID == id {
+			identity.LastLoginAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *memIdentityRepository) CreateLoginRequest(ctx context.Context, request *models.OIDCLoginRequest) error {
+	r.requests[request.StateHash] = request
+	return nil
+}
+
+func (r *memIdentityRepository) ConsumeLoginRequest(ctx context.Context, provider, stateHash string, now time.Time) (*models.OIDCLoginRequest, error) {
+	request, ok := r.requests[stateHash]
+	if !ok || request.Provider != provider || !request.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	delete(r.requests, stateHash)
+	return request, nil
+}
+
+var oidcNow = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type oidcTest struct {
+	server     *oidctest.Server
+	userRepo   *MockUserRepository
+	identities *memIdentityRepository
+	sessions   *MockSessionRepository
+	service    *oidcService
+}
+
+func setupOIDCTest(t *testing.T) *oidcTest {
+	server := oidctest.NewServer(t)
+	userRepo, sessionRepo := new(MockUserRepository), new(MockSessionRepository)
+	editionRepo := new(MockEditionRepository)
+	editionRepo.On("GetActive", mock.Anything).Return(nil, nil).Maybe()
+	sessionRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	identities := newMemIdentityRepository()
+	uow := &mockUnitOfWork{users: userRepo, audits: new(MockAuditRepository), identities: identities}
+
+	providers := []OIDCProvider{oidc.NewProvider(server.Config("test"), nil)}
+	service := NewOIDCService(userRepo, editionRepo, identities, sessionRepo, uow, providers).(*oidcService)
+	service.now = func() time.Time { return oidcNow }
+	return &oidcTest{server: server, userRepo: userRepo, identities: identities, sessions: sessionRepo, service: service}
+}
+
+// signIn runs the whole flow for identity, as the browser would.
+func (tt *oidcTest) signIn(t *testing.T, identity oidctest.Identity) (*AuthTokens, error) {
+	ctx := context.Background()
+	authURL, err := tt.service.StartLogin(ctx, "test")
+	require.NoError(t, err)
+	code, state := tt.server.Authorize(t, authURL, identity)
+	return tt.service.CompleteLogin(ctx, "test", state, code)
+}
+
+func TestOIDCService_FirstSignInCreatesUser(t *testing.T) {
+	tt := setupOIDCTest(t)
+	identity := oidctest.Identity{Subject: "fan-1", Email: "Fan@Example.com", EmailVerified: true, Name: "A Fan"}
+
+	var created *models.User
+	tt.userRepo.On("GetByEmail", mock.Anything, "fan@example.com").Return(nil, nil).Once()
+	tt.userRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*models.User)
+	}).Return(nil).Once()
+
+	tokens, err := tt.signIn(t, identity)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	require.NotNil(t, created)
+	assert.Equal(t, "fan@example.com", created.Email)
+	assert.Equal(t, models.RoleUser, created.Role)
+	assert.True(t, strings.HasPrefix(created.Username, "A_Fan-"))
+	assert.Equal(t, &oidcNow, created.EmailVerifiedAt)
+	require.Len(t, tt.identities.identities, 1)
+	assert.Equal(t, created.UserID, tt.identities.identities[0].UserID)
+	assert.Equal(t, "fan-1", tt.identities.identities[0].Subject)
+	tt.sessions.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(s *models.Session) bool {
+		return s.UserID == created.UserID
+	}))
+
+	t.Run("later sign-ins use the linked user", func(t *testing.T) {
+		tt.userRepo.On("GetByID", mock.Anything, created.UserID).Return(created, nil).Once()
+
+		_, err := tt.signIn(t, identity)
+
+		require.NoError(t, err)
+		assert.Len(t, tt.identities.identities, 1)
+		tt.userRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+}
+
+func TestOIDCService_LinksVerifiedEmail(t *testing.T) {
+	tt := setupOIDCTest(t)
+	user := &models.User{UserID: uuid.New(), Email: "fan@example.com", Role: models.RoleUser, EmailVerifiedAt: &oidcNow}
+	tt.userRepo.On("GetByEmail", mock.Anything, "fan@example.com").Return(user, nil)
+
+	_, err := tt.signIn(t, oidctest.Identity{Subject: "fan-1", Email: "fan@example.com", EmailVerified: true})
+
+	require.NoError(t, err)
+	require.Len(t, tt.identities.identities, 1)
+	assert.Equal(t, user.UserID, tt.identities.identities[0].UserID)
+	tt.userRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestOIDCService_DoesNotLinkUnverifiedEmail(t *testing.T) {
+	verified := &models.User{UserID: uuid.New(), Email: "fan@example.com", EmailVerifiedAt: &oidcNow}
+	unverified := &models.User{UserID: uuid.New(), Email: "fan@example.com"}
+
+	tests := []struct {
+		name             string
+		user             *models.User
+		providerVerified bool
+	}{
+		{"provider has not verified the email", verified, false},
+		{"account has not verified the email", unverified, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := setupOIDCTest(t)
+			tt.userRepo.On("GetByEmail", mock.Anything, "fan@example.com").Return(tc.user, nil)
+
+			_, err := tt.signIn(t, oidctest.Identity{Subject: "fan-1", Email: "fan@example.com", EmailVerified: tc.providerVerified})
+
+			assert.ErrorIs(t, err, ErrEmailExists)
+			assert.Empty(t, tt.identities.identities)
+			tt.sessions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestOIDCService_RejectsInvalidState(t *testing.T) {
+	ctx := context.Background()
+	identity := oidctest.Identity{Subject: "fan-1", Email: "fan@example.com"}
+
+	t.Run("unknown provider", func(t *testing.T) {
+		tt := setupOIDCTest(t)
+		_, err := tt.service.StartLogin(ctx, "other")
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+		_, err = tt.service.CompleteLogin(ctx, "other", "state", "code")
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+
+	t.Run("state that was never issued", func(t *testing.T) {
+		tt := setupOIDCTest(t)
+		authURL, err := tt.service.StartLogin(ctx, "test")
+		require.NoError(t, err)
+		code, _ := tt.server.Authorize(t, authURL, identity)
+
+		_, err = tt.service.CompleteLogin(ctx, "test", "forged-state", code)
+		assert.ErrorIs(t, err, ErrInvalidLoginState)
+	})
+
+	t.Run("state used twice", func(t *testing.T) {
+		tt := setupOIDCTest(t)
+		tt.userRepo.On("GetByEmail", mock.Anything, mock.Anything).Return(nil, nil)
+		tt.userRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		authURL, err := tt.service.StartLogin(ctx, "test")
+		require.NoError(t, err)
+		code, state := tt.server.Authorize(t, authURL, identity)
+		_, err = tt.service.CompleteLogin(ctx, "test", state, code)
+		require.NoError(t, err)
+
+		_, err = tt.service.CompleteLogin(ctx, "test", state, code)
+		assert.ErrorIs(t, err, ErrInvalidLoginState)
+	})
+
+	t.Run("expired state", func(t *testing.T) {
+		tt := setupOIDCTest(t)
+		authURL, err := tt.service.StartLogin(ctx, "test")
+		require.NoError(t, err)
+		code, state := tt.server.Authorize(t, authURL, identity)
+		tt.service.now = func() time.Time { return oidcNow.Add(oidcLoginTTL) }
+
+		_, err = tt.service.CompleteLogin(ctx, "test", state, code)
+		assert.ErrorIs(t, err, ErrInvalidLoginState)
+	})
+}
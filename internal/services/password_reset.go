@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/nyashahama/music-awards/internal/security"
+	"github.com/nyashahama/music-awards/internal/validation"
+)
+
+// passwordResetTTL is how long a password reset link stays valid
+const passwordResetTTL = time.Hour
+
+var ErrInvalidResetToken = errors.New("password reset link is invalid or has expired")
+
+// ForgotPassword emails a password reset link, invalidating any earlier
+// link. It succeeds without sending anything when there is no such account,
+// so callers cannot probe which addresses are registered.
+func (s *userService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, strings.ToLower(email))
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	var link string
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		if err := tx.Tokens().Revoke(ctx, user.UserID, models.TokenPurposePasswordReset, s.now()); err != nil {
+			return fmt.Errorf("failed to revoke reset tokens: %w", err)
+		}
+		link, err = s.issueToken(ctx, tx.Tokens(), user.UserID, models.TokenPurposePasswordReset, passwordResetTTL, s.links.ResetPassword)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	go s.sendPasswordResetEmail(user.Email, link)
+	return nil
+}
+
+// ResetPassword sets a new password for the account a reset link was sent
+// to and ends all of its sessions. Following the link also proves the user
+// owns the email address, so it is marked verified.
+func (s *userService) ResetPassword(ctx context.Context, token, password string) error {
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+	// Checked first so that a rejected password does not use up the link
+	if err := validation.ValidatePassword(password); err != nil {
+		return fmt.Errorf("%w: %s", ErrPasswordValidation, err)
+	}
+	hashed, err := hashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := s.now()
+	return s.uow.Do(ctx, func(tx repositories.Tx) error {
+		consumed, err := tx.Tokens().Consume(ctx, models.TokenPurposePasswordReset, security.HashToken(token), now)
+		if err != nil {
+			return fmt.Errorf("failed to consume reset token: %w", err)
+		}
+		if consumed == nil {
+			return ErrInvalidResetToken
+		}
+		if err := tx.Tokens().Revoke(ctx, consumed.UserID, models.TokenPurposePasswordReset, now); err != nil {
+			return fmt.Errorf("failed to revoke reset tokens: %w", err)
+		}
+		if err := tx.Users().ResetPassword(ctx, consumed.UserID, hashed); err != nil {
+			return fmt.Errorf("failed to reset password: %w", err)
+		}
+		if err := tx.Sessions().RevokeAllForUser(ctx, consumed.UserID, now); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		if err := tx.Users().MarkEmailVerified(ctx, consumed.UserID, now); err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+		return nil
+	})
+}
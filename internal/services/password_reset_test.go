@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserService_ForgotPassword(t *testing.T) {
+	t.Run("known account gets a reset link", func(t *testing.T) {
+		user := createTestUser()
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		sent := make(chan sentEmail, 1)
+		service.sendPasswordResetEmail = func(recipient, link string) { sent <- sentEmail{recipient, link} }
+		service.links.ResetPassword = testVerifyEmailURL
+		userRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(user, nil)
+		tokenRepo.On("Revoke", mock.Anything, user.UserID, models.TokenPurposePasswordReset, verificationNow).Return(nil)
+		var stored *models.UserToken
+		tokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.UserToken")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(*models.UserToken) }).
+			Return(nil)
+
+		err := service.ForgotPassword(context.Background(), "TEST@example.com")
+
+		require.NoError(t, err)
+		email := <-sent
+		assert.Equal(t, user.Email, email.recipient)
+		token := tokenFromLink(t, email.link)
+		require.NotNil(t, stored)
+		assert.Equal(t, models.TokenPurposePasswordReset, stored.Purpose)
+		assert.Equal(t, security.HashToken(token), stored.TokenHash)
+		assert.Equal(t, verificationNow.Add(passwordResetTTL), stored.ExpiresAt)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		userRepo.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, nil)
+
+		assert.NoError(t, service.ForgotPassword(context.Background(), "nobody@example.com"))
+		tokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	hash := security.HashToken("abc")
+
+	t.Run("valid token", func(t *testing.T) {
+		user := createTestUser()
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		tokenRepo.On("Consume", mock.Anything, models.TokenPurposePasswordReset, hash, verificationNow).
+			Return(&models.UserToken{UserID: user.UserID}, nil)
+		tokenRepo.On("Revoke", mock.Anything, user.UserID, models.TokenPurposePasswordReset, verificationNow).Return(nil)
+		var newHash string
+		userRepo.On("ResetPassword", mock.Anything, user.UserID, mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { newHash = args.String(2) }).
+			Return(nil)
+		userRepo.On("MarkEmailVerified", mock.Anything, user.UserID, verificationNow).Return(nil)
+		sessionRepo := service.sessionRepo.(*MockSessionRepository)
+		sessionRepo.On("RevokeAllForUser", mock.Anything, user.UserID, verificationNow).Return(nil)
+
+		err := service.ResetPassword(context.Background(), "abc", "NewPass123!")
+
+		require.NoError(t, err)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte("NewPass123!")))
+		tokenRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+		sessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("used or expired token", func(t *testing.T) {
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+		tokenRepo.On("Consume", mock.Anything, models.TokenPurposePasswordReset, hash, verificationNow).Return(nil, nil)
+
+		err := service.ResetPassword(context.Background(), "abc", "NewPass123!")
+
+		assert.ErrorIs(t, err, ErrInvalidResetToken)
+		userRepo.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("weak password keeps the token", func(t *testing.T) {
+		userRepo, tokenRepo := new(MockUserRepository), new(MockTokenRepository)
+		service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, tokens: tokenRepo})
+
+		err := service.ResetPassword(context.Background(), "abc", "weak")
+
+		assert.ErrorIs(t, err, ErrPasswordValidation)
+		tokenRepo.AssertNotCalled(t, "Consume", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
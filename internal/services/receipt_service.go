@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/nyashahama/music-awards/internal/security"
+)
+
+var ErrInvalidReceipt = errors.New("invalid vote receipt")
+
+// Receipt verification outcomes
+const (
+	ReceiptCounted     = "counted"
+	ReceiptUnderReview = "under_review"
+	ReceiptVoid        = "void"
+	ReceiptMismatch    = "mismatch"
+)
+
+// ReceiptVerification tells a voter whether their receipt matches a vote that
+// counts towards the tally. It deliberately leaves out the vote's choice.
+type ReceiptVerification struct {
+	Status   string
+	Verified bool
+	Counted  bool
+}
+
+// ReceiptService issues signed vote receipts and verifies them
+type ReceiptService interface {
+	Issue(vote *models.Vote) string
+	Verify(ctx context.Context, code string) (*ReceiptVerification, error)
+}
+
+type receiptService struct {
+	voteRepo repositories.VoteRepository
+}
+
+func NewReceiptService(voteRepo repositories.VoteRepository) ReceiptService {
+	return &receiptService{voteRepo: voteRepo}
+}
+
+// Issue returns the receipt code for the vote as it stands. Changing the vote
+// invalidates earlier receipts.
+func (s *receiptService) Issue(vote *models.Vote) string {
+	return security.SignVoteReceipt(voteReceipt(vote))
+}
+
+// Verify checks a receipt against the recorded vote. A receipt for a vote
+// that has since been changed no longer matches.
+func (s *receiptService) Verify(ctx context.Context, code string) (*ReceiptVerification, error) {
+	voteID, signature, err := security.ParseVoteReceipt(code)
+	if err != nil {
+		return nil, ErrInvalidReceipt
+	}
+
+	vote, err := s.voteRepo.GetByID(ctx, voteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !security.VerifyVoteReceipt(voteReceipt(vote), signature) {
+		return &ReceiptVerification{Status: ReceiptMismatch}, nil
+	}
+
+	verification := &ReceiptVerification{Verified: true}
+	switch vote.Status {
+	case models.VoteStatusQuarantined:
+		verification.Status = ReceiptUnderReview
+	case models.VoteStatusVoid:
+		verification.Status = ReceiptVoid
+	default:
+		verification.Status = ReceiptCounted
+		verification.Counted = true
+	}
+	return verification, nil
+}
+
+// voteReceipt is what a receipt commits to: the ballot's full preference
+// order for ranked votes, otherwise the single pick.
+func voteReceipt(vote *models.Vote) security.VoteReceipt {
+	nominees := vote.RankedNominees()
+	if len(nominees) == 0 {
+		nominees = []uuid.UUID{vote.NomineeID}
+	}
+	return security.VoteReceipt{
+		VoteID:     vote.VoteID,
+		CategoryID: vote.CategoryID,
+		Nominees:   nominees,
+		CastAt:     vote.CreatedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestReceiptService_Verify(t *testing.T) {
+	castAt := time.Date(2025, 3, 1, 20, 0, 0, 987654321, time.UTC)
+	newVote := func(status string) *models.Vote {
+		return &models.Vote{
+			VoteID:     uuid.New(),
+			CategoryID: uuid.New(),
+			NomineeID:  uuid.New(),
+			Status:     status,
+			CreatedAt:  castAt,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		vote   *models.Vote
+		change func(vote *models.Vote)
+		want   ReceiptVerification
+	}{
+		{
+			name: "counted",
+			vote: newVote(models.VoteStatusValid),
+			want: ReceiptVerification{Status: ReceiptCounted, Verified: true, Counted: true},
+		},
+		{
+			name: "quarantined",
+			vote: newVote(models.VoteStatusQuarantined),
+			want: ReceiptVerification{Status: ReceiptUnderReview, Verified: true},
+		},
+		{
+			name: "void",
+			vote: newVote(models.VoteStatusVoid),
+			want: ReceiptVerification{Status: ReceiptVoid, Verified: true},
+		},
+		{
+			name:   "changed since the receipt was issued",
+			vote:   newVote(models.VoteStatusValid),
+			change: func(vote *models.Vote) { vote.NomineeID = uuid.New() },
+			want:   ReceiptVerification{Status: ReceiptMismatch},
+		},
+		{
+			name: "ranked ballot reordered",
+			vote: func() *models.Vote {
+				vote := newVote(models.VoteStatusValid)
+				vote.Rankings = newBallotRankings(vote.VoteID, []uuid.UUID{vote.NomineeID, uuid.New()})
+				return vote
+			}(),
+			change: func(vote *models.Vote) {
+				vote.Rankings[0].NomineeID, vote.Rankings[1].NomineeID = vote.Rankings[1].NomineeID, vote.Rankings[0].NomineeID
+			},
+			want: ReceiptVerification{Status: ReceiptMismatch},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voteRepo := new(MockVoteRepository)
+			service := NewReceiptService(voteRepo)
+			receipt := service.Issue(tt.vote)
+
+			stored := *tt.vote
+			stored.Rankings = append([]models.BallotRanking(nil), tt.vote.Rankings...)
+			// Read back with the database's microsecond precision
+			stored.CreatedAt = castAt.Truncate(time.Microsecond)
+			if tt.change != nil {
+				tt.change(&stored)
+			}
+			voteRepo.On("GetByID", mock.Anything, tt.vote.VoteID).Return(&stored, nil)
+
+			verification, err := service.Verify(context.Background(), receipt)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, *verification)
+		})
+	}
+}
+
+func TestReceiptService_VerifyErrors(t *testing.T) {
+	voteRepo := new(MockVoteRepository)
+	service := NewReceiptService(voteRepo)
+
+	_, err := service.Verify(context.Background(), "not a receipt")
+	assert.ErrorIs(t, err, ErrInvalidReceipt)
+
+	deleted := &models.Vote{VoteID: uuid.New(), CategoryID: uuid.New(), NomineeID: uuid.New()}
+	voteRepo.On("GetByID", mock.Anything, deleted.VoteID).Return(nil, gorm.ErrRecordNotFound)
+	_, err = service.Verify(context.Background(), service.Issue(deleted))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
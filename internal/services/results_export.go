@@ -0,0 +1,194 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nyashahama/music-awards/internal/models"
+)
+
+// ResultsExport is an encoded results document ready for download.
+type ResultsExport struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type exportFormat struct {
+	contentType string
+	extension   string
+	encode      func(rows []exportRow) ([]byte, error)
+}
+
+const odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+var exportFormats = map[string]exportFormat{
+	"csv":  {"text/csv; charset=utf-8", "csv", encodeDelimited(',')},
+	"tsv":  {"text/tab-separated-values; charset=utf-8", "tsv", encodeDelimited('\t')},
+	"json": {"application/json", "json", encodeJSON},
+	"ods":  {odsMimeType, "ods", encodeODS},
+}
+
+// exportRow is one nominee's standing, flattened for tabular formats.
+type exportRow struct {
+	EditionYear int     `json:"edition_year"`
+	Category    string  `json:"category"`
+	Rank        int     `json:"rank"`
+	Nominee     string  `json:"nominee"`
+	Votes       int64   `json:"votes"`
+	Percentage  float64 `json:"percentage"`
+	Winner      bool    `json:"winner"`
+}
+
+var exportHeader = []string{"edition_year", "category", "rank", "nominee", "votes", "percentage", "winner"}
+
+func (r exportRow) fields() []string {
+	return []string{
+		strconv.Itoa(r.EditionYear),
+		r.Category,
+		strconv.Itoa(r.Rank),
+		r.Nominee,
+		strconv.FormatInt(r.Votes, 10),
+		strconv.FormatFloat(r.Percentage, 'f', 2, 64),
+		strconv.FormatBool(r.Winner),
+	}
+}
+
+func newExportRows(year int, results []models.CategoryResults) []exportRow {
+	var rows []exportRow
+	for _, category := range results {
+		for _, nominee := range category.Nominees {
+			rows = append(rows, exportRow{
+				EditionYear: year,
+				Category:    category.CategoryName,
+				Rank:        nominee.Rank,
+				Nominee:     nominee.NomineeName,
+				Votes:       nominee.Votes,
+				Percentage:  nominee.Percentage,
+				Winner:      nominee.Winner,
+			})
+		}
+	}
+	return rows
+}
+
+func encodeDelimited(sep rune) func([]exportRow) ([]byte, error) {
+	return func(rows []exportRow) ([]byte, error) {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Comma = sep
+		if err := w.Write(exportHeader); err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if err := w.Write(row.fields()); err != nil {
+				return nil, err
+			}
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	}
+}
+
+func encodeJSON(rows []exportRow) ([]byte, error) {
+	if rows == nil {
+		rows = []exportRow{}
+	}
+	return json.MarshalIndent(rows, "", "  ")
+}
+
+const odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+ <manifest:file-entry manifest:full-path="/" manifest:media-type="application/vnd.oasis.opendocument.spreadsheet"/>
+ <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>`
+
+// encodeODS writes a single-sheet OpenDocument spreadsheet. Numeric columns
+// are typed as floats so spreadsheet applications can sort and sum them.
+func encodeODS(rows []exportRow) ([]byte, error) {
+	var content strings.Builder
+	content.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" office:version="1.2">
+<office:body><office:spreadsheet><table:table table:name="Results">`)
+
+	writeRow := func(cells []string, numeric map[int]bool) error {
+		content.WriteString("<table:table-row>")
+		for i, cell := range cells {
+			var escaped bytes.Buffer
+			if err := xml.EscapeText(&escaped, []byte(cell)); err != nil {
+				return err
+			}
+			if numeric[i] {
+				fmt.Fprintf(&content, `<table:table-cell office:value-type="float" office:value="%s"><text:p>%s</text:p></table:table-cell>`, escaped.String(), escaped.String())
+			} else {
+				fmt.Fprintf(&content, `<table:table-cell office:value-type="string"><text:p>%s</text:p></table:table-cell>`, escaped.String())
+			}
+		}
+		content.WriteString("</table:table-row>")
+		return nil
+	}
+
+	if err := writeRow(exportHeader, nil); err != nil {
+		return nil, err
+	}
+	numeric := map[int]bool{0: true, 2: true, 4: true, 5: true}
+	for _, row := range rows {
+		if err := writeRow(row.fields(), numeric); err != nil {
+			return nil, err
+		}
+	}
+	content.WriteString("</table:table></office:spreadsheet></office:body></office:document-content>")
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	// The mimetype entry must come first and be stored uncompressed
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := mw.Write([]byte(odsMimeType)); err != nil {
+		return nil, err
+	}
+
+	files := []struct{ name, body string }{
+		{"META-INF/manifest.xml", odsManifest},
+		{"content.xml", content.String()},
+	}
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(f.body)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// exportFilenameSlug reduces a name to lowercase ASCII words joined by dashes.
+func exportFilenameSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -2,20 +2,354 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+)
+
+var (
+	ErrUnsupportedExportFormat = errors.New("unsupported export format")
+	ErrResultsHidden           = errors.New("results are not available")
+	ErrResultsEmbargoed        = errors.New("results are under embargo")
 )
 
 // ResultsService handles voting results and reporting
 type ResultsService interface {
-	GetCategoryResults(ctx context.Context, categoryID uuid.UUID) ([]models.Nominee, error)
-	GenerateVotingReport(ctx context.Context, filters map[string]interface{}) (*models.Vote, error)
-	ExportResults(ctx context.Context, format string) ([]byte, error)
-	GetHistoricalResults(ctx context.Context, year int) (map[uuid.UUID]models.Nominee, error)
-	GetRealTimeTallies(ctx context.Context) (map[uuid.UUID]models.Category, error)
+	GetCategoryResults(ctx context.Context, categoryID uuid.UUID) (*models.CategoryResults, error)
+	GetPublishedCategoryResults(ctx context.Context, categoryID uuid.UUID) (*models.CategoryResults, error)
+	ExportResults(ctx context.Context, format string, categoryID, editionID uuid.UUID) (*ResultsExport, error)
+	GetHistoricalResults(ctx context.Context, year int) ([]models.CategoryResults, error)
+	GetPublishedHistoricalResults(ctx context.Context, year int) ([]models.CategoryResults, error)
+	GetRealTimeTallies(ctx context.Context) ([]models.CategoryResults, error)
 }
 
 type resultsService struct {
-	// Dependencies
-}
\ No newline at end of file
+	voteRepo     repositories.VoteRepository
+	categoryRepo repositories.CategoryRepository
+	editionRepo  repositories.EditionRepository
+	now          func() time.Time
+}
+
+func NewResultsService(
+	voteRepo repositories.VoteRepository,
+	categoryRepo repositories.CategoryRepository,
+	editionRepo repositories.EditionRepository,
+) ResultsService {
+	return &resultsService{
+		voteRepo:     voteRepo,
+		categoryRepo: categoryRepo,
+		editionRepo:  editionRepo,
+		now:          time.Now,
+	}
+}
+
+// GetCategoryResults is the admin view of a category, available once its
+// results are in preview or published. Every staff read of results follows
+// the same rule, so hidden results are kept from staff as well.
+func (s *resultsService) GetCategoryResults(ctx context.Context, categoryID uuid.UUID) (*models.CategoryResults, error) {
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if !previewable(s.now())(category) {
+		return nil, ErrResultsHidden
+	}
+	return s.categoryResults(ctx, category)
+}
+
+// GetPublishedCategoryResults is the public view of a category. Hidden results
+// are reported as missing and previewed or scheduled ones as embargoed.
+func (s *resultsService) GetPublishedCategoryResults(ctx context.Context, categoryID uuid.UUID) (*models.CategoryResults, error) {
+	category, err := s.getCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch category.EffectiveResultsState(s.now()) {
+	case models.ResultsPublished:
+		return s.categoryResults(ctx, category)
+	case models.ResultsPreview:
+		return nil, ErrResultsEmbargoed
+	default:
+		if category.ResultsPublishAt != nil {
+			return nil, ErrResultsEmbargoed
+		}
+		return nil, ErrResultsHidden
+	}
+}
+
+func (s *resultsService) getCategory(ctx context.Context, categoryID uuid.UUID) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (s *resultsService) categoryResults(ctx context.Context, category *models.Category) (*models.CategoryResults, error) {
+	tallies, err := s.voteRepo.TallyByCategory(ctx, category.CategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tally votes: %w", err)
+	}
+
+	results, err := s.buildResults(ctx, category, tallies)
+	if err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
+
+// buildResults ranks a category's tallies, running the instant runoff for
+// ranked-choice categories.
+func (s *resultsService) buildResults(ctx context.Context, category *models.Category, tallies []repositories.NomineeTally) (models.CategoryResults, error) {
+	if !category.IsRankedChoice() {
+		return buildCategoryResults(category, tallies), nil
+	}
+
+	ballots, err := s.voteRepo.GetBallots(ctx, category.CategoryID)
+	if err != nil {
+		return models.CategoryResults{}, fmt.Errorf("failed to get ballots: %w", err)
+	}
+	return buildRankedCategoryResults(category, tallies, ballots), nil
+}
+
+// ExportResults encodes final standings as csv, tsv, json or ods. A non-nil
+// categoryID exports that category alone; otherwise the whole of editionID is
+// exported, defaulting to the active edition. Hidden results are left out.
+func (s *resultsService) ExportResults(ctx context.Context, format string, categoryID, editionID uuid.UUID) (*ResultsExport, error) {
+	encoding, ok := exportFormats[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedExportFormat, format)
+	}
+
+	var (
+		edition *models.Edition
+		results []models.CategoryResults
+		name    string
+		err     error
+	)
+	if categoryID != uuid.Nil {
+		category, err := s.getCategory(ctx, categoryID)
+		if err != nil {
+			return nil, err
+		}
+		if !previewable(s.now())(category) {
+			return nil, ErrResultsHidden
+		}
+		if edition, err = resolveEdition(ctx, s.editionRepo, category.EditionID); err != nil {
+			return nil, err
+		}
+		single, err := s.categoryResults(ctx, category)
+		if err != nil {
+			return nil, err
+		}
+		results = []models.CategoryResults{*single}
+		name = fmt.Sprintf("results-%d-%s", edition.Year, exportFilenameSlug(category.Name))
+	} else {
+		if edition, err = resolveEdition(ctx, s.editionRepo, editionID); err != nil {
+			return nil, err
+		}
+		if results, err = s.editionResults(ctx, edition.EditionID, previewable(s.now())); err != nil {
+			return nil, err
+		}
+		name = fmt.Sprintf("results-%d", edition.Year)
+	}
+
+	data, err := encoding.encode(newExportRows(edition.Year, results))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	return &ResultsExport{
+		Filename:    name + "." + encoding.extension,
+		ContentType: encoding.contentType,
+		Data:        data,
+	}, nil
+}
+
+// GetHistoricalResults is the admin view of the year's edition, leaving out
+// categories whose results are hidden.
+func (s *resultsService) GetHistoricalResults(ctx context.Context, year int) ([]models.CategoryResults, error) {
+	edition, err := s.getEditionByYear(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	return s.editionResults(ctx, edition.EditionID, previewable(s.now()))
+}
+
+// GetPublishedHistoricalResults returns only the categories of the year's
+// edition whose results have been published.
+func (s *resultsService) GetPublishedHistoricalResults(ctx context.Context, year int) ([]models.CategoryResults, error) {
+	edition, err := s.getEditionByYear(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	return s.editionResults(ctx, edition.EditionID, func(category *models.Category) bool {
+		return category.EffectiveResultsState(now) == models.ResultsPublished
+	})
+}
+
+func (s *resultsService) getEditionByYear(ctx context.Context, year int) (*models.Edition, error) {
+	edition, err := s.editionRepo.GetByYear(ctx, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edition: %w", err)
+	}
+	if edition == nil {
+		return nil, ErrEditionNotFound
+	}
+	return edition, nil
+}
+
+// GetRealTimeTallies returns the current standings of every category in the
+// active edition whose results are not hidden.
+func (s *resultsService) GetRealTimeTallies(ctx context.Context) ([]models.CategoryResults, error) {
+	edition, err := resolveEdition(ctx, s.editionRepo, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.editionResults(ctx, edition.EditionID, previewable(s.now()))
+}
+
+// previewable accepts the categories staff may see results for at now: those
+// in preview or published.
+func previewable(now time.Time) func(*models.Category) bool {
+	return func(category *models.Category) bool {
+		return category.EffectiveResultsState(now) != models.ResultsHidden
+	}
+}
+
+// editionResults tallies every category of an edition, keeping only the
+// categories accepted by include when it is non-nil.
+func (s *resultsService) editionResults(ctx context.Context, editionID uuid.UUID, include func(*models.Category) bool) ([]models.CategoryResults, error) {
+	categories, err := s.categoryRepo.GetByEdition(ctx, editionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	tallies, err := s.voteRepo.TallyByEdition(ctx, editionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tally votes: %w", err)
+	}
+
+	byCategory := make(map[uuid.UUID][]repositories.NomineeTally, len(categories))
+	for _, tally := range tallies {
+		byCategory[tally.CategoryID] = append(byCategory[tally.CategoryID], tally)
+	}
+
+	results := make([]models.CategoryResults, 0, len(categories))
+	for i := range categories {
+		if include != nil && !include(&categories[i]) {
+			continue
+		}
+		categoryResults, err := s.buildResults(ctx, &categories[i], byCategory[categories[i].CategoryID])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, categoryResults)
+	}
+	return results, nil
+}
+
+// buildCategoryResults ranks a category's tallies. Equal scores share a rank
+// (1, 1, 3, ...), and every nominee sharing a non-zero first place is marked
+// as a winner. The score is the vote count, or the weighted share of jury and
+// public votes when the category is blended.
+func buildCategoryResults(category *models.Category, tallies []repositories.NomineeTally) models.CategoryResults {
+	results := models.CategoryResults{
+		CategoryID:   category.CategoryID,
+		CategoryName: category.Name,
+		EditionID:    category.EditionID,
+		VotingMethod: models.VotingMethodSingle,
+		Nominees:     make([]models.NomineeResult, len(tallies)),
+	}
+
+	for i, tally := range tallies {
+		results.TotalVotes += tally.Votes
+		results.JuryVotes += tally.JuryVotes
+		results.PublicVotes += tally.PublicVotes
+		results.Nominees[i] = models.NomineeResult{
+			NomineeID:   tally.NomineeID,
+			NomineeName: tally.NomineeName,
+			Votes:       tally.Votes,
+			Percentage:  tally.Percentage,
+			Jury:        models.VoteShare{Votes: tally.JuryVotes},
+			Public:      models.VoteShare{Votes: tally.PublicVotes},
+		}
+	}
+
+	score := func(n models.NomineeResult) float64 { return float64(n.Votes) }
+	if category.IsBlended() {
+		blendShares(&results, category.JuryWeight)
+		score = func(n models.NomineeResult) float64 { return n.Percentage }
+	}
+
+	// Tallies arrive sorted by vote count; a blend can reorder them
+	sort.SliceStable(results.Nominees, func(i, j int) bool {
+		return score(results.Nominees[i]) > score(results.Nominees[j])
+	})
+
+	winners := 0
+	for i := range results.Nominees {
+		nominee := &results.Nominees[i]
+		nominee.Rank = i + 1
+		if i > 0 && score(*nominee) == score(results.Nominees[i-1]) {
+			nominee.Rank = results.Nominees[i-1].Rank
+		}
+
+		nominee.Winner = nominee.Rank == 1 && score(*nominee) > 0
+		if nominee.Winner {
+			winners++
+		}
+	}
+
+	results.Tied = winners > 1
+	return results
+}
+
+// blendShares turns each nominee's jury and public votes into shares of their
+// class and sets Percentage to juryWeight times the jury share plus the rest
+// times the public share. When only one class has voted its share counts in
+// full, so percentages still sum to 100.
+func blendShares(results *models.CategoryResults, juryWeight float64) {
+	results.JuryWeight = juryWeight
+
+	juryWeight, publicWeight := juryWeight, 1-juryWeight
+	switch {
+	case results.JuryVotes == 0 && results.PublicVotes == 0:
+		juryWeight, publicWeight = 0, 0
+	case results.JuryVotes == 0:
+		juryWeight, publicWeight = 0, 1
+	case results.PublicVotes == 0:
+		juryWeight, publicWeight = 1, 0
+	}
+
+	for i := range results.Nominees {
+		nominee := &results.Nominees[i]
+		nominee.Jury.Share = sharePercentage(nominee.Jury.Votes, results.JuryVotes)
+		nominee.Public.Share = sharePercentage(nominee.Public.Votes, results.PublicVotes)
+		nominee.Percentage = roundPercentage(juryWeight*nominee.Jury.Share + publicWeight*nominee.Public.Share)
+	}
+}
+
+func sharePercentage(votes, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return roundPercentage(float64(votes) * 100 / float64(total))
+}
+
+func roundPercentage(p float64) float64 {
+	return math.Round(p*100) / 100
+}
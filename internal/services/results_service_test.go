@@ -0,0 +1,408 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/rbac"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCategoryResults(t *testing.T) {
+	category := &models.Category{CategoryID: uuid.New(), Name: "Best Hip Hop Album"}
+
+	tests := []struct {
+		name        string
+		votes       []int64
+		wantRanks   []int
+		wantWinners []bool
+		wantTied    bool
+	}{
+		{"clear winner", []int64{5, 3, 1}, []int{1, 2, 3}, []bool{true, false, false}, false},
+		{"tied first place", []int64{4, 4, 2}, []int{1, 1, 3}, []bool{true, true, false}, true},
+		{"tie further down", []int64{6, 2, 2, 1}, []int{1, 2, 2, 4}, []bool{true, false, false, false}, false},
+		{"no votes yet", []int64{0, 0}, []int{1, 1}, []bool{false, false}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tallies := make([]repositories.NomineeTally, len(tt.votes))
+			var total int64
+			for i, v := range tt.votes {
+				tallies[i] = repositories.NomineeTally{CategoryID: category.CategoryID, NomineeID: uuid.New(), Votes: v}
+				total += v
+			}
+
+			results := buildCategoryResults(category, tallies)
+
+			assert.Equal(t, total, results.TotalVotes)
+			assert.Equal(t, tt.wantTied, results.Tied)
+			for i, nominee := range results.Nominees {
+				assert.Equal(t, tt.wantRanks[i], nominee.Rank, "rank of nominee %d", i)
+				assert.Equal(t, tt.wantWinners[i], nominee.Winner, "winner flag of nominee %d", i)
+			}
+		})
+	}
+}
+
+func TestBuildCategoryResults_Blended(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+
+	t.Run("jury overturns the public vote", func(t *testing.T) {
+		category := &models.Category{CategoryID: uuid.New(), JuryWeight: 0.5}
+		tallies := []repositories.NomineeTally{
+			{NomineeID: a, Votes: 61, JuryVotes: 1, PublicVotes: 60},
+			{NomineeID: b, Votes: 43, JuryVotes: 3, PublicVotes: 40},
+		}
+
+		results := buildCategoryResults(category, tallies)
+
+		assert.Equal(t, 0.5, results.JuryWeight)
+		assert.Equal(t, int64(4), results.JuryVotes)
+		assert.Equal(t, int64(100), results.PublicVotes)
+		assert.Equal(t, b, results.Nominees[0].NomineeID)
+		assert.Equal(t, 57.5, results.Nominees[0].Percentage)
+		assert.Equal(t, models.VoteShare{Votes: 3, Share: 75}, results.Nominees[0].Jury)
+		assert.Equal(t, models.VoteShare{Votes: 40, Share: 40}, results.Nominees[0].Public)
+		assert.Equal(t, 42.5, results.Nominees[1].Percentage)
+		assert.True(t, results.Nominees[0].Winner)
+		assert.False(t, results.Tied)
+	})
+
+	t.Run("only the public has voted", func(t *testing.T) {
+		category := &models.Category{CategoryID: uuid.New(), JuryWeight: 0.7}
+		tallies := []repositories.NomineeTally{
+			{NomineeID: a, Votes: 3, PublicVotes: 3},
+			{NomineeID: b, Votes: 1, PublicVotes: 1},
+		}
+
+		results := buildCategoryResults(category, tallies)
+
+		assert.Equal(t, 75.0, results.Nominees[0].Percentage)
+		assert.Equal(t, 25.0, results.Nominees[1].Percentage)
+	})
+}
+
+func TestInstantRunoff(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	repeat := func(n int, ballot ...uuid.UUID) [][]uuid.UUID {
+		ballots := make([][]uuid.UUID, n)
+		for i := range ballots {
+			ballots[i] = ballot
+		}
+		return ballots
+	}
+	concat := func(groups ...[][]uuid.UUID) [][]uuid.UUID {
+		var ballots [][]uuid.UUID
+		for _, group := range groups {
+			ballots = append(ballots, group...)
+		}
+		return ballots
+	}
+
+	t.Run("first round majority", func(t *testing.T) {
+		rounds, winners := instantRunoff([]uuid.UUID{a, b}, concat(repeat(3, a), repeat(2, b)))
+
+		assert.Len(t, rounds, 1)
+		assert.Equal(t, []uuid.UUID{a}, winners)
+	})
+
+	t.Run("transfers decide the winner", func(t *testing.T) {
+		// a leads on first preferences but c's voters prefer b
+		ballots := concat(repeat(5, a), repeat(4, b, a), repeat(3, c, b), repeat(1, d, a))
+		rounds, winners := instantRunoff([]uuid.UUID{a, b, c, d}, ballots)
+
+		assert.Equal(t, []uuid.UUID{b}, winners)
+		assert.Len(t, rounds, 3)
+		assert.Equal(t, []uuid.UUID{d}, rounds[0].Eliminated)
+		assert.Equal(t, []uuid.UUID{c}, rounds[1].Eliminated)
+		assert.Empty(t, rounds[2].Eliminated)
+		assert.Equal(t, []models.RunoffTally{{NomineeID: b, Votes: 7}, {NomineeID: a, Votes: 6}}, rounds[2].Tallies)
+	})
+
+	t.Run("exhausted ballots leave a tie", func(t *testing.T) {
+		ballots := concat(repeat(2, a), repeat(2, b), repeat(1, c))
+		rounds, winners := instantRunoff([]uuid.UUID{a, b, c}, ballots)
+
+		assert.ElementsMatch(t, []uuid.UUID{a, b}, winners)
+		assert.Len(t, rounds, 2)
+		assert.Equal(t, int64(1), rounds[1].Exhausted)
+	})
+
+	t.Run("tie for last broken by earlier rounds", func(t *testing.T) {
+		// b and c tie at 3 in the second round, but c had fewer first
+		// preferences; eliminating both would have handed a the win
+		ballots := concat(repeat(4, a), repeat(3, b, c), repeat(2, c, b), repeat(1, d, c))
+		rounds, winners := instantRunoff([]uuid.UUID{a, b, c, d}, ballots)
+
+		assert.Equal(t, []uuid.UUID{b}, winners)
+		require.Len(t, rounds, 3)
+		assert.Equal(t, []uuid.UUID{d}, rounds[0].Eliminated)
+		assert.Equal(t, []uuid.UUID{c}, rounds[1].Eliminated)
+		assert.Equal(t, []models.RunoffTally{{NomineeID: b, Votes: 5}, {NomineeID: a, Votes: 4}}, rounds[2].Tallies)
+		assert.Equal(t, int64(1), rounds[2].Exhausted)
+	})
+
+	t.Run("tie for last in every round broken by ID", func(t *testing.T) {
+		// A=4, B=3, C=3 from the start
+		ballots := concat(repeat(4, a), repeat(3, b, c), repeat(3, c, b))
+		rounds, winners := instantRunoff([]uuid.UUID{a, b, c}, ballots)
+
+		last, next := b, c
+		if bytes.Compare(b[:], c[:]) < 0 {
+			last, next = c, b
+		}
+		assert.Equal(t, []uuid.UUID{next}, winners)
+		require.Len(t, rounds, 2)
+		assert.Equal(t, []uuid.UUID{last}, rounds[0].Eliminated)
+
+		again, _ := instantRunoff([]uuid.UUID{c, a, b}, ballots)
+		assert.Equal(t, rounds[0].Eliminated, again[0].Eliminated, "the result does not depend on order")
+	})
+
+	t.Run("no ballots", func(t *testing.T) {
+		rounds, winners := instantRunoff([]uuid.UUID{a, b}, nil)
+
+		assert.Len(t, rounds, 1)
+		assert.Nil(t, winners)
+	})
+}
+
+func TestBuildRankedCategoryResults(t *testing.T) {
+	category := &models.Category{CategoryID: uuid.New(), Name: "Album of the Year", VotingMethod: models.VotingMethodRanked}
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	tallies := []repositories.NomineeTally{
+		{NomineeID: a, NomineeName: "A", Votes: 2},
+		{NomineeID: b, NomineeName: "B", Votes: 2},
+		{NomineeID: c, NomineeName: "C", Votes: 1},
+	}
+	ballots := [][]uuid.UUID{{a}, {a}, {b}, {b}, {c, b}}
+
+	results := buildRankedCategoryResults(category, tallies, ballots)
+
+	assert.Equal(t, models.VotingMethodRanked, results.VotingMethod)
+	assert.Equal(t, int64(5), results.TotalVotes)
+	assert.False(t, results.Tied)
+	assert.Len(t, results.Rounds, 2)
+	assert.Equal(t, "B", results.Rounds[1].Tallies[0].NomineeName)
+
+	var order []uuid.UUID
+	var ranks []int
+	for _, nominee := range results.Nominees {
+		order = append(order, nominee.NomineeID)
+		ranks = append(ranks, nominee.Rank)
+	}
+	assert.Equal(t, []uuid.UUID{b, a, c}, order)
+	assert.Equal(t, []int{1, 2, 3}, ranks)
+	assert.True(t, results.Nominees[0].Winner)
+	assert.Equal(t, int64(2), results.Nominees[0].Votes, "votes are first preferences")
+}
+
+func TestResultsService_GetCategoryResults_UnknownCategory(t *testing.T) {
+	voteRepo := new(MockVoteRepository)
+	categoryRepo := new(MockCategoryRepository)
+	service := NewResultsService(voteRepo, categoryRepo, nil)
+
+	categoryID := uuid.New()
+	categoryRepo.On("GetByID", mock.Anything, categoryID).Return(nil, nil)
+
+	_, err := service.GetCategoryResults(context.Background(), categoryID)
+
+	assert.ErrorIs(t, err, ErrCategoryNotFound)
+	voteRepo.AssertNotCalled(t, "TallyByCategory", mock.Anything, mock.Anything)
+}
+
+func TestResultsService_PublicationStates(t *testing.T) {
+	now := time.Date(2025, 3, 2, 21, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		state        string
+		publishAt    *time.Time
+		wantPublic   error
+		wantAdminErr error
+	}{
+		{"hidden", models.ResultsHidden, nil, ErrResultsHidden, ErrResultsHidden},
+		{"admin preview", models.ResultsPreview, nil, ErrResultsEmbargoed, nil},
+		{"published", models.ResultsPublished, nil, nil, nil},
+		{"scheduled in the future", models.ResultsHidden, &future, ErrResultsEmbargoed, ErrResultsHidden},
+		{"schedule has passed", models.ResultsPreview, &past, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voteRepo := new(MockVoteRepository)
+			categoryRepo := new(MockCategoryRepository)
+			service := NewResultsService(voteRepo, categoryRepo, nil).(*resultsService)
+			service.now = func() time.Time { return now }
+
+			category := &models.Category{CategoryID: uuid.New(), ResultsState: tt.state, ResultsPublishAt: tt.publishAt}
+			categoryRepo.On("GetByID", mock.Anything, category.CategoryID).Return(category, nil)
+			voteRepo.On("TallyByCategory", mock.Anything, category.CategoryID).Return([]repositories.NomineeTally{}, nil)
+
+			_, err := service.GetPublishedCategoryResults(context.Background(), category.CategoryID)
+			if tt.wantPublic != nil {
+				assert.ErrorIs(t, err, tt.wantPublic)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			_, err = service.GetCategoryResults(context.Background(), category.CategoryID)
+			if tt.wantAdminErr != nil {
+				assert.ErrorIs(t, err, tt.wantAdminErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResultsService_StaffReadsSkipHiddenCategories(t *testing.T) {
+	now := time.Date(2025, 3, 2, 21, 0, 0, 0, time.UTC)
+	edition := &models.Edition{EditionID: uuid.New(), Year: 2025}
+	hidden := models.Category{CategoryID: uuid.New(), EditionID: edition.EditionID, Name: "Hidden", ResultsState: models.ResultsHidden}
+	preview := models.Category{CategoryID: uuid.New(), EditionID: edition.EditionID, Name: "Preview", ResultsState: models.ResultsPreview}
+	published := models.Category{CategoryID: uuid.New(), EditionID: edition.EditionID, Name: "Published", ResultsState: models.ResultsPublished}
+
+	setup := func() ResultsService {
+		voteRepo := new(MockVoteRepository)
+		categoryRepo := new(MockCategoryRepository)
+		editionRepo := new(MockEditionRepository)
+		categoryRepo.On("GetByEdition", mock.Anything, edition.EditionID).Return([]models.Category{hidden, preview, published}, nil)
+		categoryRepo.On("GetByID", mock.Anything, hidden.CategoryID).Return(&hidden, nil)
+		var tallies []repositories.NomineeTally
+		for _, category := range []models.Category{hidden, preview, published} {
+			tallies = append(tallies, repositories.NomineeTally{CategoryID: category.CategoryID, NomineeID: uuid.New(), NomineeName: "Nominee", Votes: 1})
+		}
+		voteRepo.On("TallyByEdition", mock.Anything, edition.EditionID).Return(tallies, nil)
+		editionRepo.On("GetByYear", mock.Anything, 2025).Return(edition, nil)
+		editionRepo.On("GetByID", mock.Anything, edition.EditionID).Return(edition, nil)
+		editionRepo.On("GetActive", mock.Anything).Return(edition, nil)
+		service := NewResultsService(voteRepo, categoryRepo, editionRepo).(*resultsService)
+		service.now = func() time.Time { return now }
+		return service
+	}
+	names := func(results []models.CategoryResults) []string {
+		var names []string
+		for _, category := range results {
+			names = append(names, category.CategoryName)
+		}
+		return names
+	}
+
+	t.Run("category", func(t *testing.T) {
+		_, err := setup().GetCategoryResults(context.Background(), hidden.CategoryID)
+
+		assert.ErrorIs(t, err, ErrResultsHidden)
+	})
+
+	t.Run("edition", func(t *testing.T) {
+		results, err := setup().GetHistoricalResults(context.Background(), 2025)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Preview", "Published"}, names(results))
+	})
+
+	t.Run("real-time tallies", func(t *testing.T) {
+		results, err := setup().GetRealTimeTallies(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Preview", "Published"}, names(results))
+	})
+
+	t.Run("export", func(t *testing.T) {
+		_, err := setup().ExportResults(context.Background(), "csv", hidden.CategoryID, uuid.Nil)
+		assert.ErrorIs(t, err, ErrResultsHidden)
+
+		export, err := setup().ExportResults(context.Background(), "csv", uuid.Nil, edition.EditionID)
+		require.NoError(t, err)
+		assert.Contains(t, string(export.Data), "Published")
+		assert.NotContains(t, string(export.Data), "Hidden")
+	})
+}
+
+func TestResultsService_FreshCategoryTallies(t *testing.T) {
+	ctx := context.Background()
+	edition := &models.Edition{EditionID: uuid.New(), Year: 2025}
+	categoryRepo := new(MockCategoryRepository)
+	editionRepo := new(MockEditionRepository)
+	editionRepo.On("GetActive", mock.Anything).Return(edition, nil)
+	categoryRepo.On("GetByName", mock.Anything, edition.EditionID, "Best Album").Return(nil, nil)
+	var created *models.Category
+	categoryRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*models.Category)
+	}).Return(nil)
+
+	_, err := NewCategoryService(categoryRepo, editionRepo).CreateCategory(ctx, uuid.Nil, "Best Album", "")
+	require.NoError(t, err)
+
+	categoryRepo.On("GetByEdition", mock.Anything, edition.EditionID).Return([]models.Category{*created}, nil)
+	voteRepo := new(MockVoteRepository)
+	voteRepo.On("TallyByEdition", mock.Anything, edition.EditionID).Return([]repositories.NomineeTally{
+		{CategoryID: created.CategoryID, NomineeID: uuid.New(), NomineeName: "Nominee", Votes: 2},
+	}, nil)
+	require.True(t, rbac.Can(models.RoleResultsOfficer, rbac.ResultsRead))
+
+	results, err := NewResultsService(voteRepo, categoryRepo, editionRepo).GetRealTimeTallies(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Best Album", results[0].CategoryName)
+	assert.Equal(t, int64(2), results[0].TotalVotes)
+}
+
+func TestResultsService_ExportResults(t *testing.T) {
+	edition := &models.Edition{EditionID: uuid.New(), Year: 2025}
+	category := &models.Category{CategoryID: uuid.New(), EditionID: edition.EditionID, Name: "Best Hip Hop Album", ResultsState: models.ResultsPublished}
+	tallies := []repositories.NomineeTally{
+		{CategoryID: category.CategoryID, NomineeID: uuid.New(), NomineeName: "Nominee, A", Votes: 3, Percentage: 75},
+		{CategoryID: category.CategoryID, NomineeID: uuid.New(), NomineeName: "Nominee B", Votes: 1, Percentage: 25},
+	}
+
+	setup := func() ResultsService {
+		voteRepo := new(MockVoteRepository)
+		categoryRepo := new(MockCategoryRepository)
+		editionRepo := new(MockEditionRepository)
+		categoryRepo.On("GetByID", mock.Anything, category.CategoryID).Return(category, nil)
+		editionRepo.On("GetByID", mock.Anything, edition.EditionID).Return(edition, nil)
+		voteRepo.On("TallyByCategory", mock.Anything, category.CategoryID).Return(tallies, nil)
+		return NewResultsService(voteRepo, categoryRepo, editionRepo)
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		export, err := setup().ExportResults(context.Background(), "csv", category.CategoryID, uuid.Nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "results-2025-best-hip-hop-album.csv", export.Filename)
+		assert.Equal(t, "text/csv; charset=utf-8", export.ContentType)
+		assert.Equal(t, "edition_year,category,rank,nominee,votes,percentage,winner\n"+
+			"2025,Best Hip Hop Album,1,\"Nominee, A\",3,75.00,true\n"+
+			"2025,Best Hip Hop Album,2,Nominee B,1,25.00,false\n", string(export.Data))
+	})
+
+	t.Run("ods", func(t *testing.T) {
+		export, err := setup().ExportResults(context.Background(), "ODS", category.CategoryID, uuid.Nil)
+		assert.NoError(t, err)
+
+		archive, err := zip.NewReader(bytes.NewReader(export.Data), int64(len(export.Data)))
+		assert.NoError(t, err)
+		assert.Equal(t, "mimetype", archive.File[0].Name)
+		assert.Equal(t, zip.Store, archive.File[0].Method)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := setup().ExportResults(context.Background(), "xlsx", category.CategoryID, uuid.Nil)
+
+		assert.ErrorIs(t, err, ErrUnsupportedExportFormat)
+	})
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/nyashahama/music-awards/internal/security"
+)
+
+// refreshTokenTTL is how long a session lasts without being refreshed
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+var ErrInvalidRefreshToken = errors.New("refresh token is invalid or has expired")
+
+// AuthTokens are issued on login and on each refresh. The refresh token can
+// be used once, to get the next pair.
+//
+// When the user has two-factor authentication on, login only returns a
+// TwoFactorToken, which CompleteTwoFactorLogin exchanges for the other
+// tokens. ExpiresIn is then the lifetime of the TwoFactorToken.
+type AuthTokens struct {
+	AccessToken    string
+	RefreshToken   string
+	TwoFactorToken string
+	ExpiresIn      time.Duration
+}
+
+// signIn starts a session for a user who has proven who they are, or asks
+// for their second factor first if they have two-factor authentication on.
+func signIn(ctx context.Context, uow repositories.UnitOfWork, sessions repositories.SessionRepository, user *models.User, now time.Time) (*AuthTokens, error) {
+	if !user.IsTwoFactorEnabled() {
+		return startSession(ctx, sessions, user, false, now)
+	}
+
+	token, hash, err := security.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	err = uow.Do(ctx, func(tx repositories.Tx) error {
+		return tx.Tokens().Create(ctx, &models.UserToken{
+			UserID:    user.UserID,
+			Purpose:   models.TokenPurposeTwoFactorLogin,
+			TokenHash: hash,
+			ExpiresAt: now.Add(twoFactorLoginTTL),
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store two-factor token: %w", err)
+	}
+	return &AuthTokens{TwoFactorToken: token, ExpiresIn: twoFactorLoginTTL}, nil
+}
+
+// startSession records a new session for the user and issues its tokens.
+// twoFactor records whether the user entered a second factor.
+func startSession(ctx context.Context, sessions repositories.SessionRepository, user *models.User, twoFactor bool, now time.Time) (*AuthTokens, error) {
+	refreshToken, hash, err := security.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	session := &models.Session{
+		SessionID:        uuid.New(),
+		UserID:           user.UserID,
+		RefreshTokenHash: hash,
+		ExpiresAt:        now.Add(refreshTokenTTL),
+		TwoFactor:        twoFactor,
+	}
+	if err := sessions.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return issueTokens(user, session, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh
+// token. Presenting a refresh token that has already been exchanged ends its
+// session, since it means the token was copied.
+func (s *userService) Refresh(ctx context.Context, refreshToken string) (*AuthTokens, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	now := s.now()
+	oldHash := security.HashToken(refreshToken)
+	newToken, newHash, err := security.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.sessionRepo.Rotate(ctx, oldHash, newHash, now.Add(refreshTokenTTL), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if session == nil {
+		if _, err := s.sessionRepo.RevokeByPreviousHash(ctx, oldHash, now); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		if err := s.sessionRepo.Revoke(ctx, session.SessionID, now); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	return issueTokens(user, session, newToken)
+}
+
+// Logout ends the session. Its access and refresh tokens stop working.
+func (s *userService) Logout(ctx context.Context, sessionID uuid.UUID) error {
+	if err := s.sessionRepo.Revoke(ctx, sessionID, s.now()); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// ValidateSession rejects access tokens whose session has ended, whose user
+// has been deleted or had their sessions revoked, or whose role claim is out
+// of date.
+func (s *userService) ValidateSession(ctx context.Context, claims *security.JWTClaims) error {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return security.ErrSessionRevoked
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return security.ErrSessionRevoked
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if session == nil || session.UserID != userID || !session.IsActive(s.now()) {
+		return security.ErrSessionRevoked
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.TokenVersion != claims.TokenVersion || user.Role != claims.Role {
+		return security.ErrSessionRevoked
+	}
+	return nil
+}
+
+func issueTokens(user *models.User, session *models.Session, refreshToken string) (*AuthTokens, error) {
+	var amr []string
+	if session.TwoFactor {
+		amr = append(amr, security.AMRTwoFactor)
+	}
+	accessToken, err := security.GenerateJWT(user.UserID, user.Username, user.Role, user.Email, user.TokenVersion, session.SessionID, amr...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    security.AccessTokenTTL,
+	}, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Rotate(ctx context.Context, oldHash, newHash string, expiresAt, now time.Time) (*models.Session, error) {
+	args := m.Called(ctx, oldHash, newHash, expiresAt, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) RevokeByPreviousHash(ctx context.Context, hash string, now time.Time) (bool, error) {
+	args := m.Called(ctx, hash, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionRepository) Revoke(ctx context.Context, id uuid.UUID, now time.Time) error {
+	args := m.Called(ctx, id, now)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	args := m.Called(ctx, userID, now)
+	return args.Error(0)
+}
+
+func setupSessionTest() (*MockUserRepository, *MockSessionRepository, *userService) {
+	userRepo, sessionRepo := new(MockUserRepository), new(MockSessionRepository)
+	service := newTestUserService(userRepo, new(MockEditionRepository), &mockUnitOfWork{users: userRepo, sessions: sessionRepo})
+	return userRepo, sessionRepo, service
+}
+
+func TestUserService_LoginStartsSession(t *testing.T) {
+	userRepo, sessionRepo, service := setupSessionTest()
+	user := createTestUser()
+	user.PasswordHash, _ = hashPassword("correctpassword")
+	userRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	var session *models.Session
+	sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Session")).
+		Run(func(args mock.Arguments) { session = args.Get(1).(*models.Session) }).
+		Return(nil)
+
+	tokens, err := service.Login(context.Background(), user.Email, "correctpassword")
+
+	require.NoError(t, err)
+	require.NotNil(t, session)
+	assert.Equal(t, user.UserID, session.UserID)
+	assert.Equal(t, security.HashToken(tokens.RefreshToken), session.RefreshTokenHash, "only the hash is stored")
+	assert.Equal(t, verificationNow.Add(refreshTokenTTL), session.ExpiresAt)
+	assert.Equal(t, security.AccessTokenTTL, tokens.ExpiresIn)
+
+	claims, err := security.ValidateJWT(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, session.SessionID.String(), claims.SessionID)
+}
+
+func TestUserService_Refresh(t *testing.T) {
+	oldHash := security.HashToken("old-refresh")
+
+	t.Run("rotates the refresh token", func(t *testing.T) {
+		userRepo, sessionRepo, service := setupSessionTest()
+		user := createTestUser()
+		session := &models.Session{SessionID: uuid.New(), UserID: user.UserID}
+		var newHash string
+		sessionRepo.On("Rotate", mock.Anything, oldHash, mock.AnythingOfType("string"), verificationNow.Add(refreshTokenTTL), verificationNow).
+			Run(func(args mock.Arguments) { newHash = args.String(2) }).
+			Return(session, nil)
+		userRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+
+		tokens, err := service.Refresh(context.Background(), "old-refresh")
+
+		require.NoError(t, err)
+		assert.Equal(t, newHash, security.HashToken(tokens.RefreshToken))
+		assert.NotEqual(t, oldHash, newHash)
+		claims, err := security.ValidateJWT(tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, session.SessionID.String(), claims.SessionID)
+	})
+
+	t.Run("replayed token ends its session", func(t *testing.T) {
+		_, sessionRepo, service := setupSessionTest()
+		sessionRepo.On("Rotate", mock.Anything, oldHash, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+		sessionRepo.On("RevokeByPreviousHash", mock.Anything, oldHash, verificationNow).Return(true, nil)
+
+		_, err := service.Refresh(context.Background(), "old-refresh")
+
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		sessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		userRepo, sessionRepo, service := setupSessionTest()
+		session := &models.Session{SessionID: uuid.New(), UserID: uuid.New()}
+		sessionRepo.On("Rotate", mock.Anything, oldHash, mock.Anything, mock.Anything, mock.Anything).Return(session, nil)
+		sessionRepo.On("Revoke", mock.Anything, session.SessionID, verificationNow).Return(nil)
+		userRepo.On("GetByID", mock.Anything, session.UserID).Return(nil, nil)
+
+		_, err := service.Refresh(context.Background(), "old-refresh")
+
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		sessionRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserService_ValidateSession(t *testing.T) {
+	user := createTestUser()
+	user.TokenVersion = 2
+	active := &models.Session{SessionID: uuid.New(), UserID: user.UserID, ExpiresAt: verificationNow.Add(time.Hour)}
+	revoked := &models.Session{SessionID: uuid.New(), UserID: user.UserID, ExpiresAt: verificationNow.Add(time.Hour), RevokedAt: &verificationNow}
+	claims := func(session *models.Session, version int, role string) *security.JWTClaims {
+		return &security.JWTClaims{UserID: user.UserID.String(), SessionID: session.SessionID.String(), TokenVersion: version, Role: role}
+	}
+
+	tests := []struct {
+		name    string
+		claims  *security.JWTClaims
+		wantErr error
+	}{
+		{"current session", claims(active, 2, user.Role), nil},
+		{"logged out", claims(revoked, 2, user.Role), security.ErrSessionRevoked},
+		{"password reset since", claims(active, 1, user.Role), security.ErrSessionRevoked},
+		{"role changed since", claims(active, 2, models.RoleAdmin), security.ErrSessionRevoked},
+		{"no session", &security.JWTClaims{UserID: user.UserID.String(), TokenVersion: 2, Role: user.Role}, security.ErrSessionRevoked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo, sessionRepo, service := setupSessionTest()
+			sessionRepo.On("GetByID", mock.Anything, active.SessionID).Return(active, nil).Maybe()
+			sessionRepo.On("GetByID", mock.Anything, revoked.SessionID).Return(revoked, nil).Maybe()
+			userRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil).Maybe()
+
+			err := service.ValidateSession(context.Background(), tt.claims)
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/nyashahama/music-awards/internal/security"
+)
+
+const (
+	// twoFactorLoginTTL is how long the user has to enter their code after
+	// their password
+	twoFactorLoginTTL = 5 * time.Minute
+	// recoveryCodeCount is how many recovery codes each user gets
+	recoveryCodeCount = 10
+	// totpIssuer names this service in authenticator apps
+	totpIssuer = "Music Awards"
+)
+
+var (
+	ErrTwoFactorEnabled      = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled   = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp     = errors.New("two-factor authentication has not been set up")
+	ErrInvalidTwoFactorCode  = errors.New("invalid two-factor code")
+	ErrInvalidTwoFactorToken = errors.New("two-factor sign-in is invalid or has expired")
+)
+
+// TwoFactorSetup is what the user enters in their authenticator app.
+type TwoFactorSetup struct {
+	Secret string
+	URI    string
+}
+
+// SetupTwoFactor generates a new TOTP secret for the user. It takes effect
+// once EnableTwoFactor confirms the user's app produces matching codes.
+func (s *userService) SetupTwoFactor(ctx context.Context, userID uuid.UUID) (*TwoFactorSetup, error) {
+	user, err := s.GetUserProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := security.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		return tx.TwoFactor().SetSecret(ctx, userID, secret)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+	return &TwoFactorSetup{Secret: secret, URI: security.TOTPURI(totpIssuer, user.Email, secret)}, nil
+}
+
+// EnableTwoFactor turns on two-factor authentication once code shows the
+// user's app is set up. It returns the user's recovery codes, which are not
+// shown again.
+func (s *userService) EnableTwoFactor(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.GetUserProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == nil {
+		return nil, ErrTwoFactorNotSetUp
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		if err := checkSecondFactor(ctx, tx.TwoFactor(), user, code, now); err != nil {
+			return err
+		}
+		if err := tx.TwoFactor().Enable(ctx, userID, now); err != nil {
+			return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+		}
+		if err := tx.TwoFactor().ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+			return fmt.Errorf("failed to store recovery codes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor turns off two-factor authentication. code is a current
+// TOTP code or a recovery code.
+func (s *userService) DisableTwoFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.GetUserProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	now := s.now()
+	return s.uow.Do(ctx, func(tx repositories.Tx) error {
+		if err := checkSecondFactor(ctx, tx.TwoFactor(), user, code, now); err != nil {
+			return err
+		}
+		if err := tx.TwoFactor().Disable(ctx, userID); err != nil {
+			return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+		}
+		if err := tx.TwoFactor().ReplaceRecoveryCodes(ctx, userID, nil); err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes with new ones.
+func (s *userService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.GetUserProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsTwoFactorEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		if err := checkSecondFactor(ctx, tx.TwoFactor(), user, code, now); err != nil {
+			return err
+		}
+		if err := tx.TwoFactor().ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+			return fmt.Errorf("failed to store recovery codes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CompleteTwoFactorLogin finishes a login with the two-factor token Login
+// returned and a TOTP or recovery code. The token is used up by the attempt,
+// so after a wrong code the user starts again with their password.
+func (s *userService) CompleteTwoFactorLogin(ctx context.Context, token, code string) (*AuthTokens, error) {
+	if token == "" {
+		return nil, ErrInvalidTwoFactorToken
+	}
+
+	now := s.now()
+	var consumed *models.UserToken
+	err := s.uow.Do(ctx, func(tx repositories.Tx) (err error) {
+		consumed, err = tx.Tokens().Consume(ctx, models.TokenPurposeTwoFactorLogin, security.HashToken(token), now)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume two-factor token: %w", err)
+	}
+	if consumed == nil {
+		return nil, ErrInvalidTwoFactorToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, consumed.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || !user.IsTwoFactorEnabled() {
+		return nil, ErrInvalidTwoFactorToken
+	}
+
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		return checkSecondFactor(ctx, tx.TwoFactor(), user, code, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return startSession(ctx, s.sessionRepo, user, true, now)
+}
+
+// checkSecondFactor accepts a TOTP code that has not been used before or,
+// once two-factor authentication is on, an unused recovery code, which is
+// then used up.
+func checkSecondFactor(ctx context.Context, repo repositories.TwoFactorRepository, user *models.User, code string, now time.Time) error {
+	if user.TOTPSecret == nil {
+		return ErrInvalidTwoFactorCode
+	}
+	code = strings.TrimSpace(code)
+
+	if step, ok := security.ValidateTOTP(*user.TOTPSecret, code, now); ok {
+		used, err := repo.UseStep(ctx, user.UserID, step)
+		if err != nil {
+			return fmt.Errorf("failed to record TOTP code: %w", err)
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	if !user.IsTwoFactorEnabled() {
+		return ErrInvalidTwoFactorCode
+	}
+	used, err := repo.ConsumeRecoveryCode(ctx, user.UserID, hashRecoveryCode(code), now)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes returns a fresh set of recovery codes, formatted like
+// "abcde-fghij", and their hashes.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for range recoveryCodeCount {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b)[:10])
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code the way the user may type it, in
+// any case and with or without the dash.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return security.HashToken(code)
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) SetSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Enable(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, userID, at)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	args := m.Called(ctx, userID, hashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, hash string, now time.Time) (bool, error) {
+	args := m.Called(ctx, userID, hash, now)
+	return args.Bool(0), args.Error(1)
+}
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func setupTwoFactorTest() (*MockUserRepository, *MockTwoFactorRepository, *MockTokenRepository, *MockSessionRepository, *userService) {
+	userRepo, twoFactorRepo := new(MockUserRepository), new(MockTwoFactorRepository)
+	tokenRepo, sessionRepo := new(MockTokenRepository), new(MockSessionRepository)
+	uow := &mockUnitOfWork{users: userRepo, tokens: tokenRepo, sessions: sessionRepo, twoFactor: twoFactorRepo}
+	service := newTestUserService(userRepo, new(MockEditionRepository), uow)
+	return userRepo, twoFactorRepo, tokenRepo, sessionRepo, service
+}
+
+func twoFactorUser() *models.User {
+	user := createTestUser()
+	secret := testTOTPSecret
+	user.TOTPSecret = &secret
+	user.TOTPEnabledAt = &verificationNow
+	return user
+}
+
+func currentTOTPCode(t *testing.T) string {
+	code, err := security.TOTPCode(testTOTPSecret, verificationNow)
+	require.NoError(t, err)
+	return code
+}
+
+func TestUserService_SetupAndEnableTwoFactor(t *testing.T) {
+	userRepo, twoFactorRepo, _, _, service := setupTwoFactorTest()
+	user := createTestUser()
+	userRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+	twoFactorRepo.On("SetSecret", mock.Anything, user.UserID, mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) {
+			secret := args.String(2)
+			user.TOTPSecret = &secret
+		}).
+		Return(nil)
+
+	setup, err := service.SetupTwoFactor(context.Background(), user.UserID)
+
+	require.NoError(t, err)
+	assert.Equal(t, *user.TOTPSecret, setup.Secret)
+	assert.Contains(t, setup.URI, "otpauth://totp/")
+	assert.Contains(t, setup.URI, "secret="+setup.Secret)
+
+	_, err = service.EnableTwoFactor(context.Background(), user.UserID, "000000")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	twoFactorRepo.AssertNotCalled(t, "Enable", mock.Anything, mock.Anything, mock.Anything)
+
+	code, err := security.TOTPCode(setup.Secret, verificationNow)
+	require.NoError(t, err)
+	var hashes []string
+	twoFactorRepo.On("UseStep", mock.Anything, user.UserID, verificationNow.Unix()/30).Return(true, nil)
+	twoFactorRepo.On("Enable", mock.Anything, user.UserID, verificationNow).Return(nil)
+	twoFactorRepo.On("ReplaceRecoveryCodes", mock.Anything, user.UserID, mock.Anything).
+		Run(func(args mock.Arguments) { hashes = args.Get(2).([]string) }).
+		Return(nil)
+
+	codes, err := service.EnableTwoFactor(context.Background(), user.UserID, code)
+
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+	for i, recoveryCode := range codes {
+		assert.Regexp(t, regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`), recoveryCode)
+		assert.Equal(t, hashes[i], hashRecoveryCode(recoveryCode), "only the hash is stored")
+	}
+}
+
+func TestUserService_SetupTwoFactorWhenEnabled(t *testing.T) {
+	userRepo, twoFactorRepo, _, _, service := setupTwoFactorTest()
+	user := twoFactorUser()
+	userRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+
+	_, err := service.SetupTwoFactor(context.Background(), user.UserID)
+	assert.ErrorIs(t, err, ErrTwoFactorEnabled)
+	_, err = service.EnableTwoFactor(context.Background(), user.UserID, currentTOTPCode(t))
+	assert.ErrorIs(t, err, ErrTwoFactorEnabled)
+	twoFactorRepo.AssertExpectations(t)
+}
+
+func TestUserService_LoginWithTwoFactor(t *testing.T) {
+	userRepo, twoFactorRepo, tokenRepo, sessionRepo, service := setupTwoFactorTest()
+	user := twoFactorUser()
+	user.PasswordHash, _ = hashPassword("correctpassword")
+	userRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	userRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+
+	var challenge *models.UserToken
+	tokenRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.UserToken")).
+		Run(func(args mock.Arguments) { challenge = args.Get(1).(*models.UserToken) }).
+		Return(nil)
+
+	tokens, err := service.Login(context.Background(), user.Email, "correctpassword")
+
+	require.NoError(t, err)
+	assert.Empty(t, tokens.AccessToken)
+	assert.Empty(t, tokens.RefreshToken)
+	assert.Equal(t, twoFactorLoginTTL, tokens.ExpiresIn)
+	require.NotNil(t, challenge)
+	assert.Equal(t, models.TokenPurposeTwoFactorLogin, challenge.Purpose)
+	assert.Equal(t, security.HashToken(tokens.TwoFactorToken), challenge.TokenHash)
+	sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	consume := func() *mock.Call {
+		return tokenRepo.On("Consume", mock.Anything, models.TokenPurposeTwoFactorLogin, challenge.TokenHash, verificationNow).
+			Return(challenge, nil).Once()
+	}
+
+	t.Run("with a TOTP code", func(t *testing.T) {
+		consume()
+		twoFactorRepo.On("UseStep", mock.Anything, user.UserID, verificationNow.Unix()/30).Return(true, nil).Once()
+		var session *models.Session
+		sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Session")).
+			Run(func(args mock.Arguments) { session = args.Get(1).(*models.Session) }).
+			Return(nil).Once()
+
+		tokens, err := service.CompleteTwoFactorLogin(context.Background(), tokens.TwoFactorToken, currentTOTPCode(t))
+
+		require.NoError(t, err)
+		require.NotNil(t, session)
+		assert.True(t, session.TwoFactor)
+		claims, err := security.ValidateJWT(tokens.AccessToken)
+		require.NoError(t, err)
+		assert.True(t, claims.HasTwoFactor())
+	})
+
+	t.Run("with a TOTP code already used", func(t *testing.T) {
+		consume()
+		twoFactorRepo.On("UseStep", mock.Anything, user.UserID, verificationNow.Unix()/30).Return(false, nil).Once()
+
+		_, err := service.CompleteTwoFactorLogin(context.Background(), tokens.TwoFactorToken, currentTOTPCode(t))
+
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	})
+
+	t.Run("with a recovery code", func(t *testing.T) {
+		consume()
+		twoFactorRepo.On("ConsumeRecoveryCode", mock.Anything, user.UserID, hashRecoveryCode("abcde-fghij"), verificationNow).Return(true, nil).Once()
+		sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Session")).Return(nil).Once()
+
+		_, err := service.CompleteTwoFactorLogin(context.Background(), tokens.TwoFactorToken, "ABCDE FGHIJ")
+
+		require.NoError(t, err)
+	})
+
+	t.Run("with a wrong code", func(t *testing.T) {
+		consume()
+		twoFactorRepo.On("ConsumeRecoveryCode", mock.Anything, user.UserID, hashRecoveryCode("000000"), verificationNow).Return(false, nil).Once()
+
+		_, err := service.CompleteTwoFactorLogin(context.Background(), tokens.TwoFactorToken, "000000")
+
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	})
+
+	t.Run("with a used or expired token", func(t *testing.T) {
+		tokenRepo.On("Consume", mock.Anything, models.TokenPurposeTwoFactorLogin, challenge.TokenHash, verificationNow).
+			Return(nil, nil).Once()
+
+		_, err := service.CompleteTwoFactorLogin(context.Background(), tokens.TwoFactorToken, currentTOTPCode(t))
+
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorToken)
+	})
+}
+
+func TestUserService_DisableTwoFactor(t *testing.T) {
+	t.Run("disables with a valid code", func(t *testing.T) {
+		userRepo, twoFactorRepo, _, _, service := setupTwoFactorTest()
+		user := twoFactorUser()
+		userRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+		twoFactorRepo.On("UseStep", mock.Anything, user.UserID, verificationNow.Unix()/30).Return(true, nil)
+		twoFactorRepo.On("Disable", mock.Anything, user.UserID).Return(nil)
+		twoFactorRepo.On("ReplaceRecoveryCodes", mock.Anything, user.UserID, []string(nil)).Return(nil)
+
+		err := service.DisableTwoFactor(context.Background(), user.UserID, currentTOTPCode(t))
+
+		require.NoError(t, err)
+		twoFactorRepo.AssertExpectations(t)
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		userRepo, _, _, _, service := setupTwoFactorTest()
+		user := createTestUser()
+		userRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+
+		err := service.DisableTwoFactor(context.Background(), user.UserID, "123456")
+
+		assert.ErrorIs(t, err, ErrTwoFactorNotEnabled)
+	})
+}
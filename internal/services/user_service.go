@@ -6,39 +6,104 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/rbac"
 	"github.com/nyashahama/music-awards/internal/repositories"
 	"github.com/nyashahama/music-awards/internal/security"
+	"github.com/nyashahama/music-awards/internal/utils"
 	"github.com/nyashahama/music-awards/internal/validation"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrEmailExists        = errors.New("email already exists")
+	ErrUsernameExists     = errors.New("username already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrPasswordValidation = errors.New("password validation failed")
 	ErrInvalidID          = errors.New("invalid id")
+	ErrInvalidRoleChange  = errors.New("role change not allowed")
+	ErrUnknownRole        = errors.New("unknown role")
 )
 
 // UserService handles user-related business logic
 type UserService interface {
 	Register(ctx context.Context, username, email, password string) (*models.User, error)
-	Login(ctx context.Context, email, password string) (string, error) // Returns JWT token
+	Login(ctx context.Context, email, password string) (*AuthTokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*AuthTokens, error)
+	Logout(ctx context.Context, sessionID uuid.UUID) error
 	GetUserProfile(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	UpdateUser(ctx context.Context, userID uuid.UUID, updateData map[string]any) (*models.User, error)
 	DeleteUser(ctx context.Context, userID uuid.UUID) error
+	RestoreUser(ctx context.Context, userID uuid.UUID) (*models.User, error)
 	PromoteToAdmin(ctx context.Context, userID uuid.UUID) error
+	AppointJury(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	DismissJury(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	AssignRole(ctx context.Context, actorID, userID uuid.UUID, role string) (*models.User, error)
+	RevokeRole(ctx context.Context, actorID, userID uuid.UUID) (*models.User, error)
 	GetAllUsers(ctx context.Context) ([]models.User, error)
+	VerifyEmail(ctx context.Context, token string) (*models.User, error)
+	ResendVerification(ctx context.Context, email string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	ValidateSession(ctx context.Context, claims *security.JWTClaims) error
+	SetupTwoFactor(ctx context.Context, userID uuid.UUID) (*TwoFactorSetup, error)
+	EnableTwoFactor(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	CompleteTwoFactorLogin(ctx context.Context, token, code string) (*AuthTokens, error)
+}
+
+// AccountLinks are the URLs of the links emailed to users. A token is
+// appended to each as the token query parameter.
+type AccountLinks struct {
+	VerifyEmail   string
+	ResetPassword string
 }
 
 type userService struct {
-	userRepo repositories.UserRepository
+	userRepo     repositories.UserRepository
+	editionRepo  repositories.EditionRepository
+	sessionRepo  repositories.SessionRepository
+	throttleRepo repositories.LoginThrottleRepository
+	uow          repositories.UnitOfWork
+	links        AccountLinks
+
+	// the email senders are replaced in tests
+	sendVerificationEmail  func(recipient, verificationURL string)
+	sendPasswordResetEmail func(recipient, resetURL string)
+	sendAccountLockedEmail func(recipient string, until time.Time)
+	now                    func() time.Time
+
+	// accountThrottle and ipThrottle slow down password guessing
+	accountThrottle throttlePolicy
+	ipThrottle      throttlePolicy
 }
 
-func NewUserService(userRepo repositories.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(
+	userRepo repositories.UserRepository,
+	editionRepo repositories.EditionRepository,
+	sessionRepo repositories.SessionRepository,
+	throttleRepo repositories.LoginThrottleRepository,
+	uow repositories.UnitOfWork,
+	links AccountLinks,
+) UserService {
+	return &userService{
+		userRepo:               userRepo,
+		editionRepo:            editionRepo,
+		sessionRepo:            sessionRepo,
+		throttleRepo:           throttleRepo,
+		uow:                    uow,
+		links:                  links,
+		sendVerificationEmail:  utils.SendVerificationEmail,
+		sendPasswordResetEmail: utils.SendPasswordResetEmail,
+		sendAccountLockedEmail: utils.SendAccountLockedEmail,
+		now:                    time.Now,
+		accountThrottle:        accountThrottle,
+		ipThrottle:             ipThrottle,
+	}
 }
 
 func (s *userService) Register(ctx context.Context, username, email, password string) (*models.User, error) {
@@ -65,42 +130,78 @@ func (s *userService) Register(ctx context.Context, username, email, password st
 		return nil, fmt.Errorf("failed to hash the password: %w", err)
 	}
 
+	// New users start with the active edition's budget for their role
+	budget := models.DefaultVoteBudget
+	edition, err := s.editionRepo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active edition: %w", err)
+	}
+	if edition != nil {
+		budget = edition.BudgetFor(models.RoleUser)
+	}
+
 	user := &models.User{
 		UserID:         uuid.New(),
 		Username:       username,
 		Email:          email,
 		PasswordHash:   hashedPassword,
-		Role:           "user",
-		AvailableVotes: 5,
+		Role:           models.RoleUser,
+		AvailableVotes: budget,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+	var link string
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		if err := tx.Users().Create(ctx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		if err := recordRegistrationAudit(ctx, tx.Audits(), user.UserID); err != nil {
+			return fmt.Errorf("failed to audit registration: %w", err)
+		}
+		link, err = s.issueVerificationToken(ctx, tx.Tokens(), user.UserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	go s.sendVerificationEmail(user.Email, link)
 	return user, nil
 }
 
-func (s *userService) Login(ctx context.Context, email, password string) (string, error) {
+// Login checks the user's credentials and starts a new session, or returns
+// a two-factor token if the user must also enter a code.
+//
+// Failed logins are counted per email address and per client IP. Past a
+// few failures further attempts are delayed, with the delay doubling each
+// time, and after many the account is locked out for a while and its owner
+// emailed. Blocked attempts return a LoginThrottledError without checking
+// the password.
+func (s *userService) Login(ctx context.Context, email, password string) (*AuthTokens, error) {
 	email = strings.ToLower(email)
+	now := s.now()
+
+	throttles := s.loginThrottles(ctx, email)
+	if err := s.checkLoginThrottle(ctx, throttles, now); err != nil {
+		return nil, err
+	}
 
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user: %w", err)
-	}
-	if user == nil {
-		return "", ErrInvalidCredentials
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
-
-	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
-		return "", ErrInvalidCredentials
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		if err := s.recordLoginFailure(ctx, throttles, user, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
 	}
 
-	token, err := security.GenerateJWT(user.UserID, user.Username, user.Role, user.Email)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
+	// Only the account's count is reset, so that signing in to an account
+	// of their own does not let an attacker keep guessing from their IP
+	if err := s.throttleRepo.Reset(ctx, throttles[0].key); err != nil {
+		return nil, fmt.Errorf("failed to reset login throttle: %w", err)
 	}
-
-	return token, nil
+	return signIn(ctx, s.uow, s.sessionRepo, user, now)
 }
 
 func (s *userService) GetUserProfile(ctx context.Context, userID uuid.UUID) (*models.User, error) {
@@ -127,6 +228,8 @@ func (s *userService) UpdateUser(ctx context.Context, userID uuid.UUID, updateDa
 		user.Username = username
 	}
 
+	emailChanged := false
+
 	if email, ok := updateData["email"].(string); ok {
 		// Add validation
 		email = strings.ToLower(email)
@@ -140,7 +243,12 @@ func (s *userService) UpdateUser(ctx context.Context, userID uuid.UUID, updateDa
 		if existing != nil && existing.UserID != user.UserID {
 			return nil, ErrEmailExists
 		}
-		user.Email = email
+		// The new address is unproven until its owner follows a new link
+		if email != user.Email {
+			user.Email = email
+			user.EmailVerifiedAt = nil
+			emailChanged = true
+		}
 	}
 
 	if password, ok := updateData["password"].(string); ok {
@@ -155,13 +263,34 @@ func (s *userService) UpdateUser(ctx context.Context, userID uuid.UUID, updateDa
 		user.PasswordHash = hashedPassword
 	}
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+	if !emailChanged {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+		return user, nil
+	}
+
+	// Links sent to the old address must not verify the new one
+	var link string
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		if err := tx.Users().Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		if err := tx.Tokens().Revoke(ctx, user.UserID, models.TokenPurposeEmailVerification, s.now()); err != nil {
+			return fmt.Errorf("failed to revoke verification tokens: %w", err)
+		}
+		link, err = s.issueVerificationToken(ctx, tx.Tokens(), user.UserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	go s.sendVerificationEmail(user.Email, link)
 	return user, nil
 }
 
+// DeleteUser soft deletes the account. Its votes stay counted.
 func (s *userService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	if err := s.userRepo.Delete(ctx, userID); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
@@ -169,6 +298,42 @@ func (s *userService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	return nil
 }
 
+// RestoreUser brings back a deleted account, unless another account has
+// registered its email address or username in the meantime.
+func (s *userService) RestoreUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	deleted, err := s.userRepo.GetDeletedByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if deleted == nil {
+		return nil, ErrInvalidID
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, deleted.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrEmailExists
+	}
+	existing, err = s.userRepo.GetByUsername(ctx, deleted.Username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check username: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrUsernameExists
+	}
+
+	restored, err := s.userRepo.Restore(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+	if !restored {
+		return nil, ErrInvalidID
+	}
+	return s.userRepo.GetByID(ctx, userID)
+}
+
 func (s *userService) PromoteToAdmin(ctx context.Context, userID uuid.UUID) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -178,13 +343,82 @@ func (s *userService) PromoteToAdmin(ctx context.Context, userID uuid.UUID) erro
 		return ErrInvalidID
 	}
 
-	user.Role = "admin"
+	user.Role = models.RoleAdmin
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return fmt.Errorf("failed to promote user: %w", err)
 	}
 	return nil
 }
 
+// AppointJury makes a regular user a jury member. Votes they cast from now on
+// count towards the jury share; votes already cast keep their class.
+func (s *userService) AppointJury(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	return s.changeRole(ctx, userID, models.RoleUser, models.RoleJury)
+}
+
+// DismissJury returns a jury member to the regular user role.
+func (s *userService) DismissJury(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	return s.changeRole(ctx, userID, models.RoleJury, models.RoleUser)
+}
+
+// AssignRole gives the user role in place of the one they had. Nobody may
+// change their own role, so admins cannot lock themselves out. The user's
+// existing access tokens stop working, since they carry the old role.
+func (s *userService) AssignRole(ctx context.Context, actorID, userID uuid.UUID, role string) (*models.User, error) {
+	if !rbac.IsRole(role) {
+		return nil, ErrUnknownRole
+	}
+	if actorID == userID {
+		return nil, fmt.Errorf("%w: you cannot change your own role", ErrInvalidRoleChange)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrInvalidID
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	user.Role = role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to change role: %w", err)
+	}
+	return user, nil
+}
+
+// RevokeRole demotes the user to a regular user.
+func (s *userService) RevokeRole(ctx context.Context, actorID, userID uuid.UUID) (*models.User, error) {
+	return s.AssignRole(ctx, actorID, userID, models.RoleUser)
+}
+
+// changeRole moves a user from one role to another, leaving a user already
+// in the target role untouched.
+func (s *userService) changeRole(ctx context.Context, userID uuid.UUID, from, to string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrInvalidID
+	}
+	if user.Role == to {
+		return user, nil
+	}
+	if user.Role != from {
+		return nil, fmt.Errorf("%w: user is %s", ErrInvalidRoleChange, user.Role)
+	}
+
+	user.Role = to
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to change role: %w", err)
+	}
+	return user, nil
+}
+
 func (s *userService) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	return s.userRepo.GetAll(ctx)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/models"
@@ -28,6 +29,14 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
@@ -36,6 +45,14 @@ func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*mod
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserRepository) GetAll(ctx context.Context) ([]models.User, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]models.User), args.Error(1)
@@ -51,6 +68,29 @@ func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ResetPassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) DecrementAvailableVotes(ctx context.Context, userID uuid.UUID) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
@@ -61,10 +101,22 @@ func (m *MockUserRepository) IncrementAvailableVotes(ctx context.Context, userID
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) AdjustAvailableVotes(ctx context.Context, userID uuid.UUID, delta int) error {
+	args := m.Called(ctx, userID, delta)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ResetAvailableVotes(ctx context.Context, editionID uuid.UUID, budget int, roleBudgets map[string]int) (int64, error) {
+	args := m.Called(ctx, editionID, budget, roleBudgets)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // Test helper functions to reduce duplication
 func setupTest() (*MockUserRepository, UserService) {
 	mockRepo := new(MockUserRepository)
-	service := NewUserService(mockRepo)
+	editionRepo := new(MockEditionRepository)
+	editionRepo.On("GetActive", mock.Anything).Return(nil, nil).Maybe()
+	service := newTestUserService(mockRepo, editionRepo, &mockUnitOfWork{users: mockRepo, audits: new(MockAuditRepository)})
 	return mockRepo, service
 }
 
@@ -441,6 +493,60 @@ func TestUserService_DeleteUser(t *testing.T) {
 	}
 }
 
+func TestUserService_RestoreUser(t *testing.T) {
+	deleted := &models.User{UserID: uuid.New(), Username: "nyasha", Email: "nyasha@example.com"}
+	taken := &models.User{UserID: uuid.New()}
+	tests := []struct {
+		name        string
+		mockSetup   func(*MockUserRepository)
+		expectedErr error
+	}{
+		{
+			name: "successful restore",
+			mockSetup: func(m *MockUserRepository) {
+				m.On("GetByEmail", mock.Anything, deleted.Email).Return(nil, nil)
+				m.On("GetByUsername", mock.Anything, deleted.Username).Return(nil, nil)
+				m.On("Restore", mock.Anything, deleted.UserID).Return(true, nil)
+				m.On("GetByID", mock.Anything, deleted.UserID).Return(deleted, nil)
+			},
+		},
+		{
+			name: "email taken since",
+			mockSetup: func(m *MockUserRepository) {
+				m.On("GetByEmail", mock.Anything, deleted.Email).Return(taken, nil)
+			},
+			expectedErr: ErrEmailExists,
+		},
+		{
+			name: "username taken since",
+			mockSetup: func(m *MockUserRepository) {
+				m.On("GetByEmail", mock.Anything, deleted.Email).Return(nil, nil)
+				m.On("GetByUsername", mock.Anything, deleted.Username).Return(taken, nil)
+			},
+			expectedErr: ErrUsernameExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, service := setupTest()
+			mockRepo.On("GetDeletedByID", mock.Anything, deleted.UserID).Return(deleted, nil)
+			tt.mockSetup(mockRepo)
+
+			user, err := service.RestoreUser(context.Background(), deleted.UserID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				mockRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, deleted, user)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestUserService_PromoteToAdmin(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -549,3 +655,109 @@ func TestUserService_GetAllUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_RegisterUsesActiveEditionBudget(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	editionRepo := new(MockEditionRepository)
+	editionRepo.On("GetActive", mock.Anything).Return(&models.Edition{
+		VotePolicy:      models.VotePolicyPerRole,
+		VoteBudget:      3,
+		RoleVoteBudgets: models.RoleBudgets{"user": 8},
+	}, nil)
+	mockRepo.On("GetByEmail", mock.Anything, "test@example.com").Return(nil, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil)
+	service := newTestUserService(mockRepo, editionRepo, &mockUnitOfWork{users: mockRepo, audits: new(MockAuditRepository)})
+
+	user, err := service.Register(context.Background(), "testuser", "test@example.com", "ValidPass123!")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 8, user.AvailableVotes)
+}
+
+func TestUserService_JuryRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		appoint  bool
+		wantRole string
+		wantErr  error
+	}{
+		{"appoint user", models.RoleUser, true, models.RoleJury, nil},
+		{"appoint existing juror", models.RoleJury, true, models.RoleJury, nil},
+		{"appoint admin", models.RoleAdmin, true, "", ErrInvalidRoleChange},
+		{"dismiss juror", models.RoleJury, false, models.RoleUser, nil},
+		{"dismiss admin", models.RoleAdmin, false, "", ErrInvalidRoleChange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, service := setupTest()
+			user := createTestUser()
+			user.Role = tt.role
+			mockRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+			mockRepo.On("Update", mock.Anything, user).Return(nil)
+
+			change := service.DismissJury
+			if tt.appoint {
+				change = service.AppointJury
+			}
+			updated, err := change(context.Background(), user.UserID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRole, updated.Role)
+		})
+	}
+}
+
+func TestUserService_AssignRole(t *testing.T) {
+	actorID := uuid.New()
+	tests := []struct {
+		name     string
+		role     string
+		assign   string
+		revoke   bool
+		self     bool
+		wantRole string
+		wantErr  error
+	}{
+		{"assign staff role", models.RoleUser, models.RoleContentEditor, false, false, models.RoleContentEditor, nil},
+		{"demote admin", models.RoleAdmin, models.RoleResultsOfficer, false, false, models.RoleResultsOfficer, nil},
+		{"revoke role", models.RoleModerator, "", true, false, models.RoleUser, nil},
+		{"unknown role", models.RoleUser, "superuser", false, false, "", ErrUnknownRole},
+		{"own role", models.RoleAdmin, models.RoleUser, false, true, "", ErrInvalidRoleChange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo, service := setupTest()
+			user := createTestUser()
+			user.Role = tt.role
+			if tt.self {
+				user.UserID = actorID
+			}
+			mockRepo.On("GetByID", mock.Anything, user.UserID).Return(user, nil)
+			mockRepo.On("Update", mock.Anything, user).Return(nil)
+
+			var updated *models.User
+			var err error
+			if tt.revoke {
+				updated, err = service.RevokeRole(context.Background(), actorID, user.UserID)
+			} else {
+				updated, err = service.AssignRole(context.Background(), actorID, user.UserID, tt.assign)
+			}
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRole, updated.Role)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+)
+
+var (
+	ErrInvalidVotePolicy = errors.New("invalid vote policy")
+	ErrInvalidVoteCount  = errors.New("vote count must be positive")
+	ErrPolicyNotBudgeted = errors.New("vote policy has no budget to reset")
+)
+
+// VoteAllocationService manages how many votes users may cast
+type VoteAllocationService interface {
+	SetPolicy(ctx context.Context, editionID uuid.UUID, policy string, budget int, roleBudgets map[string]int) (*models.Edition, error)
+	GrantVotes(ctx context.Context, userID uuid.UUID, count int) (*models.User, error)
+	RevokeVotes(ctx context.Context, userID uuid.UUID, count int) (*models.User, error)
+	ResetBudgets(ctx context.Context, editionID uuid.UUID) (int64, error)
+}
+
+type voteAllocationService struct {
+	editionRepo repositories.EditionRepository
+	userRepo    repositories.UserRepository
+}
+
+func NewVoteAllocationService(editionRepo repositories.EditionRepository, userRepo repositories.UserRepository) VoteAllocationService {
+	return &voteAllocationService{
+		editionRepo: editionRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// SetPolicy replaces the edition's vote policy. Existing budgets are left
+// alone until ResetBudgets is called.
+func (s *voteAllocationService) SetPolicy(ctx context.Context, editionID uuid.UUID, policy string, budget int, roleBudgets map[string]int) (*models.Edition, error) {
+	switch policy {
+	case models.VotePolicyFixed, models.VotePolicyPerRole, models.VotePolicyPerCategory, models.VotePolicyUnlimited:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidVotePolicy, policy)
+	}
+	if budget < 0 {
+		return nil, fmt.Errorf("%w: budget cannot be negative", ErrInvalidVotePolicy)
+	}
+	for role, roleBudget := range roleBudgets {
+		if role == "" || roleBudget < 0 {
+			return nil, fmt.Errorf("%w: invalid budget for role %q", ErrInvalidVotePolicy, role)
+		}
+	}
+
+	edition, err := s.editionRepo.GetByID(ctx, editionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edition: %w", err)
+	}
+	if edition == nil {
+		return nil, ErrEditionNotFound
+	}
+
+	edition.VotePolicy = policy
+	edition.VoteBudget = budget
+	edition.RoleVoteBudgets = roleBudgets
+	if err := s.editionRepo.Update(ctx, edition); err != nil {
+		return nil, fmt.Errorf("failed to update vote policy: %w", err)
+	}
+	return edition, nil
+}
+
+func (s *voteAllocationService) GrantVotes(ctx context.Context, userID uuid.UUID, count int) (*models.User, error) {
+	return s.adjust(ctx, userID, count, count)
+}
+
+// RevokeVotes takes up to count votes away; a budget never drops below zero.
+func (s *voteAllocationService) RevokeVotes(ctx context.Context, userID uuid.UUID, count int) (*models.User, error) {
+	return s.adjust(ctx, userID, count, -count)
+}
+
+func (s *voteAllocationService) adjust(ctx context.Context, userID uuid.UUID, count, delta int) (*models.User, error) {
+	if count <= 0 {
+		return nil, ErrInvalidVoteCount
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.userRepo.AdjustAvailableVotes(ctx, userID, delta); err != nil {
+		return nil, fmt.Errorf("failed to adjust votes: %w", err)
+	}
+
+	user.AvailableVotes = max(user.AvailableVotes+delta, 0)
+	return user, nil
+}
+
+// ResetBudgets gives every user the edition's budget for their role, less any
+// votes they have already cast in it that were not voided. Deleted users are
+// skipped. It is meant to be run when a new edition opens and returns the
+// number of users updated.
+func (s *voteAllocationService) ResetBudgets(ctx context.Context, editionID uuid.UUID) (int64, error) {
+	edition, err := resolveEdition(ctx, s.editionRepo, editionID)
+	if err != nil {
+		return 0, err
+	}
+	if !edition.LimitsVotes() {
+		return 0, ErrPolicyNotBudgeted
+	}
+
+	var roleBudgets map[string]int
+	if edition.VotePolicy == models.VotePolicyPerRole {
+		roleBudgets = edition.RoleVoteBudgets
+	}
+
+	updated, err := s.userRepo.ResetAvailableVotes(ctx, edition.EditionID, edition.VoteBudget, roleBudgets)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset vote budgets: %w", err)
+	}
+	return updated, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nyashahama/music-awards/internal/models"
@@ -14,77 +15,164 @@ var (
 	ErrNoVotesAvailable       = errors.New("no votes available")
 	ErrAlreadyVotedInCategory = errors.New("already voted in category")
 	ErrVotingPeriodClosed     = errors.New("voting period is closed")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrRankedBallotRequired   = errors.New("category requires a ranked ballot")
+	ErrNotRankedChoice        = errors.New("category does not accept ranked ballots")
+	ErrInvalidBallot          = errors.New("invalid ballot")
+	ErrNomineeNotInCategory   = errors.New("nominee is not in category")
+	ErrVoteLocked             = errors.New("vote is under fraud review")
+	ErrVoteChangeLimit        = errors.New("vote change limit reached")
+	ErrVoteChangeCooldown     = errors.New("vote was changed too recently")
+	ErrEmailNotVerified       = errors.New("email address not verified")
 )
 
+// VoteChangeCooldownError reports how long until a vote may be changed again.
+// It matches ErrVoteChangeCooldown.
+type VoteChangeCooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *VoteChangeCooldownError) Error() string {
+	return fmt.Sprintf("%s: retry in %s", ErrVoteChangeCooldown, e.RetryAfter.Round(time.Second))
+}
+
+func (e *VoteChangeCooldownError) Is(target error) bool {
+	return target == ErrVoteChangeCooldown
+}
+
 type VotingMechanismService interface {
 	CastVote(ctx context.Context, userID, nomineeID, categoryID uuid.UUID) (*models.Vote, error)
+	CastBallot(ctx context.Context, userID, categoryID uuid.UUID, rankings []uuid.UUID) (*models.Vote, error)
 	GetVote(ctx context.Context, voteID uuid.UUID) (*models.Vote, error)
-	ChangeVote(ctx context.Context, voteID uuid.UUID, newNomineeID uuid.UUID) (*models.Vote, error)
+	ChangeVote(ctx context.Context, voteID, changedBy, newNomineeID uuid.UUID) (*models.Vote, error)
+	ChangeBallot(ctx context.Context, voteID, changedBy uuid.UUID, rankings []uuid.UUID) (*models.Vote, error)
+	GetVoteHistory(ctx context.Context, voteID uuid.UUID) ([]models.VoteChange, error)
 	GetUserVotes(ctx context.Context, userID uuid.UUID) ([]models.Vote, error)
 	HasVotedInCategory(ctx context.Context, userID, categoryID uuid.UUID) (bool, error)
 	GetCategoryVotes(ctx context.Context, categoryID uuid.UUID) ([]models.Vote, error)
 	ValidateVotingPeriod(ctx context.Context, categoryID uuid.UUID) (bool, error)
 	DeleteVote(ctx context.Context, voteID uuid.UUID) error
+	UndeleteVote(ctx context.Context, voteID uuid.UUID) (*models.Vote, error)
 	GetAvailableVotes(ctx context.Context, userID uuid.UUID) (int, error)
 	GetAllVotes(ctx context.Context) ([]models.Vote, error)
 }
 
 type votingMechanismService struct {
-	voteRepo repositories.VoteRepository
-	userRepo repositories.UserRepository
+	voteRepo     repositories.VoteRepository
+	userRepo     repositories.UserRepository
+	categoryRepo repositories.CategoryRepository
+	editionRepo  repositories.EditionRepository
+	linkRepo     repositories.NomineeCategoryRepository
+	uow          repositories.UnitOfWork
+	now          func() time.Time
 }
 
-func NewVotingMechanismService(voteRepo repositories.VoteRepository, userRepo repositories.UserRepository) VotingMechanismService {
+func NewVotingMechanismService(
+	voteRepo repositories.VoteRepository,
+	userRepo repositories.UserRepository,
+	categoryRepo repositories.CategoryRepository,
+	editionRepo repositories.EditionRepository,
+	linkRepo repositories.NomineeCategoryRepository,
+	uow repositories.UnitOfWork,
+) VotingMechanismService {
 	return &votingMechanismService{
-		voteRepo: voteRepo,
-		userRepo: userRepo,
+		voteRepo:     voteRepo,
+		userRepo:     userRepo,
+		categoryRepo: categoryRepo,
+		editionRepo:  editionRepo,
+		linkRepo:     linkRepo,
+		uow:          uow,
+		now:          time.Now,
 	}
 }
 
 func (s *votingMechanismService) CastVote(ctx context.Context, userID, nomineeID, categoryID uuid.UUID) (*models.Vote, error) {
-	// Check if user exists and has votes
-	user, err := s.userRepo.GetByID(ctx, userID)
-	if err != nil {
-		return nil, fmt.Errorf("user not found: %w", err)
-	}
+	return s.castVote(ctx, userID, categoryID, []uuid.UUID{nomineeID}, false)
+}
 
-	if user.AvailableVotes <= 0 {
-		return nil, ErrNoVotesAvailable
+// CastBallot records a ranked ballot in a ranked-choice category. The first
+// preference doubles as the vote's nominee.
+func (s *votingMechanismService) CastBallot(ctx context.Context, userID, categoryID uuid.UUID, rankings []uuid.UUID) (*models.Vote, error) {
+	if err := validateBallot(rankings); err != nil {
+		return nil, err
 	}
+	return s.castVote(ctx, userID, categoryID, rankings, true)
+}
 
-	hasVoted, err := s.HasVotedInCategory(ctx, userID, categoryID)
+// castVote records a vote inside a single transaction. The user's row is
+// locked first, so concurrent requests from the same user are serialised and
+// cannot overspend the vote budget or vote twice in a category. Which of
+// those limits apply depends on the vote policy of the category's edition.
+func (s *votingMechanismService) castVote(ctx context.Context, userID, categoryID uuid.UUID, nominees []uuid.UUID, ranked bool) (*models.Vote, error) {
+	category, err := s.openCategory(ctx, categoryID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking existing vote: %w", err)
+		return nil, err
 	}
-	if hasVoted {
-		return nil, ErrAlreadyVotedInCategory
+	if err := checkVotingMethod(category, ranked); err != nil {
+		return nil, err
 	}
-
-	// Validate voting period - implement actual validation
-	isOpen, err := s.ValidateVotingPeriod(ctx, categoryID)
-	if err != nil {
-		return nil, fmt.Errorf("error validating voting period: %w", err)
+	if err := s.checkNominees(ctx, categoryID, nominees); err != nil {
+		return nil, err
 	}
-	if !isOpen {
-		return nil, ErrVotingPeriodClosed
-	}
-
-	// Decrement votes first
-	if err := s.userRepo.DecrementAvailableVotes(ctx, userID); err != nil {
-		return nil, fmt.Errorf("insufficient votes: %w", err)
+	edition, err := resolveEdition(ctx, s.editionRepo, category.EditionID)
+	if err != nil {
+		return nil, err
 	}
 
 	vote := &models.Vote{
 		VoteID:     uuid.New(),
 		UserID:     userID,
-		NomineeID:  nomineeID,
+		EditionID:  category.EditionID,
+		NomineeID:  nominees[0],
 		CategoryID: categoryID,
+		Status:     models.VoteStatusValid,
+	}
+	if ranked {
+		vote.Rankings = newBallotRankings(vote.VoteID, nominees)
 	}
 
-	if err := s.voteRepo.Create(ctx, vote); err != nil {
-		// Rollback vote count if vote creation fails
-		s.userRepo.IncrementAvailableVotes(ctx, userID)
-		return nil, fmt.Errorf("failed to cast vote: %w", err)
+	err = s.uow.Do(ctx, func(tx repositories.Tx) error {
+		user, err := tx.Users().GetByIDForUpdate(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if !user.IsEmailVerified() {
+			return ErrEmailNotVerified
+		}
+		vote.VoterClass = models.VoterClassFor(user.Role)
+
+		if edition.LimitsVotes() && user.AvailableVotes <= 0 {
+			return ErrNoVotesAvailable
+		}
+
+		if edition.OneVotePerCategory() {
+			existing, err := tx.Votes().GetByUserAndCategory(ctx, userID, categoryID)
+			if err != nil {
+				return fmt.Errorf("error checking existing vote: %w", err)
+			}
+			if existing != nil {
+				return ErrAlreadyVotedInCategory
+			}
+		}
+
+		if edition.LimitsVotes() {
+			if err := tx.Users().DecrementAvailableVotes(ctx, userID); err != nil {
+				return fmt.Errorf("insufficient votes: %w", err)
+			}
+		}
+		if err := tx.Votes().Create(ctx, vote); err != nil {
+			return fmt.Errorf("failed to cast vote: %w", err)
+		}
+		if err := recordVoteAudit(ctx, tx.Audits(), vote.VoteID); err != nil {
+			return fmt.Errorf("failed to audit vote: %w", err)
+		}
+		return appendVoteLedger(ctx, tx.Ledger(), models.LedgerActionCreate, vote)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return vote, nil
@@ -98,19 +186,160 @@ func (s *votingMechanismService) GetVote(ctx context.Context, voteID uuid.UUID)
 	return vote, nil
 }
 
-func (s *votingMechanismService) ChangeVote(ctx context.Context, voteID uuid.UUID, newNomineeID uuid.UUID) (*models.Vote, error) {
-	vote, err := s.voteRepo.GetByID(ctx, voteID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find vote: %w", err)
+// ChangeVote moves a vote to another nominee on behalf of changedBy, who is
+// the voter or an admin.
+func (s *votingMechanismService) ChangeVote(ctx context.Context, voteID, changedBy, newNomineeID uuid.UUID) (*models.Vote, error) {
+	return s.changeVote(ctx, voteID, changedBy, []uuid.UUID{newNomineeID}, false)
+}
+
+// ChangeBallot replaces the preference order of a ranked ballot.
+func (s *votingMechanismService) ChangeBallot(ctx context.Context, voteID, changedBy uuid.UUID, rankings []uuid.UUID) (*models.Vote, error) {
+	if err := validateBallot(rankings); err != nil {
+		return nil, err
 	}
-	// Update nominee
-	vote.NomineeID = newNomineeID
-	if err := s.voteRepo.Update(ctx, vote); err != nil {
-		return nil, fmt.Errorf("failed to update vote: %w", err)
+	return s.changeVote(ctx, voteID, changedBy, rankings, true)
+}
+
+// changeVote applies a change within the category's change limits and
+// records it in the vote's history.
+func (s *votingMechanismService) changeVote(ctx context.Context, voteID, changedBy uuid.UUID, nominees []uuid.UUID, ranked bool) (*models.Vote, error) {
+	var vote *models.Vote
+	err := s.uow.Do(ctx, func(tx repositories.Tx) error {
+		var err error
+		vote, err = tx.Votes().GetByIDForUpdate(ctx, voteID)
+		if err != nil {
+			return fmt.Errorf("failed to find vote: %w", err)
+		}
+		if vote.IsLocked() {
+			return ErrVoteLocked
+		}
+		category, err := s.openCategory(ctx, vote.CategoryID)
+		if err != nil {
+			return err
+		}
+		if err := checkVotingMethod(category, ranked); err != nil {
+			return err
+		}
+		// Checked against the vote's own category, so a vote can never be
+		// moved to a nominee of another category
+		if err := s.checkNominees(ctx, vote.CategoryID, nominees); err != nil {
+			return err
+		}
+		now := s.now()
+		if err := checkChangeLimits(category, vote, now); err != nil {
+			return err
+		}
+
+		change := &models.VoteChange{
+			VoteID:            vote.VoteID,
+			ChangedBy:         &changedBy,
+			PreviousNomineeID: vote.NomineeID,
+			NewNomineeID:      nominees[0],
+			PreviousRankings:  vote.RankedNominees(),
+			ChangedAt:         now,
+		}
+		if ranked {
+			change.NewRankings = nominees
+		}
+
+		vote.NomineeID = nominees[0]
+		vote.ChangeCount++
+		vote.LastChangedAt = &now
+		if err := tx.Votes().Update(ctx, vote); err != nil {
+			return fmt.Errorf("failed to update vote: %w", err)
+		}
+		if err := tx.Votes().RecordChange(ctx, change); err != nil {
+			return fmt.Errorf("failed to record vote change: %w", err)
+		}
+
+		if ranked {
+			vote.Rankings = newBallotRankings(vote.VoteID, nominees)
+			if err := tx.Votes().ReplaceRankings(ctx, vote.VoteID, vote.Rankings); err != nil {
+				return fmt.Errorf("failed to update ballot: %w", err)
+			}
+		}
+		return appendVoteLedger(ctx, tx.Ledger(), models.LedgerActionChange, vote)
+	})
+	if err != nil {
+		return nil, err
 	}
 	return vote, nil
 }
 
+// checkChangeLimits enforces the category's maximum number of changes per
+// vote and the cooldown since the vote was last changed.
+func checkChangeLimits(category *models.Category, vote *models.Vote, now time.Time) error {
+	if category.MaxVoteChanges != nil && vote.ChangeCount >= *category.MaxVoteChanges {
+		return ErrVoteChangeLimit
+	}
+	if vote.LastChangedAt != nil {
+		if wait := vote.LastChangedAt.Add(category.VoteChangeCooldown()).Sub(now); wait > 0 {
+			return &VoteChangeCooldownError{RetryAfter: wait}
+		}
+	}
+	return nil
+}
+
+// GetVoteHistory returns every change made to the vote, oldest first.
+func (s *votingMechanismService) GetVoteHistory(ctx context.Context, voteID uuid.UUID) ([]models.VoteChange, error) {
+	changes, err := s.voteRepo.GetChanges(ctx, voteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vote history: %w", err)
+	}
+	return changes, nil
+}
+
+// checkVotingMethod ensures ranked ballots go to ranked-choice categories and
+// single picks to the others.
+func checkVotingMethod(category *models.Category, ranked bool) error {
+	switch {
+	case category.IsRankedChoice() && !ranked:
+		return ErrRankedBallotRequired
+	case !category.IsRankedChoice() && ranked:
+		return ErrNotRankedChoice
+	}
+	return nil
+}
+
+// checkNominees returns ErrNomineeNotInCategory unless every nominee is
+// linked to the category.
+func (s *votingMechanismService) checkNominees(ctx context.Context, categoryID uuid.UUID, nominees []uuid.UUID) error {
+	ok, err := s.linkRepo.ContainsNominees(ctx, categoryID, nominees)
+	if err != nil {
+		return fmt.Errorf("failed to check nominees: %w", err)
+	}
+	if !ok {
+		return ErrNomineeNotInCategory
+	}
+	return nil
+}
+
+// validateBallot requires at least one preference and no nominee ranked twice.
+func validateBallot(rankings []uuid.UUID) error {
+	if len(rankings) == 0 {
+		return fmt.Errorf("%w: at least one nominee must be ranked", ErrInvalidBallot)
+	}
+	seen := make(map[uuid.UUID]bool, len(rankings))
+	for _, nomineeID := range rankings {
+		if nomineeID == uuid.Nil {
+			return fmt.Errorf("%w: empty nominee ID", ErrInvalidBallot)
+		}
+		if seen[nomineeID] {
+			return fmt.Errorf("%w: nominee %s ranked more than once", ErrInvalidBallot, nomineeID)
+		}
+		seen[nomineeID] = true
+	}
+	return nil
+}
+
+func newBallotRankings(voteID uuid.UUID, nominees []uuid.UUID) []models.BallotRanking {
+	rankings := make([]models.BallotRanking, len(nominees))
+	for i, nomineeID := range nominees {
+		rankings[i] = models.BallotRanking{VoteID: voteID, Position: i + 1, NomineeID: nomineeID}
+	}
+	return rankings
+}
+
 func (s *votingMechanismService) GetUserVotes(ctx context.Context, userID uuid.UUID) ([]models.Vote, error) {
 	votes, err := s.voteRepo.GetByUser(ctx, userID)
 	if err != nil {
@@ -127,39 +356,177 @@ func (s *votingMechanismService) HasVotedInCategory(ctx context.Context, userID,
 	return vote != nil, nil
 }
 
+// GetCategoryVotes returns the raw votes of a category. Like its results, they
+// are withheld from staff while the category's results are hidden.
 func (s *votingMechanismService) GetCategoryVotes(ctx context.Context, categoryID uuid.UUID) ([]models.Vote, error) {
-	allVotes, err := s.voteRepo.GetAll(ctx)
+	category, err := s.categoryRepo.GetByID(ctx, categoryID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve votes: %w", err)
+		return nil, fmt.Errorf("failed to get category: %w", err)
 	}
-	var filtered []models.Vote
-	for _, v := range allVotes {
-		if v.CategoryID == categoryID {
-			filtered = append(filtered, v)
-		}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+	if !previewable(s.now())(category) {
+		return nil, ErrResultsHidden
 	}
-	return filtered, nil
+
+	votes, err := s.voteRepo.GetByCategories(ctx, []uuid.UUID{categoryID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve votes: %w", err)
+	}
+	return votes, nil
 }
 
 func (s *votingMechanismService) ValidateVotingPeriod(ctx context.Context, categoryID uuid.UUID) (bool, error) {
+	_, err := s.openCategory(ctx, categoryID)
+	if errors.Is(err, ErrVotingPeriodClosed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
 	return true, nil
 }
 
+// openCategory loads the category and returns ErrVotingPeriodClosed when its
+// window does not currently accept changes.
+func (s *votingMechanismService) openCategory(ctx context.Context, categoryID uuid.UUID) (*models.Category, error) {
+	category, err := s.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+	if !category.IsVotingOpen(s.now()) {
+		return nil, ErrVotingPeriodClosed
+	}
+	return category, nil
+}
+
+// DeleteVote withdraws a vote and returns it to the user's budget. The vote
+// is soft deleted, so an admin can undo it with UndeleteVote. The user's row
+// is locked before the vote's, in the same order CastVote takes them, so a
+// vote can only be refunded once.
 func (s *votingMechanismService) DeleteVote(ctx context.Context, voteID uuid.UUID) error {
 	vote, err := s.voteRepo.GetByID(ctx, voteID)
 	if err != nil {
 		return err
 	}
 
-	if err := s.voteRepo.Delete(ctx, voteID); err != nil {
+	category, err := s.openCategory(ctx, vote.CategoryID)
+	if err != nil {
+		return err
+	}
+	edition, err := resolveEdition(ctx, s.editionRepo, category.EditionID)
+	if err != nil {
 		return err
 	}
 
-	// Return vote to user
-	if err := s.userRepo.IncrementAvailableVotes(ctx, vote.UserID); err != nil {
-		return fmt.Errorf("failed to return vote: %w", err)
+	return s.uow.Do(ctx, func(tx repositories.Tx) error {
+		if _, err := tx.Users().GetByIDForUpdate(ctx, vote.UserID); err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		// Re-read under the lock; a concurrent delete leaves nothing to find.
+		// Votes held or voided by fraud review cannot be withdrawn for a refund.
+		locked, err := tx.Votes().GetByIDForUpdate(ctx, voteID)
+		if err != nil {
+			return err
+		}
+		if locked.IsLocked() {
+			return ErrVoteLocked
+		}
+
+		if err := tx.Votes().Delete(ctx, voteID); err != nil {
+			return err
+		}
+		if err := appendVoteLedger(ctx, tx.Ledger(), models.LedgerActionDelete, locked); err != nil {
+			return err
+		}
+
+		if !edition.LimitsVotes() {
+			return nil
+		}
+
+		// Return vote to user
+		if err := tx.Users().IncrementAvailableVotes(ctx, vote.UserID); err != nil {
+			return fmt.Errorf("failed to return vote: %w", err)
+		}
+		return nil
+	})
+}
+
+// UndeleteVote restores a deleted vote, spending a vote from the user's
+// budget again. It is held to the same rules as casting the vote: the
+// category must be open, its nominees still eligible, the user's email
+// verified and, where the edition allows one vote per category, the user must
+// not have voted again since.
+// Restoring a vote from fraud review is FraudService.RestoreVote.
+func (s *votingMechanismService) UndeleteVote(ctx context.Context, voteID uuid.UUID) (*models.Vote, error) {
+	var vote *models.Vote
+	err := s.uow.Do(ctx, func(tx repositories.Tx) error {
+		// Locking the deleted vote first is safe: DeleteVote and CastVote
+		// never lock a deleted vote, so they cannot be waiting on each other.
+		var err error
+		vote, err = tx.Votes().GetDeletedByIDForUpdate(ctx, voteID)
+		if err != nil {
+			return err
+		}
+
+		category, err := s.openCategory(ctx, vote.CategoryID)
+		if err != nil {
+			return err
+		}
+		nominees := vote.RankedNominees()
+		if len(nominees) == 0 {
+			nominees = []uuid.UUID{vote.NomineeID}
+		}
+		if err := s.checkNominees(ctx, vote.CategoryID, nominees); err != nil {
+			return err
+		}
+		edition, err := resolveEdition(ctx, s.editionRepo, category.EditionID)
+		if err != nil {
+			return err
+		}
+
+		user, err := tx.Users().GetByIDForUpdate(ctx, vote.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return ErrUserNotFound
+		}
+		if !user.IsEmailVerified() {
+			return ErrEmailNotVerified
+		}
+		if edition.LimitsVotes() && user.AvailableVotes <= 0 {
+			return ErrNoVotesAvailable
+		}
+		if edition.OneVotePerCategory() {
+			existing, err := tx.Votes().GetByUserAndCategory(ctx, vote.UserID, vote.CategoryID)
+			if err != nil {
+				return fmt.Errorf("error checking existing vote: %w", err)
+			}
+			if existing != nil {
+				return ErrAlreadyVotedInCategory
+			}
+		}
+
+		if edition.LimitsVotes() {
+			if err := tx.Users().DecrementAvailableVotes(ctx, vote.UserID); err != nil {
+				return fmt.Errorf("insufficient votes: %w", err)
+			}
+		}
+		if _, err := tx.Votes().Restore(ctx, voteID); err != nil {
+			return fmt.Errorf("failed to restore vote: %w", err)
+		}
+		vote.DeletedAt.Valid = false
+		return appendVoteLedger(ctx, tx.Ledger(), models.LedgerActionRestore, vote)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return vote, nil
 }
 
 func (s *votingMechanismService) GetAvailableVotes(ctx context.Context, userID uuid.UUID) (int, error) {
@@ -170,6 +537,25 @@ func (s *votingMechanismService) GetAvailableVotes(ctx context.Context, userID u
 	return user.AvailableVotes, nil
 }
 
+// GetAllVotes returns the raw votes of every category whose results are not
+// hidden.
 func (s *votingMechanismService) GetAllVotes(ctx context.Context) ([]models.Vote, error) {
-	return s.voteRepo.GetAll(ctx)
+	categories, err := s.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	include := previewable(s.now())
+	var categoryIDs []uuid.UUID
+	for i := range categories {
+		if include(&categories[i]) {
+			categoryIDs = append(categoryIDs, categories[i].CategoryID)
+		}
+	}
+
+	votes, err := s.voteRepo.GetByCategories(ctx, categoryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve votes: %w", err)
+	}
+	return votes, nil
 }
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// openVotingDB migrates the Postgres database at TEST_DATABASE_URL and
+// returns a connection to it. Unlike the repository tests it does not wrap
+// the test in a transaction, since the concurrent votes have to commit
+// against each other; callers remove the rows they create. Tests that need
+// it are skipped when the variable is unset.
+func openVotingDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	m, err := migrate.New("file://../../migrations", url)
+	require.NoError(t, err)
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		t.Fatalf("migrating test database: %v", err)
+	}
+	m.Close()
+
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// seedVoting creates a fixed-budget edition with one nominee in each of n
+// open categories and a verified user with five votes. Everything but the
+// append-only ledger entries is deleted when the test ends.
+func seedVoting(t *testing.T, db *gorm.DB, n int) (models.User, uuid.UUID, []uuid.UUID) {
+	t.Helper()
+	edition := models.Edition{EditionID: uuid.New(), Name: "Test Edition", Year: 10000 + rand.IntN(1000000), VotePolicy: models.VotePolicyFixed, VoteBudget: 5}
+	nominee := models.Nominee{NomineeID: uuid.New(), Name: "Nominee"}
+	user := models.User{UserID: uuid.New(), Username: "voter-" + uuid.NewString(), Email: "voter-" + uuid.NewString() + "@example.com",
+		PasswordHash: "x", Role: models.RoleUser, AvailableVotes: 5, EmailVerifiedAt: &votingNow}
+	rows := []any{&edition, &nominee, &user}
+	categories := make([]uuid.UUID, n)
+	for i := range categories {
+		category := models.Category{CategoryID: uuid.New(), EditionID: edition.EditionID, Name: "Category"}
+		categories[i] = category.CategoryID
+		rows = append(rows, &category, &models.NomineeCategory{NomineeID: nominee.NomineeID, CategoryID: category.CategoryID, EditionID: edition.EditionID})
+	}
+	for _, row := range rows {
+		require.NoError(t, db.Omit(clause.Associations).Create(row).Error)
+	}
+
+	t.Cleanup(func() {
+		db.Unscoped().Where("user_id = ?", user.UserID).Delete(&models.Vote{})
+		db.Unscoped().Where("nominee_id = ?", nominee.NomineeID).Delete(&models.Nominee{})
+		db.Unscoped().Where("category_id IN ?", categories).Delete(&models.Category{})
+		db.Where("edition_id = ?", edition.EditionID).Delete(&models.Edition{})
+		db.Unscoped().Where("user_id = ?", user.UserID).Delete(&models.User{})
+	})
+	return user, nominee.NomineeID, categories
+}
+
+// TestVotingMechanismService_ConcurrentCastVotePostgres runs the concurrent
+// cast scenarios against the real repositories, so the row locks taken in
+// SQL are what keep the votes and budget consistent.
+func TestVotingMechanismService_ConcurrentCastVotePostgres(t *testing.T) {
+	const attempts = 20
+	db := openVotingDB(t)
+	service := NewVotingMechanismService(
+		repositories.NewVoteRepository(db),
+		repositories.NewUserRepository(db),
+		repositories.NewCategoryRepository(db),
+		repositories.NewEditionRepository(db),
+		repositories.NewNomineeCategoryRepository(db),
+		repositories.NewUnitOfWork(db),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	t.Run("same category", func(t *testing.T) {
+		user, nomineeID, categories := seedVoting(t, db, 1)
+
+		errs := hammer(attempts, func(int) error {
+			_, err := service.CastVote(ctx, user.UserID, nomineeID, categories[0])
+			return err
+		})
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, ErrAlreadyVotedInCategory)
+		}
+		assert.Equal(t, 1, succeeded)
+		var votes int64
+		require.NoError(t, db.Model(&models.Vote{}).Where("user_id = ?", user.UserID).Count(&votes).Error)
+		assert.Equal(t, int64(1), votes)
+		var got models.User
+		require.NoError(t, db.First(&got, "user_id = ?", user.UserID).Error)
+		assert.Equal(t, 4, got.AvailableVotes)
+	})
+
+	t.Run("budget across categories", func(t *testing.T) {
+		user, nomineeID, categories := seedVoting(t, db, attempts)
+
+		errs := hammer(attempts, func(i int) error {
+			_, err := service.CastVote(ctx, user.UserID, nomineeID, categories[i])
+			return err
+		})
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, ErrNoVotesAvailable)
+		}
+		assert.Equal(t, 5, succeeded)
+		var votes int64
+		require.NoError(t, db.Model(&models.Vote{}).Where("user_id = ?", user.UserID).Count(&votes).Error)
+		assert.Equal(t, int64(5), votes)
+		var got models.User
+		require.NoError(t, db.First(&got, "user_id = ?", user.UserID).Error)
+		assert.Zero(t, got.AvailableVotes)
+	})
+}
@@ -0,0 +1,1219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nyashahama/music-awards/internal/models"
+	"github.com/nyashahama/music-awards/internal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockVoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockVoteRepository) Create(ctx context.Context, vote *models.Vote) error {
+	args := m.Called(ctx, vote)
+	return args.Error(0)
+}
+
+func (m *MockVoteRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Vote, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Vote), args.Error(1)
+}
+
+func (m *MockVoteRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Vote, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Vote), args.Error(1)
+}
+
+func (m *MockVoteRepository) GetByCategories(ctx context.Context, categoryIDs []uuid.UUID) ([]models.Vote, error) {
+	args := m.Called(ctx, categoryIDs)
+	return args.Get(0).([]models.Vote), args.Error(1)
+}
+
+func (m *MockVoteRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]models.Vote, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Vote), args.Error(1)
+}
+
+func (m *MockVoteRepository) GetByUserAndCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Vote, error) {
+	args := m.Called(ctx, userID, categoryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Vote), args.Error(1)
+}
+
+func (m *MockVoteRepository) Update(ctx context.Context, vote *models.Vote) error {
+	args := m.Called(ctx, vote)
+	return args.Error(0)
+}
+
+func (m *MockVoteRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockVoteRepository) GetDeletedByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Vote, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Vote), args.Error(1)
+}
+
+func (m *MockVoteRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockVoteRepository) ReplaceRankings(ctx context.Context, voteID uuid.UUID, rankings []models.BallotRanking) error {
+	args := m.Called(ctx, voteID, rankings)
+	return args.Error(0)
+}
+
+func (m *MockVoteRepository) RecordChange(ctx context.Context, change *models.VoteChange) error {
+	args := m.Called(ctx, change)
+	return args.Error(0)
+}
+
+func (m *MockVoteRepository) GetChanges(ctx context.Context, voteID uuid.UUID) ([]models.VoteChange, error) {
+	args := m.Called(ctx, voteID)
+	return args.Get(0).([]models.VoteChange), args.Error(1)
+}
+
+func (m *MockVoteRepository) GetBallots(ctx context.Context, categoryID uuid.UUID) ([][]uuid.UUID, error) {
+	args := m.Called(ctx, categoryID)
+	return args.Get(0).([][]uuid.UUID), args.Error(1)
+}
+
+func (m *MockVoteRepository) TallyByCategory(ctx context.Context, categoryID uuid.UUID) ([]repositories.NomineeTally, error) {
+	args := m.Called(ctx, categoryID)
+	return args.Get(0).([]repositories.NomineeTally), args.Error(1)
+}
+
+func (m *MockVoteRepository) TallyByEdition(ctx context.Context, editionID uuid.UUID) ([]repositories.NomineeTally, error) {
+	args := m.Called(ctx, editionID)
+	return args.Get(0).([]repositories.NomineeTally), args.Error(1)
+}
+
+type MockCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetByName(ctx context.Context, editionID uuid.UUID, name string) (*models.Category, error) {
+	args := m.Called(ctx, editionID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetByEdition(ctx context.Context, editionID uuid.UUID) ([]models.Category, error) {
+	args := m.Called(ctx, editionID)
+	return args.Get(0).([]models.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetActive(ctx context.Context) ([]models.Category, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCategoryRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCategoryRepository) CountVotes(ctx context.Context, id uuid.UUID) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockEditionRepository struct {
+	mock.Mock
+}
+
+func (m *MockEditionRepository) Create(ctx context.Context, edition *models.Edition) error {
+	args := m.Called(ctx, edition)
+	return args.Error(0)
+}
+
+func (m *MockEditionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Edition, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Edition), args.Error(1)
+}
+
+func (m *MockEditionRepository) GetByYear(ctx context.Context, year int) (*models.Edition, error) {
+	args := m.Called(ctx, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Edition), args.Error(1)
+}
+
+func (m *MockEditionRepository) GetActive(ctx context.Context) (*models.Edition, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Edition), args.Error(1)
+}
+
+func (m *MockEditionRepository) GetAll(ctx context.Context) ([]models.Edition, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Edition), args.Error(1)
+}
+
+func (m *MockEditionRepository) Update(ctx context.Context, edition *models.Edition) error {
+	args := m.Called(ctx, edition)
+	return args.Error(0)
+}
+
+func (m *MockEditionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockEditionRepository) SetActive(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockEditionRepository) CountCategories(ctx context.Context, id uuid.UUID) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockNomineeCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockNomineeCategoryRepository) AddCategory(ctx context.Context, nomineeID, categoryID uuid.UUID) error {
+	args := m.Called(ctx, nomineeID, categoryID)
+	return args.Error(0)
+}
+
+func (m *MockNomineeCategoryRepository) RemoveCategory(ctx context.Context, nomineeID, categoryID uuid.UUID) error {
+	args := m.Called(ctx, nomineeID, categoryID)
+	return args.Error(0)
+}
+
+func (m *MockNomineeCategoryRepository) GetCategoriesForNominee(ctx context.Context, nomineeID uuid.UUID) ([]models.Category, error) {
+	args := m.Called(ctx, nomineeID)
+	return args.Get(0).([]models.Category), args.Error(1)
+}
+
+func (m *MockNomineeCategoryRepository) GetNomineesForCategory(ctx context.Context, categoryID uuid.UUID) ([]models.Nominee, error) {
+	args := m.Called(ctx, categoryID)
+	return args.Get(0).([]models.Nominee), args.Error(1)
+}
+
+func (m *MockNomineeCategoryRepository) UpdateCategories(ctx context.Context, nomineeID uuid.UUID, added, removed []uuid.UUID) error {
+	args := m.Called(ctx, nomineeID, added, removed)
+	return args.Error(0)
+}
+
+func (m *MockNomineeCategoryRepository) CountVotes(ctx context.Context, nomineeID uuid.UUID, categoryIDs []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, nomineeID, categoryIDs)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNomineeCategoryRepository) ContainsNominees(ctx context.Context, categoryID uuid.UUID, nomineeIDs []uuid.UUID) (bool, error) {
+	args := m.Called(ctx, categoryID, nomineeIDs)
+	return args.Bool(0), args.Error(1)
+}
+
+// nomineesInCategory returns a link repository that accepts every nominee.
+func nomineesInCategory() *MockNomineeCategoryRepository {
+	linkRepo := new(MockNomineeCategoryRepository)
+	linkRepo.On("ContainsNominees", mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Maybe()
+	return linkRepo
+}
+
+// mockUnitOfWork hands the mocked repositories to the transaction function
+// without any transactional behaviour.
+type mockUnitOfWork struct {
+	users  *MockUserRepository
+	votes  *MockVoteRepository
+	audits *MockAuditRepository
+	ledger *MockLedgerRepository
+	fraud  *MockFraudRepository
+	tokens *MockTokenRepository
+
+	sessions   *MockSessionRepository
+	identities repositories.IdentityRepository
+	twoFactor  *MockTwoFactorRepository
+}
+
+func (u *mockUnitOfWork) Do(ctx context.Context, fn func(tx repositories.Tx) error) error {
+	return fn(u)
+}
+
+func (u *mockUnitOfWork) Users() repositories.UserRepository    { return u.users }
+func (u *mockUnitOfWork) Votes() repositories.VoteRepository    { return u.votes }
+func (u *mockUnitOfWork) Audits() repositories.AuditRepository  { return u.audits }
+func (u *mockUnitOfWork) Ledger() repositories.LedgerRepository { return u.ledger }
+func (u *mockUnitOfWork) Fraud() repositories.FraudRepository   { return u.fraud }
+func (u *mockUnitOfWork) Tokens() repositories.TokenRepository  { return u.tokens }
+func (u *mockUnitOfWork) Sessions() repositories.SessionRepository {
+	return u.sessions
+}
+func (u *mockUnitOfWork) Identities() repositories.IdentityRepository {
+	return u.identities
+}
+func (u *mockUnitOfWork) TwoFactor() repositories.TwoFactorRepository {
+	return u.twoFactor
+}
+
+var votingNow = time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC)
+
+func setupVoteTest() (*MockVoteRepository, *MockUserRepository, *MockCategoryRepository, *votingMechanismService) {
+	voteRepo := new(MockVoteRepository)
+	userRepo := new(MockUserRepository)
+	categoryRepo := new(MockCategoryRepository)
+	editionRepo := new(MockEditionRepository)
+	editionRepo.On("GetByID", mock.Anything, mock.Anything).Return(&models.Edition{VotePolicy: models.VotePolicyFixed}, nil).Maybe()
+	ledgerRepo := new(MockLedgerRepository)
+	ledgerRepo.On("Append", mock.Anything, mock.Anything).Return(nil).Maybe()
+	uow := &mockUnitOfWork{users: userRepo, votes: voteRepo, audits: new(MockAuditRepository), ledger: ledgerRepo}
+	service := NewVotingMechanismService(voteRepo, userRepo, categoryRepo, editionRepo, nomineesInCategory(), uow).(*votingMechanismService)
+	service.now = func() time.Time { return votingNow }
+	return voteRepo, userRepo, categoryRepo, service
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestVotingMechanismService_ValidateVotingPeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		opensAt  *time.Time
+		closesAt *time.Time
+		wantOpen bool
+	}{
+		{"no window configured", nil, nil, true},
+		{"inside window", timePtr(votingNow.Add(-time.Hour)), timePtr(votingNow.Add(time.Hour)), true},
+		{"not yet open", timePtr(votingNow.Add(time.Hour)), nil, false},
+		{"already closed", nil, timePtr(votingNow.Add(-time.Minute)), false},
+		{"closes exactly now", nil, timePtr(votingNow), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, categoryRepo, service := setupVoteTest()
+			categoryID := uuid.New()
+			categoryRepo.On("GetByID", mock.Anything, categoryID).Return(&models.Category{
+				CategoryID:     categoryID,
+				VotingOpensAt:  tt.opensAt,
+				VotingClosesAt: tt.closesAt,
+			}, nil)
+
+			isOpen, err := service.ValidateVotingPeriod(context.Background(), categoryID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOpen, isOpen)
+		})
+	}
+}
+
+func TestVotingMechanismService_ValidateVotingPeriod_UnknownCategory(t *testing.T) {
+	_, _, categoryRepo, service := setupVoteTest()
+	categoryID := uuid.New()
+	categoryRepo.On("GetByID", mock.Anything, categoryID).Return(nil, nil)
+
+	_, err := service.ValidateVotingPeriod(context.Background(), categoryID)
+
+	assert.ErrorIs(t, err, ErrCategoryNotFound)
+}
+
+func TestVotingMechanismService_StaffReadsSkipHiddenCategories(t *testing.T) {
+	hidden := models.Category{CategoryID: uuid.New(), ResultsState: models.ResultsHidden}
+	preview := models.Category{CategoryID: uuid.New(), ResultsState: models.ResultsPreview}
+	vote := models.Vote{VoteID: uuid.New(), CategoryID: preview.CategoryID, NomineeID: uuid.New()}
+
+	t.Run("category", func(t *testing.T) {
+		voteRepo, _, categoryRepo, service := setupVoteTest()
+		categoryRepo.On("GetByID", mock.Anything, hidden.CategoryID).Return(&hidden, nil)
+
+		_, err := service.GetCategoryVotes(context.Background(), hidden.CategoryID)
+
+		assert.ErrorIs(t, err, ErrResultsHidden)
+		voteRepo.AssertNotCalled(t, "GetByCategories", mock.Anything, mock.Anything)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		voteRepo, _, categoryRepo, service := setupVoteTest()
+		categoryRepo.On("GetAll", mock.Anything).Return([]models.Category{hidden, preview}, nil)
+		voteRepo.On("GetByCategories", mock.Anything, []uuid.UUID{preview.CategoryID}).Return([]models.Vote{vote}, nil)
+
+		votes, err := service.GetAllVotes(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Vote{vote}, votes)
+	})
+}
+
+func TestVotingMechanismService_ClosedWindowRejectsChanges(t *testing.T) {
+	userID := uuid.New()
+	categoryID := uuid.New()
+	closed := &models.Category{CategoryID: categoryID, VotingClosesAt: timePtr(votingNow.Add(-time.Hour))}
+	vote := &models.Vote{VoteID: uuid.New(), UserID: userID, CategoryID: categoryID, NomineeID: uuid.New()}
+
+	t.Run("cast", func(t *testing.T) {
+		voteRepo, userRepo, categoryRepo, service := setupVoteTest()
+		userRepo.On("GetByID", mock.Anything, userID).Return(&models.User{UserID: userID, AvailableVotes: 5, EmailVerifiedAt: &votingNow}, nil)
+		voteRepo.On("GetByUserAndCategory", mock.Anything, userID, categoryID).Return(nil, nil)
+		categoryRepo.On("GetByID", mock.Anything, categoryID).Return(closed, nil)
+
+		_, err := service.CastVote(context.Background(), userID, uuid.New(), categoryID)
+
+		assert.ErrorIs(t, err, ErrVotingPeriodClosed)
+		userRepo.AssertNotCalled(t, "DecrementAvailableVotes", mock.Anything, mock.Anything)
+		voteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("change", func(t *testing.T) {
+		voteRepo, _, categoryRepo, service := setupVoteTest()
+		voteRepo.On("GetByIDForUpdate", mock.Anything, vote.VoteID).Return(vote, nil)
+		categoryRepo.On("GetByID", mock.Anything, categoryID).Return(closed, nil)
+
+		_, err := service.ChangeVote(context.Background(), vote.VoteID, userID, uuid.New())
+
+		assert.ErrorIs(t, err, ErrVotingPeriodClosed)
+		voteRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		voteRepo, userRepo, categoryRepo, service := setupVoteTest()
+		voteRepo.On("GetByID", mock.Anything, vote.VoteID).Return(vote, nil)
+		categoryRepo.On("GetByID", mock.Anything, categoryID).Return(closed, nil)
+
+		err := service.DeleteVote(context.Background(), vote.VoteID)
+
+		assert.ErrorIs(t, err, ErrVotingPeriodClosed)
+		voteRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		userRepo.AssertNotCalled(t, "IncrementAvailableVotes", mock.Anything, mock.Anything)
+	})
+}
+
+// memStore is an in-memory stand-in for the users, votes and ledger tables.
+// Like Postgres, it locks rows rather than whole transactions: a transaction
+// takes a row's lock when it reads the row for update or writes it, and
+// holds it until it ends, so only transactions touching the same rows wait
+// on each other. The ledger is locked as a whole on append, as the advisory
+// lock does. A transaction whose function fails has its writes undone.
+type memStore struct {
+	// mu guards the fields below for the length of a single call
+	mu      sync.Mutex
+	locks   map[any]*sync.Mutex
+	users   map[uuid.UUID]models.User
+	votes   map[uuid.UUID]models.Vote
+	deleted map[uuid.UUID]models.Vote
+	ledger  []models.VoteLedgerEntry
+	changes []models.VoteChange
+}
+
+func newMemStore(users ...models.User) *memStore {
+	store := &memStore{
+		locks:   make(map[any]*sync.Mutex),
+		users:   make(map[uuid.UUID]models.User),
+		votes:   make(map[uuid.UUID]models.Vote),
+		deleted: make(map[uuid.UUID]models.Vote),
+	}
+	for _, user := range users {
+		store.users[user.UserID] = user
+	}
+	return store
+}
+
+func (s *memStore) Do(ctx context.Context, fn func(tx repositories.Tx) error) error {
+	tx := &memTx{store: s, held: make(map[any]*sync.Mutex)}
+	err := fn(tx)
+
+	s.mu.Lock()
+	if err != nil {
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+	} else {
+		s.changes = append(s.changes, tx.changes...)
+	}
+	s.mu.Unlock()
+
+	for _, lock := range tx.held {
+		lock.Unlock()
+	}
+	return err
+}
+
+// roundTrip stands in for the latency of a query, giving other transactions
+// the chance to run in between a transaction's calls as they would against
+// a real database.
+func (s *memStore) roundTrip() {
+	runtime.Gosched()
+}
+
+// Ledger reads the ledger outside of any transaction.
+func (s *memStore) Ledger() repositories.LedgerRepository {
+	return memLedgerRepository{tx: &memTx{store: s, held: make(map[any]*sync.Mutex)}}
+}
+
+// memTx is a transaction on a memStore. It records the row locks it holds
+// and how to undo each of its writes.
+type memTx struct {
+	store   *memStore
+	held    map[any]*sync.Mutex
+	undo    []func()
+	changes []models.VoteChange
+}
+
+// userRow, voteRow and ledgerTable key the locks of a memStore
+type (
+	userRow     uuid.UUID
+	voteRow     uuid.UUID
+	ledgerTable struct{}
+)
+
+// lock takes the lock on key until the transaction ends, waiting for any
+// other transaction holding it.
+func (tx *memTx) lock(key any) {
+	if _, ok := tx.held[key]; ok {
+		return
+	}
+	tx.store.mu.Lock()
+	lock, ok := tx.store.locks[key]
+	if !ok {
+		lock = new(sync.Mutex)
+		tx.store.locks[key] = lock
+	}
+	tx.store.mu.Unlock()
+
+	lock.Lock()
+	tx.held[key] = lock
+}
+
+// put stores value under id in table and remove deletes id from it, both
+// remembering the previous value so that a rollback can restore it. The
+// store's mutex must be held.
+func put[T any](tx *memTx, table map[uuid.UUID]T, id uuid.UUID, value T) {
+	remember(tx, table, id)
+	table[id] = value
+}
+
+func remove[T any](tx *memTx, table map[uuid.UUID]T, id uuid.UUID) {
+	remember(tx, table, id)
+	delete(table, id)
+}
+
+func remember[T any](tx *memTx, table map[uuid.UUID]T, id uuid.UUID) {
+	prev, existed := table[id]
+	tx.undo = append(tx.undo, func() {
+		if existed {
+			table[id] = prev
+		} else {
+			delete(table, id)
+		}
+	})
+}
+
+func (tx *memTx) Users() repositories.UserRepository    { return memUserRepository{tx: tx} }
+func (tx *memTx) Votes() repositories.VoteRepository    { return memVoteRepository{tx: tx} }
+func (tx *memTx) Audits() repositories.AuditRepository  { return new(MockAuditRepository) }
+func (tx *memTx) Ledger() repositories.LedgerRepository { return memLedgerRepository{tx: tx} }
+func (tx *memTx) Fraud() repositories.FraudRepository   { return nil }
+func (tx *memTx) Tokens() repositories.TokenRepository  { return new(MockTokenRepository) }
+func (tx *memTx) Sessions() repositories.SessionRepository {
+	return new(MockSessionRepository)
+}
+func (tx *memTx) Identities() repositories.IdentityRepository {
+	return nil
+}
+func (tx *memTx) TwoFactor() repositories.TwoFactorRepository {
+	return nil
+}
+
+// memUserRepository implements only what the vote service calls inside a
+// transaction; anything else panics through the nil embedded interface.
+type memUserRepository struct {
+	repositories.UserRepository
+	tx *memTx
+}
+
+func (r memUserRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	r.tx.lock(userRow(id))
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	user, ok := store.users[id]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (r memUserRepository) DecrementAvailableVotes(ctx context.Context, userID uuid.UUID) error {
+	r.tx.lock(userRow(userID))
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	user := store.users[userID]
+	if user.AvailableVotes <= 0 {
+		return errors.New("no votes available")
+	}
+	user.AvailableVotes--
+	put(r.tx, store.users, userID, user)
+	return nil
+}
+
+func (r memUserRepository) IncrementAvailableVotes(ctx context.Context, userID uuid.UUID) error {
+	r.tx.lock(userRow(userID))
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	user := store.users[userID]
+	user.AvailableVotes++
+	put(r.tx, store.users, userID, user)
+	return nil
+}
+
+type memVoteRepository struct {
+	repositories.VoteRepository
+	tx *memTx
+}
+
+func (r memVoteRepository) Create(ctx context.Context, vote *models.Vote) error {
+	r.tx.lock(voteRow(vote.VoteID))
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	put(r.tx, store.votes, vote.VoteID, *vote)
+	return nil
+}
+
+func (r memVoteRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Vote, error) {
+	r.tx.lock(voteRow(id))
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	vote, ok := store.votes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &vote, nil
+}
+
+func (r memVoteRepository) GetByUserAndCategory(ctx context.Context, userID, categoryID uuid.UUID) (*models.Vote, error) {
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, vote := range store.votes {
+		if vote.UserID == userID && vote.CategoryID == categoryID {
+			return &vote, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r memVoteRepository) Update(ctx context.Context, vote *models.Vote) error {
+	r.tx.lock(voteRow(vote.VoteID))
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	put(r.tx, store.votes, vote.VoteID, *vote)
+	return nil
+}
+
+// Delete moves the vote to the store's deleted votes, as a soft delete hides
+// it from every other query.
+func (r memVoteRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.tx.lock(voteRow(id))
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	vote := store.votes[id]
+	vote.DeletedAt = gorm.DeletedAt{Time: votingNow, Valid: true}
+	put(r.tx, store.deleted, id, vote)
+	remove(r.tx, store.votes, id)
+	return nil
+}
+
+func (r memVoteRepository) GetDeletedByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Vote, error) {
+	r.tx.lock(voteRow(id))
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	vote, ok := store.deleted[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &vote, nil
+}
+
+func (r memVoteRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.tx.lock(voteRow(id))
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	vote, ok := store.deleted[id]
+	if !ok {
+		return false, nil
+	}
+	vote.DeletedAt = gorm.DeletedAt{}
+	put(r.tx, store.votes, id, vote)
+	remove(r.tx, store.deleted, id)
+	return true, nil
+}
+
+// RecordChange keeps the change with the transaction until it commits.
+func (r memVoteRepository) RecordChange(ctx context.Context, change *models.VoteChange) error {
+	r.tx.changes = append(r.tx.changes, *change)
+	return nil
+}
+
+// memLedgerRepository chains entries the way the database repository does;
+// appends are serialised by the ledger lock.
+type memLedgerRepository struct {
+	tx *memTx
+}
+
+func (r memLedgerRepository) Append(ctx context.Context, entry *models.VoteLedgerEntry) error {
+	r.tx.lock(ledgerTable{})
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	entry.Sequence = int64(len(store.ledger)) + 1
+	entry.PrevHash = models.GenesisHash
+	if len(store.ledger) > 0 {
+		entry.PrevHash = store.ledger[len(store.ledger)-1].Hash
+	}
+	entry.CreatedAt = votingNow
+	entry.Hash = entry.ComputeHash()
+	length := len(store.ledger)
+	store.ledger = append(store.ledger, *entry)
+	// No one else appends while the ledger lock is held
+	r.tx.undo = append(r.tx.undo, func() { store.ledger = store.ledger[:length] })
+	return nil
+}
+
+func (r memLedgerRepository) ListAfter(ctx context.Context, sequence int64, limit int) ([]models.VoteLedgerEntry, error) {
+	store := r.tx.store
+	store.roundTrip()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if sequence >= int64(len(store.ledger)) {
+		return nil, nil
+	}
+	entries := store.ledger[sequence:]
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func setupConcurrentVoteTest(store *memStore, categories ...uuid.UUID) *votingMechanismService {
+	return setupPolicyVoteTest(store, models.VotePolicyFixed, categories...)
+}
+
+func setupPolicyVoteTest(store *memStore, policy string, categories ...uuid.UUID) *votingMechanismService {
+	edition := &models.Edition{EditionID: uuid.New(), VotePolicy: policy}
+	list := make([]*models.Category, len(categories))
+	for i, categoryID := range categories {
+		list[i] = &models.Category{CategoryID: categoryID, EditionID: edition.EditionID}
+	}
+	return setupStoreVoteTest(store, edition, list...)
+}
+
+func setupStoreVoteTest(store *memStore, edition *models.Edition, categories ...*models.Category) *votingMechanismService {
+	categoryRepo := new(MockCategoryRepository)
+	editionRepo := new(MockEditionRepository)
+	for _, category := range categories {
+		categoryRepo.On("GetByID", mock.Anything, category.CategoryID).Return(category, nil)
+	}
+	editionRepo.On("GetByID", mock.Anything, edition.EditionID).Return(edition, nil)
+	service := NewVotingMechanismService(new(MockVoteRepository), new(MockUserRepository), categoryRepo, editionRepo, nomineesInCategory(), store).(*votingMechanismService)
+	service.now = func() time.Time { return votingNow }
+	return service
+}
+
+// hammer runs fn from n goroutines released at the same moment.
+func hammer(n int, fn func(i int) error) []error {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = fn(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+// TestVotingMechanismService_ConcurrentCastVote runs against the in-memory
+// store, whose locks stand in for the repositories' row locks. The SQL
+// locking itself is covered by TestVotingMechanismService_ConcurrentCastVotePostgres.
+func TestVotingMechanismService_ConcurrentCastVote(t *testing.T) {
+	const attempts = 50
+
+	t.Run("same category", func(t *testing.T) {
+		user := models.User{UserID: uuid.New(), AvailableVotes: 5, EmailVerifiedAt: &votingNow}
+		categoryID := uuid.New()
+		store := newMemStore(user)
+		service := setupConcurrentVoteTest(store, categoryID)
+
+		errs := hammer(attempts, func(int) error {
+			_, err := service.CastVote(context.Background(), user.UserID, uuid.New(), categoryID)
+			return err
+		})
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, ErrAlreadyVotedInCategory)
+		}
+		assert.Equal(t, 1, succeeded)
+		assert.Len(t, store.votes, 1)
+		assert.Equal(t, 4, store.users[user.UserID].AvailableVotes)
+	})
+
+	t.Run("budget across categories", func(t *testing.T) {
+		user := models.User{UserID: uuid.New(), AvailableVotes: 5, EmailVerifiedAt: &votingNow}
+		categories := make([]uuid.UUID, attempts)
+		for i := range categories {
+			categories[i] = uuid.New()
+		}
+		store := newMemStore(user)
+		service := setupConcurrentVoteTest(store, categories...)
+
+		errs := hammer(attempts, func(i int) error {
+			_, err := service.CastVote(context.Background(), user.UserID, uuid.New(), categories[i])
+			return err
+		})
+
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.ErrorIs(t, err, ErrNoVotesAvailable)
+		}
+		assert.Equal(t, 5, succeeded)
+		assert.Len(t, store.votes, 5)
+		assert.Zero(t, store.users[user.UserID].AvailableVotes)
+	})
+}
+
+func TestVotingMechanismService_ConcurrentDeleteVote(t *testing.T) {
+	user := models.User{UserID: uuid.New(), AvailableVotes: 4, EmailVerifiedAt: &votingNow}
+	vote := models.Vote{VoteID: uuid.New(), UserID: user.UserID, CategoryID: uuid.New(), NomineeID: uuid.New()}
+	store := newMemStore(user)
+	store.votes[vote.VoteID] = vote
+	service := setupConcurrentVoteTest(store, vote.CategoryID)
+	service.voteRepo.(*MockVoteRepository).On("GetByID", mock.Anything, vote.VoteID).Return(&vote, nil)
+
+	errs := hammer(20, func(int) error {
+		return service.DeleteVote(context.Background(), vote.VoteID)
+	})
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Empty(t, store.votes)
+	assert.Equal(t, 5, store.users[user.UserID].AvailableVotes)
+}
+
+func TestVotingMechanismService_CastVoteRollsBack(t *testing.T) {
+	user := models.User{UserID: uuid.New(), AvailableVotes: 5, EmailVerifiedAt: &votingNow}
+	categoryID := uuid.New()
+	store := newMemStore(user)
+	service := setupConcurrentVoteTest(store, categoryID)
+	service.uow = failingCreateUnitOfWork{store}
+
+	_, err := service.CastVote(context.Background(), user.UserID, uuid.New(), categoryID)
+
+	assert.Error(t, err)
+	assert.Empty(t, store.votes)
+	assert.Equal(t, 5, store.users[user.UserID].AvailableVotes)
+}
+
+// failingCreateUnitOfWork wraps memStore so that inserting a vote fails after
+// the user's budget has already been decremented.
+type failingCreateUnitOfWork struct {
+	store *memStore
+}
+
+func (u failingCreateUnitOfWork) Do(ctx context.Context, fn func(tx repositories.Tx) error) error {
+	return u.store.Do(ctx, func(tx repositories.Tx) error {
+		return fn(failingCreateTx{tx.(*memTx)})
+	})
+}
+
+type failingCreateTx struct {
+	*memTx
+}
+
+func (tx failingCreateTx) Votes() repositories.VoteRepository {
+	return failingCreateVoteRepository{memVoteRepository{tx: tx.memTx}}
+}
+
+type failingCreateVoteRepository struct {
+	memVoteRepository
+}
+
+func (failingCreateVoteRepository) Create(ctx context.Context, vote *models.Vote) error {
+	return errors.New("insert failed")
+}
+
+func TestVotingMechanismService_VotePolicies(t *testing.T) {
+	tests := []struct {
+		policy        string
+		wantVotes     int
+		wantRemaining int
+	}{
+		{models.VotePolicyFixed, 1, 1},
+		{models.VotePolicyPerRole, 1, 1},
+		{models.VotePolicyPerCategory, 1, 2},
+		{models.VotePolicyUnlimited, 3, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			user := models.User{UserID: uuid.New(), AvailableVotes: 2, EmailVerifiedAt: &votingNow}
+			categoryID := uuid.New()
+			store := newMemStore(user)
+			service := setupPolicyVoteTest(store, tt.policy, categoryID)
+
+			for i := 0; i < 3; i++ {
+				service.CastVote(context.Background(), user.UserID, uuid.New(), categoryID)
+			}
+
+			assert.Len(t, store.votes, tt.wantVotes)
+			assert.Equal(t, tt.wantRemaining, store.users[user.UserID].AvailableVotes)
+		})
+	}
+}
+
+func TestVotingMechanismService_CastBallot(t *testing.T) {
+	userID := uuid.New()
+	categoryID := uuid.New()
+	first, second := uuid.New(), uuid.New()
+
+	t.Run("invalid ballots", func(t *testing.T) {
+		_, _, _, service := setupVoteTest()
+
+		for _, rankings := range [][]uuid.UUID{nil, {first, first}, {first, uuid.Nil}} {
+			_, err := service.CastBallot(context.Background(), userID, categoryID, rankings)
+			assert.ErrorIs(t, err, ErrInvalidBallot)
+		}
+	})
+
+	t.Run("voting method mismatch", func(t *testing.T) {
+		_, _, categoryRepo, service := setupVoteTest()
+		single := uuid.New()
+		categoryRepo.On("GetByID", mock.Anything, categoryID).Return(&models.Category{CategoryID: categoryID, VotingMethod: models.VotingMethodRanked}, nil)
+		categoryRepo.On("GetByID", mock.Anything, single).Return(&models.Category{CategoryID: single, VotingMethod: models.VotingMethodSingle}, nil)
+
+		_, err := service.CastVote(context.Background(), userID, first, categoryID)
+		assert.ErrorIs(t, err, ErrRankedBallotRequired)
+
+		_, err = service.CastBallot(context.Background(), userID, single, []uuid.UUID{first, second})
+		assert.ErrorIs(t, err, ErrNotRankedChoice)
+	})
+
+	t.Run("records preferences", func(t *testing.T) {
+		user := models.User{UserID: userID, AvailableVotes: 5, EmailVerifiedAt: &votingNow}
+		edition := &models.Edition{EditionID: uuid.New(), VotePolicy: models.VotePolicyFixed}
+		category := &models.Category{CategoryID: categoryID, EditionID: edition.EditionID, VotingMethod: models.VotingMethodRanked}
+		store := newMemStore(user)
+		service := setupStoreVoteTest(store, edition, category)
+
+		vote, err := service.CastBallot(context.Background(), userID, categoryID, []uuid.UUID{second, first})
+
+		assert.NoError(t, err)
+		assert.Equal(t, second, vote.NomineeID)
+		assert.Equal(t, []uuid.UUID{second, first}, vote.RankedNominees())
+		assert.Equal(t, models.VoterClassPublic, vote.VoterClass)
+		assert.Equal(t, 4, store.users[userID].AvailableVotes)
+	})
+}
+
+func TestVotingMechanismService_CastVoteTagsVoterClass(t *testing.T) {
+	juror := models.User{UserID: uuid.New(), Role: models.RoleJury, AvailableVotes: 5, EmailVerifiedAt: &votingNow}
+	categoryID := uuid.New()
+	store := newMemStore(juror)
+	service := setupConcurrentVoteTest(store, categoryID)
+
+	vote, err := service.CastVote(context.Background(), juror.UserID, uuid.New(), categoryID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.VoterClassJury, vote.VoterClass)
+}
+
+func TestVotingMechanismService_CastVoteRequiresVerifiedEmail(t *testing.T) {
+	user := models.User{UserID: uuid.New(), AvailableVotes: 5}
+	categoryID := uuid.New()
+	store := newMemStore(user)
+	service := setupConcurrentVoteTest(store, categoryID)
+
+	_, err := service.CastVote(context.Background(), user.UserID, uuid.New(), categoryID)
+
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+	assert.Empty(t, store.votes)
+	assert.Equal(t, 5, store.users[user.UserID].AvailableVotes)
+}
+
+func TestVotingMechanismService_NomineeNotInCategory(t *testing.T) {
+	userID := uuid.New()
+	categoryID := uuid.New()
+	outsider := uuid.New()
+	vote := &models.Vote{VoteID: uuid.New(), UserID: userID, CategoryID: categoryID, NomineeID: uuid.New()}
+
+	setup := func() (*MockVoteRepository, *votingMechanismService) {
+		voteRepo, _, categoryRepo, service := setupVoteTest()
+		linkRepo := new(MockNomineeCategoryRepository)
+		linkRepo.On("ContainsNominees", mock.Anything, categoryID, []uuid.UUID{outsider}).Return(false, nil)
+		service.linkRepo = linkRepo
+		categoryRepo.On("GetByID", mock.Anything, categoryID).Return(&models.Category{CategoryID: categoryID}, nil)
+		return voteRepo, service
+	}
+
+	t.Run("cast", func(t *testing.T) {
+		voteRepo, service := setup()
+
+		_, err := service.CastVote(context.Background(), userID, outsider, categoryID)
+
+		assert.ErrorIs(t, err, ErrNomineeNotInCategory)
+		voteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("change to another category's nominee", func(t *testing.T) {
+		voteRepo, service := setup()
+		voteRepo.On("GetByIDForUpdate", mock.Anything, vote.VoteID).Return(vote, nil)
+
+		_, err := service.ChangeVote(context.Background(), vote.VoteID, userID, outsider)
+
+		assert.ErrorIs(t, err, ErrNomineeNotInCategory)
+		voteRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestVotingMechanismService_LockedVoteCannotChange(t *testing.T) {
+	for _, status := range []string{models.VoteStatusQuarantined, models.VoteStatusVoid} {
+		t.Run(status, func(t *testing.T) {
+			user := models.User{UserID: uuid.New(), AvailableVotes: 4, EmailVerifiedAt: &votingNow}
+			vote := models.Vote{VoteID: uuid.New(), UserID: user.UserID, CategoryID: uuid.New(), NomineeID: uuid.New(), Status: status}
+			store := newMemStore(user)
+			store.votes[vote.VoteID] = vote
+			service := setupConcurrentVoteTest(store, vote.CategoryID)
+			service.voteRepo.(*MockVoteRepository).On("GetByID", mock.Anything, vote.VoteID).Return(&vote, nil)
+
+			_, err := service.ChangeVote(context.Background(), vote.VoteID, user.UserID, uuid.New())
+			assert.ErrorIs(t, err, ErrVoteLocked)
+
+			err = service.DeleteVote(context.Background(), vote.VoteID)
+			assert.ErrorIs(t, err, ErrVoteLocked)
+
+			assert.Equal(t, vote, store.votes[vote.VoteID])
+			assert.Equal(t, 4, store.users[user.UserID].AvailableVotes)
+		})
+	}
+}
+
+func TestVotingMechanismService_ChangeLimits(t *testing.T) {
+	user := models.User{UserID: uuid.New(), AvailableVotes: 5, EmailVerifiedAt: &votingNow}
+	edition := &models.Edition{EditionID: uuid.New(), VotePolicy: models.VotePolicyFixed}
+	category := &models.Category{
+		CategoryID:                uuid.New(),
+		EditionID:                 edition.EditionID,
+		MaxVoteChanges:            func(n int) *int { return &n }(2),
+		VoteChangeCooldownSeconds: 60,
+	}
+	store := newMemStore(user)
+	service := setupStoreVoteTest(store, edition, category)
+	ctx := context.Background()
+
+	vote, err := service.CastVote(ctx, user.UserID, uuid.New(), category.CategoryID)
+	require.NoError(t, err)
+
+	first := uuid.New()
+	_, err = service.ChangeVote(ctx, vote.VoteID, user.UserID, first)
+	require.NoError(t, err)
+
+	service.now = func() time.Time { return votingNow.Add(20 * time.Second) }
+	_, err = service.ChangeVote(ctx, vote.VoteID, user.UserID, uuid.New())
+	var cooldown *VoteChangeCooldownError
+	require.ErrorAs(t, err, &cooldown)
+	assert.ErrorIs(t, err, ErrVoteChangeCooldown)
+	assert.Equal(t, 40*time.Second, cooldown.RetryAfter)
+
+	service.now = func() time.Time { return votingNow.Add(time.Minute) }
+	second := uuid.New()
+	changed, err := service.ChangeVote(ctx, vote.VoteID, user.UserID, second)
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed.ChangeCount)
+
+	service.now = func() time.Time { return votingNow.Add(time.Hour) }
+	_, err = service.ChangeVote(ctx, vote.VoteID, user.UserID, uuid.New())
+	assert.ErrorIs(t, err, ErrVoteChangeLimit)
+
+	// Rejected changes leave no trace in the history
+	require.Len(t, store.changes, 2)
+	assert.Equal(t, vote.NomineeID, store.changes[0].PreviousNomineeID)
+	assert.Equal(t, first, store.changes[0].NewNomineeID)
+	assert.Equal(t, first, store.changes[1].PreviousNomineeID)
+	assert.Equal(t, second, store.changes[1].NewNomineeID)
+	assert.Equal(t, votingNow.Add(time.Minute), store.changes[1].ChangedAt)
+	assert.Equal(t, &user.UserID, store.changes[1].ChangedBy)
+	assert.Equal(t, second, store.votes[vote.VoteID].NomineeID)
+}
+
+func TestVotingMechanismService_UndeleteVote(t *testing.T) {
+	user := models.User{UserID: uuid.New(), AvailableVotes: 1, EmailVerifiedAt: &votingNow}
+	categoryID := uuid.New()
+	ctx := context.Background()
+
+	t.Run("restores the vote and spends the budget again", func(t *testing.T) {
+		store := newMemStore(user)
+		service := setupConcurrentVoteTest(store, categoryID)
+		vote, err := service.CastVote(ctx, user.UserID, uuid.New(), categoryID)
+		require.NoError(t, err)
+		service.voteRepo.(*MockVoteRepository).On("GetByID", mock.Anything, vote.VoteID).Return(vote, nil)
+		require.NoError(t, service.DeleteVote(ctx, vote.VoteID))
+		require.Equal(t, 1, store.users[user.UserID].AvailableVotes)
+
+		restored, err := service.UndeleteVote(ctx, vote.VoteID)
+
+		require.NoError(t, err)
+		assert.Equal(t, vote.NomineeID, restored.NomineeID)
+		assert.False(t, restored.DeletedAt.Valid)
+		assert.Contains(t, store.votes, vote.VoteID)
+		assert.Empty(t, store.deleted)
+		assert.Equal(t, 0, store.users[user.UserID].AvailableVotes)
+		require.Len(t, store.ledger, 3)
+		assert.Equal(t, models.LedgerActionRestore, store.ledger[2].Action)
+	})
+
+	t.Run("user voted again since", func(t *testing.T) {
+		store := newMemStore(user)
+		service := setupConcurrentVoteTest(store, categoryID)
+		deleted := models.Vote{VoteID: uuid.New(), UserID: user.UserID, CategoryID: categoryID, NomineeID: uuid.New(),
+			DeletedAt: gorm.DeletedAt{Time: votingNow, Valid: true}}
+		store.deleted[deleted.VoteID] = deleted
+		_, err := service.CastVote(ctx, user.UserID, uuid.New(), categoryID)
+		require.NoError(t, err)
+		store.users[user.UserID] = user
+
+		_, err = service.UndeleteVote(ctx, deleted.VoteID)
+
+		assert.ErrorIs(t, err, ErrAlreadyVotedInCategory)
+		assert.Contains(t, store.deleted, deleted.VoteID)
+		assert.Equal(t, user, store.users[user.UserID])
+	})
+
+	t.Run("email no longer verified", func(t *testing.T) {
+		store := newMemStore(user)
+		service := setupConcurrentVoteTest(store, categoryID)
+		deleted := models.Vote{VoteID: uuid.New(), UserID: user.UserID, CategoryID: categoryID, NomineeID: uuid.New(),
+			DeletedAt: gorm.DeletedAt{Time: votingNow, Valid: true}}
+		store.deleted[deleted.VoteID] = deleted
+		unverified := user
+		unverified.EmailVerifiedAt = nil
+		store.users[user.UserID] = unverified
+
+		_, err := service.UndeleteVote(ctx, deleted.VoteID)
+
+		assert.ErrorIs(t, err, ErrEmailNotVerified)
+		assert.Contains(t, store.deleted, deleted.VoteID)
+		assert.Equal(t, unverified, store.users[user.UserID])
+		assert.Empty(t, store.ledger)
+	})
+
+	t.Run("vote is not deleted", func(t *testing.T) {
+		service := setupConcurrentVoteTest(newMemStore(user), categoryID)
+
+		_, err := service.UndeleteVote(ctx, uuid.New())
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
@@ -21,6 +23,28 @@ var newDialer = func(host string, port int, username, password string) dialer {
 }
 
 func SendVerificationEmail(recipient, verificationURL string) {
+	sendEmail(recipient, "Verify Your Email Address",
+		fmt.Sprintf("Click the link to verify your email: %s", verificationURL), "Verification")
+}
+
+// SendPasswordResetEmail emails a link to choose a new password.
+func SendPasswordResetEmail(recipient, resetURL string) {
+	sendEmail(recipient, "Reset Your Password",
+		fmt.Sprintf("Click the link to reset your password: %s\n\nIf you did not ask to reset your password, you can ignore this email.", resetURL),
+		"Password reset")
+}
+
+// SendAccountLockedEmail tells the account owner that sign-in was locked
+// after repeated failed attempts.
+func SendAccountLockedEmail(recipient string, until time.Time) {
+	sendEmail(recipient, "Your Account Has Been Locked",
+		fmt.Sprintf("There were too many failed attempts to sign in to your account, so signing in is locked until %s.\n\nIf this was not you, someone may be trying to guess your password. We recommend resetting it once the lock ends.", until.UTC().Format("2 Jan 2006 15:04 MST")),
+		"Account locked")
+}
+
+// sendEmail sends a plain text email through the configured SMTP server,
+// logging the outcome. kind names the email in log messages.
+func sendEmail(recipient, subject, body, kind string) {
 	host := os.Getenv("SMTP_HOST")
 	portStr := os.Getenv("SMTP_PORT")
 	username := os.Getenv("SMTP_USERNAME")
@@ -34,13 +58,13 @@ func SendVerificationEmail(recipient, verificationURL string) {
 	m := gomail.NewMessage()
 	m.SetHeader("From", username)
 	m.SetHeader("To", recipient)
-	m.SetHeader("Subject", "Verify Your Email Address")
-	m.SetBody("text/plain", fmt.Sprintf("Click the link to verify your email: %s", verificationURL))
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", body)
 	d := newDialer(host, port, username, password)
 
 	if err := d.DialAndSend(m); err != nil {
-		log.Printf("Failed to send verification email to %s: %v", recipient, err)
+		log.Printf("Failed to send %s email to %s: %v", strings.ToLower(kind), recipient, err)
 	} else {
-		log.Printf("Verification email sent to %s", recipient)
+		log.Printf("%s email sent to %s", kind, recipient)
 	}
 }
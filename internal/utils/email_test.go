@@ -3,6 +3,7 @@ package utils
 import (
 	"os"
 	"testing"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
@@ -38,3 +39,35 @@ func TestSendVerificationEmail(t *testing.T) {
 		t.Error("Expected email to be sent")
 	}
 }
+
+func TestSendPasswordResetEmail(t *testing.T) {
+	originalNewDialer := newDialer
+	defer func() { newDialer = originalNewDialer }()
+
+	mock := &mockDialer{}
+	newDialer = func(host string, port int, username, password string) dialer {
+		return mock
+	}
+
+	SendPasswordResetEmail("test@example.com", "https://reset.com")
+
+	if !mock.called {
+		t.Error("Expected email to be sent")
+	}
+}
+
+func TestSendAccountLockedEmail(t *testing.T) {
+	originalNewDialer := newDialer
+	defer func() { newDialer = originalNewDialer }()
+
+	mock := &mockDialer{}
+	newDialer = func(host string, port int, username, password string) dialer {
+		return mock
+	}
+
+	SendAccountLockedEmail("test@example.com", time.Now().Add(15*time.Minute))
+
+	if !mock.called {
+		t.Error("Expected email to be sent")
+	}
+}
@@ -0,0 +1,4 @@
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_voting_window_check;
+ALTER TABLE categories
+  DROP COLUMN IF EXISTS voting_closes_at,
+  DROP COLUMN IF EXISTS voting_opens_at;
@@ -0,0 +1,8 @@
+-- Per-category voting windows. NULL bounds mean the window is open on that side.
+ALTER TABLE categories
+  ADD COLUMN voting_opens_at  TIMESTAMPTZ,
+  ADD COLUMN voting_closes_at TIMESTAMPTZ;
+
+ALTER TABLE categories
+  ADD CONSTRAINT categories_voting_window_check
+  CHECK (voting_opens_at IS NULL OR voting_closes_at IS NULL OR voting_closes_at > voting_opens_at);
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_votes_edition_id;
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_category_edition_fkey;
+ALTER TABLE votes DROP COLUMN IF EXISTS edition_id;
+
+DROP INDEX IF EXISTS idx_nominee_categories_edition_id;
+ALTER TABLE nominee_categories DROP CONSTRAINT IF EXISTS nominee_categories_category_edition_fkey;
+ALTER TABLE nominee_categories DROP COLUMN IF EXISTS edition_id;
+
+DROP INDEX IF EXISTS idx_categories_edition_id;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_category_id_edition_id_key;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_edition_id_name_key;
+-- Fails if the same name was reused across editions; resolve duplicates before rolling back
+ALTER TABLE categories ADD CONSTRAINT categories_name_key UNIQUE (name);
+ALTER TABLE categories DROP COLUMN IF EXISTS edition_id;
+
+DROP INDEX IF EXISTS idx_editions_single_active;
+DROP TABLE IF EXISTS editions;
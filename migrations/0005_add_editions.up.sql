@@ -0,0 +1,50 @@
+-- Editions (seasons) scope categories, nominee-category links and votes
+CREATE TABLE editions (
+  edition_id    UUID         PRIMARY KEY DEFAULT uuid_generate_v4(),
+  name          VARCHAR(255) NOT NULL,
+  year          INT          NOT NULL UNIQUE,
+  is_active     BOOLEAN      NOT NULL DEFAULT FALSE,
+  created_at    TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at    TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- At most one edition may be active at a time
+CREATE UNIQUE INDEX idx_editions_single_active ON editions (is_active) WHERE is_active;
+
+-- Everything that already exists belongs to the current year's edition
+INSERT INTO editions (name, year, is_active)
+VALUES (EXTRACT(YEAR FROM CURRENT_DATE)::INT || ' Awards', EXTRACT(YEAR FROM CURRENT_DATE)::INT, TRUE);
+
+-- Categories
+ALTER TABLE categories ADD COLUMN edition_id UUID REFERENCES editions(edition_id) ON DELETE RESTRICT;
+UPDATE categories SET edition_id = (SELECT edition_id FROM editions WHERE is_active);
+ALTER TABLE categories ALTER COLUMN edition_id SET NOT NULL;
+
+-- Category names only need to be unique within an edition
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_key;
+ALTER TABLE categories ADD CONSTRAINT categories_edition_id_name_key UNIQUE (edition_id, name);
+-- Target for the composite foreign keys below
+ALTER TABLE categories ADD CONSTRAINT categories_category_id_edition_id_key UNIQUE (category_id, edition_id);
+CREATE INDEX idx_categories_edition_id ON categories (edition_id);
+
+-- Nominee-category links carry the edition of their category
+ALTER TABLE nominee_categories ADD COLUMN edition_id UUID;
+UPDATE nominee_categories nc SET edition_id = c.edition_id
+FROM categories c WHERE c.category_id = nc.category_id;
+ALTER TABLE nominee_categories ALTER COLUMN edition_id SET NOT NULL;
+ALTER TABLE nominee_categories
+  ADD CONSTRAINT nominee_categories_category_edition_fkey
+  FOREIGN KEY (category_id, edition_id) REFERENCES categories(category_id, edition_id)
+  ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE INDEX idx_nominee_categories_edition_id ON nominee_categories (edition_id);
+
+-- Votes
+ALTER TABLE votes ADD COLUMN edition_id UUID;
+UPDATE votes v SET edition_id = c.edition_id
+FROM categories c WHERE c.category_id = v.category_id;
+ALTER TABLE votes ALTER COLUMN edition_id SET NOT NULL;
+ALTER TABLE votes
+  ADD CONSTRAINT votes_category_edition_fkey
+  FOREIGN KEY (category_id, edition_id) REFERENCES categories(category_id, edition_id)
+  ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE INDEX idx_votes_edition_id ON votes (edition_id);
@@ -0,0 +1,4 @@
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_results_state_check;
+ALTER TABLE categories
+  DROP COLUMN IF EXISTS results_publish_at,
+  DROP COLUMN IF EXISTS results_state;
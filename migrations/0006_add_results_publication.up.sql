@@ -0,0 +1,8 @@
+-- Per-category results publication state with an optional scheduled publish time
+ALTER TABLE categories
+  ADD COLUMN results_state      VARCHAR(20) NOT NULL DEFAULT 'hidden',
+  ADD COLUMN results_publish_at TIMESTAMPTZ;
+
+ALTER TABLE categories
+  ADD CONSTRAINT categories_results_state_check
+  CHECK (results_state IN ('hidden', 'preview', 'published'));
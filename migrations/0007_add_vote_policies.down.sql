@@ -0,0 +1,8 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_available_votes_check;
+ALTER TABLE editions
+  DROP CONSTRAINT IF EXISTS editions_vote_budget_check,
+  DROP CONSTRAINT IF EXISTS editions_vote_policy_check;
+ALTER TABLE editions
+  DROP COLUMN IF EXISTS role_vote_budgets,
+  DROP COLUMN IF EXISTS vote_budget,
+  DROP COLUMN IF EXISTS vote_policy;
@@ -0,0 +1,15 @@
+-- Per-edition vote allocation policy replacing the hard-coded budget of 5
+ALTER TABLE editions
+  ADD COLUMN vote_policy       VARCHAR(20) NOT NULL DEFAULT 'fixed',
+  ADD COLUMN vote_budget       INT         NOT NULL DEFAULT 5,
+  ADD COLUMN role_vote_budgets JSONB       NOT NULL DEFAULT '{}';
+
+ALTER TABLE editions
+  ADD CONSTRAINT editions_vote_policy_check
+  CHECK (vote_policy IN ('fixed', 'per_category', 'unlimited', 'per_role')),
+  ADD CONSTRAINT editions_vote_budget_check
+  CHECK (vote_budget >= 0);
+
+ALTER TABLE users
+  ADD CONSTRAINT users_available_votes_check
+  CHECK (available_votes >= 0);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ballot_rankings;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_voting_method_check;
+ALTER TABLE categories DROP COLUMN IF EXISTS voting_method;
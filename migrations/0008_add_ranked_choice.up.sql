@@ -0,0 +1,16 @@
+-- Categories decided by ranked (instant-runoff) ballots instead of single picks
+ALTER TABLE categories
+  ADD COLUMN voting_method VARCHAR(20) NOT NULL DEFAULT 'single';
+
+ALTER TABLE categories
+  ADD CONSTRAINT categories_voting_method_check
+  CHECK (voting_method IN ('single', 'ranked'));
+
+-- Preference order of a ranked ballot; the vote's nominee_id is position 1
+CREATE TABLE IF NOT EXISTS ballot_rankings (
+  vote_id    UUID NOT NULL REFERENCES votes(vote_id) ON DELETE CASCADE,
+  position   INT  NOT NULL CHECK (position > 0),
+  nominee_id UUID NOT NULL REFERENCES nominees(nominee_id) ON DELETE CASCADE,
+  PRIMARY KEY (vote_id, position),
+  UNIQUE (vote_id, nominee_id)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_jury_weight_check;
+ALTER TABLE categories DROP COLUMN IF EXISTS jury_weight;
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_voter_class_check;
+ALTER TABLE votes DROP COLUMN IF EXISTS voter_class;
@@ -0,0 +1,15 @@
+-- Votes are tagged with the voter's class at the time they were cast
+ALTER TABLE votes
+  ADD COLUMN voter_class VARCHAR(10) NOT NULL DEFAULT 'public';
+
+ALTER TABLE votes
+  ADD CONSTRAINT votes_voter_class_check
+  CHECK (voter_class IN ('public', 'jury'));
+
+-- Share of a category's result decided by the jury; 0 means public vote only
+ALTER TABLE categories
+  ADD COLUMN jury_weight DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE categories
+  ADD CONSTRAINT categories_jury_weight_check
+  CHECK (jury_weight >= 0 AND jury_weight <= 1);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS vote_flags;
+DROP INDEX IF EXISTS idx_votes_created_at;
+DROP INDEX IF EXISTS idx_votes_status;
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_status_check;
+ALTER TABLE votes
+  DROP COLUMN IF EXISTS reviewed_by,
+  DROP COLUMN IF EXISTS reviewed_at,
+  DROP COLUMN IF EXISTS status;
@@ -0,0 +1,23 @@
+-- Votes flagged by the fraud analyzer are quarantined until an admin voids
+-- or restores them. Only valid votes are counted in results.
+ALTER TABLE votes
+  ADD COLUMN status      VARCHAR(12) NOT NULL DEFAULT 'valid',
+  ADD COLUMN reviewed_at TIMESTAMPTZ,
+  ADD COLUMN reviewed_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+
+ALTER TABLE votes
+  ADD CONSTRAINT votes_status_check
+  CHECK (status IN ('valid', 'quarantined', 'void'));
+
+CREATE INDEX IF NOT EXISTS idx_votes_status ON votes(status);
+CREATE INDEX IF NOT EXISTS idx_votes_created_at ON votes(created_at);
+
+-- Why a vote was flagged; one row per vote and detection rule
+CREATE TABLE IF NOT EXISTS vote_flags (
+  flag_id    UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  vote_id    UUID NOT NULL REFERENCES votes(vote_id) ON DELETE CASCADE,
+  rule       VARCHAR(40) NOT NULL,
+  detail     TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (vote_id, rule)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS registration_audits;
+DROP TABLE IF EXISTS vote_audits;
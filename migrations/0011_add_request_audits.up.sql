@@ -0,0 +1,22 @@
+-- Client details captured when a vote is cast or an account registered,
+-- kept apart from the core tables so they can be purged after the
+-- retention period.
+CREATE TABLE IF NOT EXISTS vote_audits (
+  vote_id    UUID PRIMARY KEY REFERENCES votes(vote_id) ON DELETE CASCADE,
+  ip_address VARCHAR(45) NOT NULL DEFAULT '',
+  user_agent VARCHAR(512) NOT NULL DEFAULT '',
+  request_id VARCHAR(64) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS registration_audits (
+  user_id    UUID PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+  ip_address VARCHAR(45) NOT NULL DEFAULT '',
+  user_agent VARCHAR(512) NOT NULL DEFAULT '',
+  request_id VARCHAR(64) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_vote_audits_created_at ON vote_audits(created_at);
+CREATE INDEX IF NOT EXISTS idx_vote_audits_ip_address ON vote_audits(ip_address);
+CREATE INDEX IF NOT EXISTS idx_registration_audits_created_at ON registration_audits(created_at);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS vote_ledger_append_only ON vote_ledger;
+DROP FUNCTION IF EXISTS vote_ledger_append_only();
+DROP TABLE IF EXISTS vote_ledger;
@@ -0,0 +1,25 @@
+-- Hash-chained record of every vote create, change and delete. Each entry's
+-- hash covers the previous entry's hash, so editing or removing an entry
+-- breaks the chain from that point on.
+CREATE TABLE IF NOT EXISTS vote_ledger (
+  sequence    BIGINT PRIMARY KEY CHECK (sequence > 0),
+  action      VARCHAR(10) NOT NULL CHECK (action IN ('create', 'change', 'delete')),
+  vote_id     UUID NOT NULL,
+  payload     TEXT NOT NULL,
+  prev_hash   CHAR(64) NOT NULL,
+  hash        CHAR(64) NOT NULL UNIQUE,
+  created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_vote_ledger_vote_id ON vote_ledger(vote_id);
+
+-- Reject updates and deletes so the ledger can only be appended to
+CREATE OR REPLACE FUNCTION vote_ledger_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'vote_ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER vote_ledger_append_only
+  BEFORE UPDATE OR DELETE ON vote_ledger
+  FOR EACH ROW EXECUTE FUNCTION vote_ledger_append_only();
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS vote_changes;
+ALTER TABLE votes
+  DROP COLUMN IF EXISTS last_changed_at,
+  DROP COLUMN IF EXISTS change_count;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_vote_change_cooldown_check;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_max_vote_changes_check;
+ALTER TABLE categories
+  DROP COLUMN IF EXISTS vote_change_cooldown_seconds,
+  DROP COLUMN IF EXISTS max_vote_changes;
@@ -0,0 +1,27 @@
+-- Per-category limits on changing a vote. A NULL max_vote_changes allows
+-- unlimited changes; a zero cooldown allows changes back to back.
+ALTER TABLE categories
+  ADD COLUMN max_vote_changes INT,
+  ADD COLUMN vote_change_cooldown_seconds INT NOT NULL DEFAULT 0;
+
+ALTER TABLE categories
+  ADD CONSTRAINT categories_max_vote_changes_check CHECK (max_vote_changes >= 0),
+  ADD CONSTRAINT categories_vote_change_cooldown_check CHECK (vote_change_cooldown_seconds >= 0);
+
+ALTER TABLE votes
+  ADD COLUMN change_count INT NOT NULL DEFAULT 0,
+  ADD COLUMN last_changed_at TIMESTAMPTZ;
+
+-- One row per change, with the pick before and after it
+CREATE TABLE IF NOT EXISTS vote_changes (
+  change_id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  vote_id             UUID NOT NULL REFERENCES votes(vote_id) ON DELETE CASCADE,
+  changed_by          UUID REFERENCES users(user_id) ON DELETE SET NULL,
+  previous_nominee_id UUID NOT NULL,
+  new_nominee_id      UUID NOT NULL,
+  previous_rankings   JSONB NOT NULL DEFAULT '[]',
+  new_rankings        JSONB NOT NULL DEFAULT '[]',
+  changed_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_vote_changes_vote_id ON vote_changes(vote_id, changed_at);
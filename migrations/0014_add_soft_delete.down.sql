@@ -0,0 +1,48 @@
+-- Rolling back would have to drop soft-deleted rows for the full unique
+-- constraints to return, losing the data they keep. Refuse instead, so they
+-- are restored or purged on purpose first.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM votes WHERE deleted_at IS NOT NULL)
+    OR EXISTS (SELECT 1 FROM nominees WHERE deleted_at IS NOT NULL)
+    OR EXISTS (SELECT 1 FROM categories WHERE deleted_at IS NOT NULL)
+    OR EXISTS (SELECT 1 FROM users WHERE deleted_at IS NOT NULL) THEN
+    RAISE EXCEPTION 'soft-deleted users, categories, nominees or votes exist; restore or purge them before rolling back';
+  END IF;
+END;
+$$;
+
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_category_edition_fkey;
+ALTER TABLE votes
+  ADD CONSTRAINT votes_category_edition_fkey
+  FOREIGN KEY (category_id, edition_id) REFERENCES categories(category_id, edition_id)
+  ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_nominee_id_category_id_fkey;
+ALTER TABLE votes
+  ADD CONSTRAINT votes_nominee_id_category_id_fkey
+  FOREIGN KEY (nominee_id, category_id) REFERENCES nominee_categories(nominee_id, category_id)
+  ON DELETE CASCADE;
+
+-- Existing restore entries cannot be removed from the append-only ledger
+ALTER TABLE vote_ledger DROP CONSTRAINT IF EXISTS vote_ledger_action_check;
+ALTER TABLE vote_ledger
+  ADD CONSTRAINT vote_ledger_action_check
+  CHECK (action IN ('create', 'change', 'delete')) NOT VALID;
+
+DROP INDEX IF EXISTS idx_categories_edition_id_name;
+ALTER TABLE categories ADD CONSTRAINT categories_edition_id_name_key UNIQUE (edition_id, name);
+
+DROP INDEX IF EXISTS idx_users_email;
+DROP INDEX IF EXISTS idx_users_username;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+
+DROP INDEX IF EXISTS idx_votes_deleted_at;
+DROP INDEX IF EXISTS idx_nominees_deleted_at;
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+ALTER TABLE votes DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE nominees DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
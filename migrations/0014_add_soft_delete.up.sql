@@ -0,0 +1,41 @@
+-- Users, categories, nominees and votes are soft deleted so that admins can
+-- restore them and deleting a nominee or category no longer cascades into
+-- its votes.
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE categories ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE nominees ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE votes ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_nominees_deleted_at ON nominees(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_votes_deleted_at ON votes(deleted_at);
+
+-- Deleted rows no longer hold on to their unique names; restoring one fails
+-- if the name has been taken since
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email) WHERE deleted_at IS NULL;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_edition_id_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_edition_id_name ON categories(edition_id, name) WHERE deleted_at IS NULL;
+
+-- Restoring a deleted vote is recorded in the ledger
+ALTER TABLE vote_ledger DROP CONSTRAINT IF EXISTS vote_ledger_action_check;
+ALTER TABLE vote_ledger
+  ADD CONSTRAINT vote_ledger_action_check
+  CHECK (action IN ('create', 'change', 'delete', 'restore'));
+
+-- Votes are only soft deleted now, so deleting a nominee's category link or
+-- a category must not take the votes with it
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_nominee_id_category_id_fkey;
+ALTER TABLE votes
+  ADD CONSTRAINT votes_nominee_id_category_id_fkey
+  FOREIGN KEY (nominee_id, category_id) REFERENCES nominee_categories(nominee_id, category_id)
+  ON DELETE RESTRICT;
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_category_edition_fkey;
+ALTER TABLE votes
+  ADD CONSTRAINT votes_category_edition_fkey
+  FOREIGN KEY (category_id, edition_id) REFERENCES categories(category_id, edition_id)
+  ON UPDATE CASCADE ON DELETE RESTRICT;
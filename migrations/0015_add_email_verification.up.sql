@@ -0,0 +1,17 @@
+-- Accounts must verify their email address before they can vote. Accounts
+-- that already exist are treated as verified.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+UPDATE users SET email_verified_at = created_at;
+
+-- Single-use tokens emailed to users. Only a hash of each token is stored.
+CREATE TABLE IF NOT EXISTS user_tokens (
+  token_id    UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id     UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  purpose     VARCHAR(30) NOT NULL CHECK (purpose IN ('email_verification')),
+  token_hash  CHAR(64) NOT NULL UNIQUE,
+  expires_at  TIMESTAMPTZ NOT NULL,
+  used_at     TIMESTAMPTZ,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id ON user_tokens(user_id, purpose);
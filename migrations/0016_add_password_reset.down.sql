@@ -0,0 +1,6 @@
+DELETE FROM user_tokens WHERE purpose = 'password_reset';
+ALTER TABLE user_tokens DROP CONSTRAINT IF EXISTS user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+  CHECK (purpose IN ('email_verification'));
+
+ALTER TABLE users DROP COLUMN IF EXISTS token_version;
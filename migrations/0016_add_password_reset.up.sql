@@ -0,0 +1,7 @@
+-- Bumped whenever all of a user's sessions must end, such as after a password
+-- reset. Tokens carry the version they were issued at.
+ALTER TABLE users ADD COLUMN token_version INT NOT NULL DEFAULT 0;
+
+ALTER TABLE user_tokens DROP CONSTRAINT IF EXISTS user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+  CHECK (purpose IN ('email_verification', 'password_reset'));
@@ -0,0 +1,15 @@
+-- A session is one login. Its refresh token is rotated on every use; the
+-- previous token is kept so that replaying it can be detected.
+CREATE TABLE IF NOT EXISTS sessions (
+  session_id                  UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id                     UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  refresh_token_hash          CHAR(64) NOT NULL UNIQUE,
+  previous_refresh_token_hash CHAR(64),
+  expires_at                  TIMESTAMPTZ NOT NULL,
+  last_used_at                TIMESTAMPTZ,
+  revoked_at                  TIMESTAMPTZ,
+  created_at                  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_previous_refresh_token_hash ON sessions(previous_refresh_token_hash);
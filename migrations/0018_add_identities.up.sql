@@ -0,0 +1,24 @@
+-- Accounts at external OpenID Connect providers that users sign in with.
+CREATE TABLE IF NOT EXISTS identities (
+  identity_id   UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id       UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  provider      VARCHAR(50) NOT NULL,
+  subject       VARCHAR(255) NOT NULL,
+  email         VARCHAR(255),
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_login_at TIMESTAMPTZ,
+  UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_identities_user_id ON identities(user_id);
+
+-- Sign-ins in progress at a provider. Each is used once, when the provider
+-- redirects back with the state.
+CREATE TABLE IF NOT EXISTS oidc_login_requests (
+  state_hash    CHAR(64) PRIMARY KEY,
+  provider      VARCHAR(50) NOT NULL,
+  nonce         VARCHAR(64) NOT NULL,
+  code_verifier VARCHAR(128) NOT NULL,
+  expires_at    TIMESTAMPTZ NOT NULL,
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,24 @@
+-- TOTP two-factor authentication. The secret is pending until the user
+-- confirms it with a code, which sets totp_enabled_at. totp_last_step is the
+-- time step of the last accepted code, so no code is accepted twice.
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- Single-use codes for signing in without the authenticator, stored hashed.
+CREATE TABLE IF NOT EXISTS recovery_codes (
+  recovery_code_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id          UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  code_hash        CHAR(64) NOT NULL,
+  used_at          TIMESTAMPTZ,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE (user_id, code_hash)
+);
+
+-- Whether the session was started with a second factor
+ALTER TABLE sessions ADD COLUMN two_factor BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Issued after the password is checked, and exchanged with a code for a session
+ALTER TABLE user_tokens DROP CONSTRAINT IF EXISTS user_tokens_purpose_check;
+ALTER TABLE user_tokens ADD CONSTRAINT user_tokens_purpose_check
+  CHECK (purpose IN ('email_verification', 'password_reset', 'two_factor_login'));
@@ -0,0 +1,9 @@
+-- Failed logins per email address and per client IP. Sign-in from a key is
+-- blocked until locked_until. The count starts again once a day has passed
+-- since the last failure.
+CREATE TABLE IF NOT EXISTS login_throttles (
+  throttle_key    VARCHAR(320) PRIMARY KEY,
+  failures        INT NOT NULL DEFAULT 0,
+  last_failure_at TIMESTAMPTZ NOT NULL,
+  locked_until    TIMESTAMPTZ
+);
@@ -0,0 +1,23 @@
+-- Every request to a staff route: who made it, what it targeted and, for
+-- changes, the target before and after. Entries are not purged with the
+-- request audits, and outlive the actor's account.
+CREATE TABLE IF NOT EXISTS admin_actions (
+  action_id   UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  actor_id    UUID NOT NULL,
+  actor_role  VARCHAR(50) NOT NULL,
+  action      VARCHAR(255) NOT NULL,
+  target_type VARCHAR(50) NOT NULL DEFAULT '',
+  target_id   VARCHAR(64) NOT NULL DEFAULT '',
+  status_code INT NOT NULL,
+  before      JSONB,
+  after       JSONB,
+  diff        JSONB,
+  ip_address  VARCHAR(45) NOT NULL DEFAULT '',
+  user_agent  VARCHAR(512) NOT NULL DEFAULT '',
+  request_id  VARCHAR(64) NOT NULL DEFAULT '',
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_actions_created_at ON admin_actions(created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_actions_actor_id ON admin_actions(actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_actions_target ON admin_actions(target_type, target_id, created_at);
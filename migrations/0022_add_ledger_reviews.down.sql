@@ -0,0 +1,5 @@
+-- Existing review entries cannot be removed from the append-only ledger
+ALTER TABLE vote_ledger DROP CONSTRAINT IF EXISTS vote_ledger_action_check;
+ALTER TABLE vote_ledger
+  ADD CONSTRAINT vote_ledger_action_check
+  CHECK (action IN ('create', 'change', 'delete', 'restore')) NOT VALID;
@@ -0,0 +1,6 @@
+-- Fraud reviews decide whether a vote counts, so voiding and reinstating a
+-- vote are recorded in the ledger too
+ALTER TABLE vote_ledger DROP CONSTRAINT IF EXISTS vote_ledger_action_check;
+ALTER TABLE vote_ledger
+  ADD CONSTRAINT vote_ledger_action_check
+  CHECK (action IN ('create', 'change', 'delete', 'restore', 'void', 'reinstate'));
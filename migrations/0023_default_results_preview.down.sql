@@ -0,0 +1 @@
+ALTER TABLE categories ALTER COLUMN results_state SET DEFAULT 'hidden';
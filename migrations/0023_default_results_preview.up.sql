@@ -0,0 +1,4 @@
+-- New categories start in admin preview, so staff can follow their tallies
+-- before publication; hiding results from staff too is an explicit choice.
+-- Existing categories keep their state.
+ALTER TABLE categories ALTER COLUMN results_state SET DEFAULT 'preview';